@@ -0,0 +1,315 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"time"
+
+	crutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/cr"
+	crdutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/crd"
+	namespaceutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/namespace"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var crGVRs = []schema.GroupVersionResource{crutils.GetAlertSchema(), crutils.GetBlackDuckSchema(), crutils.GetOpssightSchema()}
+
+// crdResources maps the name of each synopsys.com CRD to the resource of its custom resources
+var crdResources = map[string]schema.GroupVersionResource{
+	crdutils.AlertCRDName:     crutils.GetAlertSchema(),
+	crdutils.BlackDuckCRDName: crutils.GetBlackDuckSchema(),
+	crdutils.OpsSightCRDName:  crutils.GetOpssightSchema(),
+}
+
+// options select what is collected
+type options struct {
+	// namespacePattern matches the names of namespaces created by synopsysctl during the suites, which
+	// carry no ownership labels. Labeled namespaces are only collected once their TTL has expired
+	namespacePattern *regexp.Regexp
+	// ignoreTTL collects labeled namespaces that have not expired yet, e.g. after every suite was aborted
+	ignoreTTL bool
+	// keepCluster leaves the ClusterRoles, ClusterRoleBindings and CRDs in place
+	keepCluster bool
+	// removeFinalizers removes the finalizers of custom resources that are not deleted in time
+	removeFinalizers bool
+	now              time.Time
+}
+
+// cr is a custom resource to collect
+type cr struct {
+	gvr schema.GroupVersionResource
+	// namespace is the collected namespace the custom resource belongs to
+	namespace string
+	// scope is the namespace of the custom resource, empty if it is cluster scoped
+	scope string
+	name  string
+}
+
+// garbage is everything that is collected, in the order it is deleted
+type garbage struct {
+	crs                 []cr
+	namespaces          []string
+	clusterRoles        []string
+	clusterRoleBindings []string
+	crds                []string
+}
+
+func (g *garbage) empty() bool {
+	return len(g.crs)+len(g.namespaces)+len(g.clusterRoles)+len(g.clusterRoleBindings)+len(g.crds) == 0
+}
+
+// print writes what is collected to w
+func (g *garbage) print(w io.Writer) {
+	for _, c := range g.crs {
+		fmt.Fprintf(w, "%s %s/%s\n", c.gvr.GroupResource(), c.namespace, c.name)
+	}
+	for _, ns := range g.namespaces {
+		fmt.Fprintf(w, "namespace %s\n", ns)
+	}
+	for _, name := range g.clusterRoles {
+		fmt.Fprintf(w, "clusterrole %s\n", name)
+	}
+	for _, name := range g.clusterRoleBindings {
+		fmt.Fprintf(w, "clusterrolebinding %s\n", name)
+	}
+	for _, name := range g.crds {
+		fmt.Fprintf(w, "customresourcedefinition %s\n", name)
+	}
+}
+
+// collector finds and deletes the resources left behind by the suites
+type collector struct {
+	kc      kubernetes.Interface
+	dc      dynamic.Interface
+	aec     apiextensionsclient.Interface
+	restcli rest.Interface
+	out     io.Writer
+}
+
+// find returns the resources left behind by the suites
+func (c *collector) find(opts options) (*garbage, error) {
+	g := &garbage{}
+	nsList, err := c.kc.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the namespaces: %v", err)
+	}
+	for i := range nsList.Items {
+		ns := &nsList.Items[i]
+		matched := opts.namespacePattern != nil && opts.namespacePattern.MatchString(ns.Name) && !namespaceutils.IsManaged(ns)
+		if matched || c.owned(ns, opts) {
+			g.namespaces = append(g.namespaces, ns.Name)
+		}
+	}
+	sort.Strings(g.namespaces)
+	for _, ns := range g.namespaces {
+		for _, gvr := range crGVRs {
+			items, err := crutils.ListInNamespace(c.dc, gvr, ns)
+			if apierrs.IsNotFound(err) {
+				// the crd is not installed
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list %s in namespace %s: %v", gvr.Resource, ns, err)
+			}
+			for _, item := range items {
+				g.crs = append(g.crs, cr{gvr: gvr, namespace: ns, scope: item.GetNamespace(), name: item.GetName()})
+			}
+		}
+	}
+	if opts.keepCluster {
+		return g, nil
+	}
+
+	roles, err := c.kc.RbacV1().ClusterRoles().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the cluster roles: %v", err)
+	}
+	for i := range roles.Items {
+		if c.owned(&roles.Items[i], opts) {
+			g.clusterRoles = append(g.clusterRoles, roles.Items[i].Name)
+		}
+	}
+	bindings, err := c.kc.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the cluster role bindings: %v", err)
+	}
+	for i := range bindings.Items {
+		if c.owned(&bindings.Items[i], opts) {
+			g.clusterRoleBindings = append(g.clusterRoleBindings, bindings.Items[i].Name)
+		}
+	}
+	for _, name := range sortedCRDNames() {
+		crd, err := c.aec.ApiextensionsV1beta1().CustomResourceDefinitions().Get(name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get crd %s: %v", name, err)
+		}
+		if !c.owned(crd, opts) {
+			continue
+		}
+		inUse, err := c.crdInUse(name, g.namespaces)
+		if err != nil {
+			return nil, err
+		}
+		if inUse != "" {
+			fmt.Fprintf(c.out, "keeping crd %s: %s\n", name, inUse)
+			continue
+		}
+		g.crds = append(g.crds, name)
+	}
+	return g, nil
+}
+
+// owned returns true if obj is labeled as created by the suites and its TTL has expired or is ignored
+func (c *collector) owned(obj metav1.Object, opts options) bool {
+	if !namespaceutils.IsManaged(obj) {
+		return false
+	}
+	expired, err := namespaceutils.Expired(obj, opts.now)
+	if err != nil {
+		fmt.Fprintf(c.out, "%v\n", err)
+	}
+	return opts.ignoreTTL || expired
+}
+
+// crdInUse describes a custom resource of the crd name outside of namespaces, or returns "" if there
+// is none. Deleting the crd would delete those too, and they may belong to another install or to a
+// suite that is still running
+func (c *collector) crdInUse(name string, namespaces []string) (string, error) {
+	gvr := crdResources[name]
+	list, err := c.dc.Resource(gvr).List(metav1.ListOptions{})
+	if apierrs.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to list %s: %v", gvr.Resource, err)
+	}
+	collected := map[string]bool{}
+	for _, ns := range namespaces {
+		collected[ns] = true
+	}
+	for _, item := range list.Items {
+		if !collected[crutils.Namespace(&item)] {
+			return fmt.Sprintf("%s %s/%s is not collected", gvr.Resource, crutils.Namespace(&item), item.GetName()), nil
+		}
+	}
+	return "", nil
+}
+
+func sortedCRDNames() []string {
+	names := []string{}
+	for name := range crdResources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// delete removes g in dependency order, CRs, then namespaces, then cluster scoped RBAC and CRDs, and
+// waits for each step to complete
+func (c *collector) delete(g *garbage, opts options) error {
+	for _, r := range g.crs {
+		fmt.Fprintf(c.out, "deleting %s %s/%s\n", r.gvr.GroupResource(), r.namespace, r.name)
+		if err := ignoreNotFound(crutils.ResourceFor(c.dc, r.gvr, r.scope).Delete(r.name, &metav1.DeleteOptions{})); err != nil {
+			return fmt.Errorf("failed to delete %s %s/%s: %v", r.gvr.Resource, r.namespace, r.name, err)
+		}
+	}
+	for _, r := range g.crs {
+		err := crutils.WaitForCR(c.restcli, r.gvr, r.scope, r.name, false, 0, 0)
+		if err == nil {
+			continue
+		}
+		if !opts.removeFinalizers {
+			return err
+		}
+		fmt.Fprintf(c.out, "%v, removing its finalizers\n", err)
+		if _, err := namespaceutils.RemoveCRFinalizers(c.dc, r.namespace, r.gvr); err != nil {
+			return err
+		}
+		if err := crutils.WaitForCR(c.restcli, r.gvr, r.scope, r.name, false, 0, 0); err != nil {
+			return err
+		}
+	}
+
+	for _, ns := range g.namespaces {
+		fmt.Fprintf(c.out, "deleting namespace %s\n", ns)
+		if err := ignoreNotFound(c.kc.CoreV1().Namespaces().Delete(ns, &metav1.DeleteOptions{})); err != nil {
+			return fmt.Errorf("failed to delete namespace %s: %v", ns, err)
+		}
+	}
+	if len(g.namespaces) > 0 {
+		if err := namespaceutils.WaitForNamespacesDeletedWithReport(c.kc, c.dc, g.namespaces, 0); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range g.clusterRoleBindings {
+		fmt.Fprintf(c.out, "deleting clusterrolebinding %s\n", name)
+		if err := ignoreNotFound(c.kc.RbacV1().ClusterRoleBindings().Delete(name, &metav1.DeleteOptions{})); err != nil {
+			return fmt.Errorf("failed to delete cluster role binding %s: %v", name, err)
+		}
+	}
+	for _, name := range g.clusterRoles {
+		fmt.Fprintf(c.out, "deleting clusterrole %s\n", name)
+		if err := ignoreNotFound(c.kc.RbacV1().ClusterRoles().Delete(name, &metav1.DeleteOptions{})); err != nil {
+			return fmt.Errorf("failed to delete cluster role %s: %v", name, err)
+		}
+	}
+	for _, name := range g.crds {
+		// a suite may have created custom resources since find
+		inUse, err := c.crdInUse(name, nil)
+		if err != nil {
+			return err
+		}
+		if inUse != "" {
+			return fmt.Errorf("refusing to delete crd %s: %s", name, inUse)
+		}
+		fmt.Fprintf(c.out, "deleting customresourcedefinition %s\n", name)
+		if err := ignoreNotFound(c.aec.ApiextensionsV1beta1().CustomResourceDefinitions().Delete(name, &metav1.DeleteOptions{})); err != nil {
+			return fmt.Errorf("failed to delete crd %s: %v", name, err)
+		}
+	}
+	for _, name := range g.crds {
+		if err := crdutils.BlockUntilCrdIsDeleted(c.aec, name, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ignoreNotFound(err error) error {
+	if apierrs.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
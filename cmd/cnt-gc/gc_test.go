@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	namespaceutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/namespace"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testMeta(name string, labels map[string]string, expires time.Time) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{Name: name, Labels: labels}
+	if !expires.IsZero() {
+		meta.Annotations = map[string]string{namespaceutils.ExpiresAnnotation: expires.Format(time.RFC3339)}
+	}
+	return meta
+}
+
+func testCR(kind, namespace, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "synopsys.com/v1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+	}}
+}
+
+const operatorAdmin = "synopsys-operator-admin"
+
+func testCollector(crs ...runtime.Object) (*collector, *bytes.Buffer) {
+	now := time.Now()
+	managed := map[string]string{namespaceutils.ManagedByLabel: namespaceutils.ManagedBy}
+	kc := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: testMeta("default", nil, time.Time{})},
+		&corev1.Namespace{ObjectMeta: testMeta("synopsys-operator", nil, time.Time{})},
+		&corev1.Namespace{ObjectMeta: testMeta("bd-one", nil, time.Time{})},
+		&corev1.Namespace{ObjectMeta: testMeta("cnt-so-x7k2p", managed, now.Add(-time.Hour))},
+		&corev1.Namespace{ObjectMeta: testMeta("cnt-so-running", managed, now.Add(time.Hour))},
+		&rbacv1.ClusterRole{ObjectMeta: testMeta(operatorAdmin, managed, now.Add(-time.Hour))},
+		&rbacv1.ClusterRole{ObjectMeta: testMeta("prod-synopsys-operator", map[string]string{"app": "synopsys-operator"}, time.Time{})},
+		&rbacv1.ClusterRole{ObjectMeta: testMeta("cluster-admin", nil, time.Time{})},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: testMeta(operatorAdmin, managed, now.Add(time.Hour))},
+	)
+	aec := apiextensionsfake.NewSimpleClientset(
+		&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: testMeta("blackducks.synopsys.com", managed, now.Add(-time.Hour))},
+		&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: testMeta("alerts.synopsys.com", managed, now.Add(-time.Hour))},
+		&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: testMeta("opssights.synopsys.com", nil, time.Time{})},
+	)
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), append([]runtime.Object{
+		testCR("Blackduck", "bd-one", "bd-one"),
+		testCR("Alert", "prod", "alert"),
+	}, crs...)...)
+	out := &bytes.Buffer{}
+	return &collector{kc: kc, dc: dc, aec: aec, out: out}, out
+}
+
+func TestFind(t *testing.T) {
+	c, out := testCollector()
+	g, err := c.find(options{namespacePattern: regexp.MustCompile(defaultNamespacePattern("cnt")), now: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.namespaces, []string{"bd-one", "cnt-so-x7k2p"}) {
+		t.Errorf("unexpected namespaces %v", g.namespaces)
+	}
+	if len(g.crs) != 1 || g.crs[0].name != "bd-one" {
+		t.Errorf("unexpected crs %v", g.crs)
+	}
+	// the binding has not expired and the other roles are not labeled by the suites
+	if !reflect.DeepEqual(g.clusterRoles, []string{operatorAdmin}) || len(g.clusterRoleBindings) != 0 {
+		t.Errorf("unexpected rbac %v %v", g.clusterRoles, g.clusterRoleBindings)
+	}
+	// the alert of namespace prod keeps its crd, and the opssight crd is not labeled by the suites
+	if !reflect.DeepEqual(g.crds, []string{"blackducks.synopsys.com"}) {
+		t.Errorf("unexpected crds %v", g.crds)
+	}
+	if !strings.Contains(out.String(), "keeping crd alerts.synopsys.com: alerts prod/alert is not collected") {
+		t.Errorf("expected the alert crd to be kept, got\n%s", out)
+	}
+
+	out.Reset()
+	g.print(out)
+	for _, s := range []string{"blackducks.synopsys.com bd-one/bd-one", "namespace cnt-so-x7k2p", "clusterrole synopsys-operator-admin", "customresourcedefinition blackducks.synopsys.com"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected %q in\n%s", s, out)
+		}
+	}
+}
+
+func TestFindUniqueInstanceNamespaces(t *testing.T) {
+	c, _ := testCollector()
+	for _, name := range []string{"cnt-alt-x7k2p", "cnt-bd-4b9sz", "cnt-ops-q2w5r", "other-alt-x7k2p", "cnt-alt"} {
+		if _, err := c.kc.CoreV1().Namespaces().Create(&corev1.Namespace{ObjectMeta: testMeta(name, nil, time.Time{})}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g, err := c.find(options{namespacePattern: regexp.MustCompile(defaultNamespacePattern("cnt")), keepCluster: true, now: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// cnt-so-running matches the pattern too, but it is labeled and has not expired
+	if !reflect.DeepEqual(g.namespaces, []string{"bd-one", "cnt-alt-x7k2p", "cnt-bd-4b9sz", "cnt-ops-q2w5r", "cnt-so-x7k2p"}) {
+		t.Errorf("unexpected namespaces %v", g.namespaces)
+	}
+}
+
+func TestDefaultNamespacePatternWithoutPrefix(t *testing.T) {
+	pattern := regexp.MustCompile(defaultNamespacePattern(""))
+	for name, want := range map[string]bool{"alt-x7k2p": true, "bd-one": true, "cnt-alt-x7k2p": false, "synopsys-operator": false} {
+		if got := pattern.MatchString(name); got != want {
+			t.Errorf("%s: expected match %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestFindClusterScopedCRs(t *testing.T) {
+	alert := testCR("Alert", "", "alert")
+	alert.Object["spec"] = map[string]interface{}{"namespace": "cnt-so-x7k2p"}
+	c, _ := testCollector(alert)
+	g, err := c.find(options{now: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.crs) != 1 || g.crs[0] != (cr{gvr: crdResources["alerts.synopsys.com"], namespace: "cnt-so-x7k2p", name: "alert"}) {
+		t.Errorf("unexpected crs %v", g.crs)
+	}
+}
+
+func TestFindKeepsCRDsOfRunningSuites(t *testing.T) {
+	c, _ := testCollector(testCR("Blackduck", "cnt-so-running", "bd-two"))
+	g, err := c.find(options{namespacePattern: regexp.MustCompile(defaultNamespacePattern("cnt")), now: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.crds) != 0 {
+		t.Errorf("expected the crds to be kept, got %v", g.crds)
+	}
+}
+
+func TestFindIgnoreTTLAndKeepCluster(t *testing.T) {
+	c, _ := testCollector()
+	g, err := c.find(options{ignoreTTL: true, keepCluster: true, now: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.namespaces, []string{"cnt-so-running", "cnt-so-x7k2p"}) {
+		t.Errorf("unexpected namespaces %v", g.namespaces)
+	}
+	if len(g.clusterRoles)+len(g.clusterRoleBindings)+len(g.crds) != 0 {
+		t.Errorf("expected the cluster resources to be kept, got %v %v %v", g.clusterRoles, g.clusterRoleBindings, g.crds)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	c, out := testCollector()
+	g := &garbage{namespaces: []string{"cnt-so-x7k2p"}, clusterRoles: []string{operatorAdmin}, clusterRoleBindings: []string{operatorAdmin}, crds: []string{"opssights.synopsys.com"}}
+	if err := c.delete(g, options{}); err != nil {
+		t.Fatal(err)
+	}
+	remaining, err := c.find(options{ignoreTTL: true, now: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(remaining.namespaces, []string{"cnt-so-running"}) || len(remaining.clusterRoles)+len(remaining.clusterRoleBindings) != 0 {
+		t.Errorf("unexpected remaining garbage %+v", remaining)
+	}
+	if !strings.Contains(out.String(), "deleting namespace cnt-so-x7k2p") {
+		t.Errorf("unexpected output\n%s", out)
+	}
+}
+
+func TestDeleteRefusesCRDsInUse(t *testing.T) {
+	c, _ := testCollector()
+	err := c.delete(&garbage{crds: []string{"alerts.synopsys.com"}}, options{})
+	if err == nil || !strings.Contains(err.Error(), "refusing to delete crd alerts.synopsys.com") {
+		t.Fatalf("expected the crd to be kept, got %v", err)
+	}
+	if _, err := c.aec.ApiextensionsV1beta1().CustomResourceDefinitions().Get("alerts.synopsys.com", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the crd to exist: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// cnt-gc deletes what aborted test runs leave behind and what would make the next run fail: the
+// custom resources and namespaces of the suites, the synopsys-operator-admin ClusterRole and
+// ClusterRoleBinding and the synopsys.com CRDs.
+//
+// Namespaces are collected if they are labeled as created by the suites and their TTL has expired, or
+// if they are not labeled and their name matches -namespace-pattern. ClusterRoles, ClusterRoleBindings
+// and CRDs are only collected if the framework labeled them as created by the suites and their TTL has
+// expired, and a CRD is kept while a custom resource of its kind is outside the collected namespaces.
+// By default it only prints what it would delete; pass -dry-run=false to delete it. Custom resources
+// whose finalizers keep them from being deleted are stripped with -cnt.remove-cr-finalizers=true, and
+// the -cnt.* timeouts bound the waits:
+//
+//	cnt-gc -cnt.kube-context=kind-kind -dry-run=false
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
+)
+
+// defaultNamespacePattern matches the namespaces synopsysctl creates for the instances of the suites:
+// the baseline names such as alt-one and bd-one, and the names of Framework.UniqueName with prefix,
+// e.g. cnt-alt-x7k2p. The operator namespace is not matched since synopsys-operator is also the
+// namespace of real installs; the framework labels it when a deploy creates it
+func defaultNamespacePattern(prefix string) string {
+	if prefix != "" {
+		prefix = regexp.QuoteMeta(prefix) + "-"
+	}
+	return fmt.Sprintf(`^(alt|bd|so|ops)-(one|two|native)$|^%s(alt|bd|ops|so)-[a-z0-9]+$`, prefix)
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", true, "only print what would be deleted")
+	pattern := flag.String("namespace-pattern", "", "regular expression of namespace names to collect even without labels ; empty disables it (default: the instance names of the suites with the configured namespace prefix)")
+	ignoreTTL := flag.Bool("ignore-ttl", false, "collect labeled namespaces that have not expired yet")
+	keepCluster := flag.Bool("keep-cluster-resources", false, "leave the ClusterRoles, ClusterRoleBindings and CRDs in place")
+	config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	if err := config.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	patternSet := false
+	flag.Visit(func(f *flag.Flag) { patternSet = patternSet || f.Name == "namespace-pattern" })
+	if !patternSet {
+		*pattern = defaultNamespacePattern(config.Get().Namespaces.Prefix)
+	}
+
+	level, err := logging.ParseLevel(config.Get().LogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	logging.SetDefault(logging.NewWriterLogger(os.Stderr, level))
+
+	if err := run(*dryRun, *pattern, options{
+		ignoreTTL:        *ignoreTTL,
+		keepCluster:      *keepCluster,
+		removeFinalizers: config.Get().Namespaces.RemoveCRFinalizers,
+		now:              time.Now(),
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dryRun bool, pattern string, opts options) error {
+	if pattern != "" {
+		var err error
+		if opts.namespacePattern, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid namespace pattern: %v", err)
+		}
+	}
+	clients, err := k8sutils.DefaultClientFactory.Clients(k8sutils.ClientOptionsFor(config.Get().Cluster))
+	if err != nil {
+		return err
+	}
+	kc := clients.KubeClient
+	c := &collector{kc: kc, dc: clients.DynamicClient, aec: clients.APIExtensionClient, restcli: kc.RESTClient(), out: os.Stdout}
+
+	g, err := c.find(opts)
+	if err != nil {
+		return err
+	}
+	if g.empty() {
+		fmt.Println("nothing to delete")
+		return nil
+	}
+	if dryRun {
+		fmt.Println("would delete (pass -dry-run=false to delete):")
+		g.print(os.Stdout)
+		return nil
+	}
+	if err := c.delete(g, opts); err != nil {
+		return err
+	}
+	fmt.Println("done")
+	return nil
+}
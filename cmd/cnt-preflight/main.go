@@ -0,0 +1,98 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// cnt-preflight checks that a cluster can run the suites: that the API server is reachable and new
+// enough, that the current user may create CRDs, cluster RBAC and namespaces, that there is a default
+// StorageClass and enough node capacity, that no Synopsys Operator is installed yet and that the
+// synopsysctl binary runs. It prints a report and exits with 1 if a check failed:
+//
+//	cnt-preflight -cnt.kube-context=kind-kind -cnt.synopsysctl=/usr/local/bin/synopsysctl
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
+	"github.com/blackducksoftware/cloud-native-tests/utils/preflight"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func main() {
+	defaults := preflight.DefaultOptions("")
+	minServerVersion := flag.String("min-server-version", defaults.MinServerVersion, "oldest supported Kubernetes version")
+	minCPU := flag.String("min-cpu", defaults.MinCPU.String(), "allocatable CPU the schedulable nodes need in total")
+	minMemory := flag.String("min-memory", defaults.MinMemory.String(), "allocatable memory the schedulable nodes need in total")
+	config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	if err := config.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	level, err := logging.ParseLevel(config.Get().LogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	logging.SetDefault(logging.NewWriterLogger(os.Stderr, level))
+
+	report, err := run(*minServerVersion, *minCPU, *minMemory)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(report)
+	if report.Failed() {
+		os.Exit(1)
+	}
+}
+
+func run(minServerVersion, minCPU, minMemory string) (*preflight.Report, error) {
+	options := preflight.Options{MinServerVersion: minServerVersion, OperatorImage: config.Get().OperatorImage}
+	var err error
+	if options.MinCPU, err = resource.ParseQuantity(minCPU); err != nil {
+		return nil, fmt.Errorf("invalid min-cpu %q: %v", minCPU, err)
+	}
+	if options.MinMemory, err = resource.ParseQuantity(minMemory); err != nil {
+		return nil, fmt.Errorf("invalid min-memory %q: %v", minMemory, err)
+	}
+	rc, err := k8sutils.GetRestConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the kube config: %v", err)
+	}
+	c, cleanup, err := preflight.NewChecker(rc, options)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			logging.Warnf("%v", err)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Command.Duration)
+	defer cancel()
+	return c.Run(ctx), nil
+}
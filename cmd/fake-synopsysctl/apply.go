@@ -0,0 +1,315 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	crutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/cr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// operatorAdmin is the name of the ClusterRole and ClusterRoleBinding of a cluster scoped operator
+const operatorAdmin = "synopsys-operator-admin"
+
+// applier makes the cluster look as if the real synopsysctl command had run
+type applier interface {
+	deploy(namespace string, clusterScoped bool, resources []string, image string) error
+	create(resource, namespace, name string) error
+	remove(resource, namespace, name string) error
+	// destroy returns false if the operator was left in place because it still manages instances
+	destroy(namespace string, force bool) (bool, error)
+}
+
+// noopApplier only prints; it is used when FAKE_SYNOPSYSCTL_APPLY is not set
+type noopApplier struct{}
+
+func (noopApplier) deploy(string, bool, []string, string) error { return nil }
+func (noopApplier) create(string, string, string) error         { return nil }
+func (noopApplier) remove(string, string, string) error         { return nil }
+func (noopApplier) destroy(string, bool) (bool, error)          { return true, nil }
+
+// clusterApplier creates the objects synopsysctl would create in the cluster of KUBECONFIG
+type clusterApplier struct {
+	kc  kubernetes.Interface
+	dc  dynamic.Interface
+	aec apiextensionsclient.Interface
+}
+
+func newClusterApplier() (*clusterApplier, error) {
+	rc, err := k8sutils.GetKubeConfig(os.Getenv("KUBECONFIG"), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the kube config: %v", err)
+	}
+	kc, err := k8sutils.GetKubeClient(rc)
+	if err != nil {
+		return nil, err
+	}
+	dc, err := k8sutils.GetDynamicClient(rc)
+	if err != nil {
+		return nil, err
+	}
+	aec, err := apiextensionsclient.NewForConfig(rc)
+	if err != nil {
+		return nil, err
+	}
+	return &clusterApplier{kc: kc, dc: dc, aec: aec}, nil
+}
+
+func schemaFor(resource string) (schema.GroupVersionResource, error) {
+	switch resource {
+	case "alert":
+		return crutils.GetAlertSchema(), nil
+	case "blackduck":
+		return crutils.GetBlackDuckSchema(), nil
+	case "opssight":
+		return crutils.GetOpssightSchema(), nil
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("unknown resource %q", resource)
+}
+
+func kindFor(resource string) string {
+	return map[string]string{"alert": "Alert", "blackduck": "Blackduck", "opssight": "OpsSight"}[resource]
+}
+
+func ignoreAlreadyExists(err error) error {
+	if apierrs.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func ignoreNotFound(err error) error {
+	if apierrs.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (a *clusterApplier) ensureNamespace(namespace string) error {
+	_, err := a.kc.CoreV1().Namespaces().Create(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+	return ignoreAlreadyExists(err)
+}
+
+func (a *clusterApplier) deploy(namespace string, clusterScoped bool, resources []string, image string) error {
+	if err := a.ensureNamespace(namespace); err != nil {
+		return err
+	}
+	labels := map[string]string{"app": "synopsys-operator", "component": "operator"}
+	replicas := int32(1)
+	_, err := a.kc.AppsV1().Deployments(namespace).Create(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "synopsys-operator", Namespace: namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "synopsys-operator", Image: image}},
+				},
+			},
+		},
+	})
+	if err := ignoreAlreadyExists(err); err != nil {
+		return err
+	}
+	if clusterScoped {
+		_, err = a.kc.RbacV1().ClusterRoles().Create(&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: operatorAdmin, Labels: labels},
+			Rules:      []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+		})
+		if err := ignoreAlreadyExists(err); err != nil {
+			return err
+		}
+		_, err = a.kc.RbacV1().ClusterRoleBindings().Create(&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: operatorAdmin, Labels: labels},
+			RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: operatorAdmin},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "synopsys-operator", Namespace: namespace}},
+		})
+		if err := ignoreAlreadyExists(err); err != nil {
+			return err
+		}
+	}
+	scope := apiextensionsv1beta1.NamespaceScoped
+	if clusterScoped {
+		scope = apiextensionsv1beta1.ClusterScoped
+	}
+	for _, resource := range resources {
+		gvr, err := schemaFor(resource)
+		if err != nil {
+			return err
+		}
+		_, err = a.aec.ApiextensionsV1beta1().CustomResourceDefinitions().Create(&apiextensionsv1beta1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: crdName(gvr), Labels: map[string]string{"app": "synopsys-operator"}},
+			Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+				Group:   gvr.Group,
+				Version: gvr.Version,
+				Scope:   scope,
+				Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
+					Plural:   gvr.Resource,
+					Singular: resource,
+					Kind:     kindFor(resource),
+					ListKind: kindFor(resource) + "List",
+				},
+			},
+		})
+		if err := ignoreAlreadyExists(err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func crdName(gvr schema.GroupVersionResource) string {
+	return fmt.Sprintf("%s.%s", gvr.Resource, gvr.Group)
+}
+
+// crs returns the client of the custom resources of gvr in namespace. Like synopsysctl, the custom
+// resources of a cluster scoped crd have no namespace and name theirs in spec.namespace instead
+func (a *clusterApplier) crs(gvr schema.GroupVersionResource, namespace string) (dynamic.ResourceInterface, bool, error) {
+	crd, err := a.aec.ApiextensionsV1beta1().CustomResourceDefinitions().Get(crdName(gvr), metav1.GetOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+	if crd.Spec.Scope == apiextensionsv1beta1.ClusterScoped {
+		return a.dc.Resource(gvr), true, nil
+	}
+	return a.dc.Resource(gvr).Namespace(namespace), false, nil
+}
+
+func (a *clusterApplier) create(resource, namespace, name string) error {
+	gvr, err := schemaFor(resource)
+	if err != nil {
+		return err
+	}
+	if err := a.ensureNamespace(namespace); err != nil {
+		return err
+	}
+	client, clusterScoped, err := a.crs(gvr, namespace)
+	if err != nil {
+		return err
+	}
+	metadata := map[string]interface{}{"name": name}
+	if !clusterScoped {
+		metadata["namespace"] = namespace
+	}
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvr.GroupVersion().String(),
+		"kind":       kindFor(resource),
+		"metadata":   metadata,
+		"spec":       map[string]interface{}{"namespace": namespace},
+	}}
+	_, err = client.Create(cr, metav1.CreateOptions{})
+	return err
+}
+
+func (a *clusterApplier) remove(resource, namespace, name string) error {
+	gvr, err := schemaFor(resource)
+	if err != nil {
+		return err
+	}
+	client, _, err := a.crs(gvr, namespace)
+	if err != nil {
+		return err
+	}
+	return client.Delete(name, &metav1.DeleteOptions{})
+}
+
+// managesInstances returns true if the operator of namespace manages a custom resource: one in
+// namespace, or any of a cluster scoped crd
+func (a *clusterApplier) managesInstances(namespace string) (bool, error) {
+	for _, resource := range []string{"alert", "blackduck", "opssight"} {
+		gvr, _ := schemaFor(resource)
+		client, _, err := a.crs(gvr, namespace)
+		if apierrs.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		list, err := client.List(metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		if len(list.Items) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// destroy removes the operator of namespace. The cluster role and binding are removed with the
+// operator they bind, and the crds only with the last operator since the others still use them
+func (a *clusterApplier) destroy(namespace string, force bool) (bool, error) {
+	if !force {
+		manages, err := a.managesInstances(namespace)
+		if err != nil || manages {
+			return false, err
+		}
+	}
+	if err := ignoreNotFound(a.kc.AppsV1().Deployments(namespace).Delete("synopsys-operator", &metav1.DeleteOptions{})); err != nil {
+		return false, err
+	}
+	binding, err := a.kc.RbacV1().ClusterRoleBindings().Get(operatorAdmin, metav1.GetOptions{})
+	if err != nil && !apierrs.IsNotFound(err) {
+		return false, err
+	}
+	if err == nil && len(binding.Subjects) > 0 && binding.Subjects[0].Namespace == namespace {
+		if err := ignoreNotFound(a.kc.RbacV1().ClusterRoleBindings().Delete(operatorAdmin, &metav1.DeleteOptions{})); err != nil {
+			return false, err
+		}
+		if err := ignoreNotFound(a.kc.RbacV1().ClusterRoles().Delete(operatorAdmin, &metav1.DeleteOptions{})); err != nil {
+			return false, err
+		}
+	}
+	operators, err := a.kc.AppsV1().Deployments("").List(metav1.ListOptions{LabelSelector: "app=synopsys-operator"})
+	if err != nil {
+		return false, err
+	}
+	if len(operators.Items) == 0 {
+		for _, resource := range []string{"alert", "blackduck", "opssight"} {
+			gvr, _ := schemaFor(resource)
+			err := a.aec.ApiextensionsV1beta1().CustomResourceDefinitions().Delete(crdName(gvr), &metav1.DeleteOptions{})
+			if err := ignoreNotFound(err); err != nil {
+				return false, err
+			}
+		}
+	}
+	if namespace == "synopsys-operator" {
+		if err := ignoreNotFound(a.kc.CoreV1().Namespaces().Delete(namespace, &metav1.DeleteOptions{})); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testApplier() *clusterApplier {
+	return &clusterApplier{
+		kc:  fake.NewSimpleClientset(),
+		dc:  dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+		aec: apiextensionsfake.NewSimpleClientset(),
+	}
+}
+
+func TestDeployScope(t *testing.T) {
+	for _, clusterScoped := range []bool{false, true} {
+		a := testApplier()
+		if err := a.deploy("synopsys-operator", clusterScoped, []string{"alert"}, "synopsys-operator:2019.6.x"); err != nil {
+			t.Fatal(err)
+		}
+		crd, err := a.aec.ApiextensionsV1beta1().CustomResourceDefinitions().Get("alerts.synopsys.com", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := apiextensionsv1beta1.NamespaceScoped
+		if clusterScoped {
+			expected = apiextensionsv1beta1.ClusterScoped
+		}
+		if crd.Spec.Scope != expected {
+			t.Errorf("cluster scoped %v: expected scope %s, got %s", clusterScoped, expected, crd.Spec.Scope)
+		}
+	}
+}
+
+func TestCreateClusterScoped(t *testing.T) {
+	a := testApplier()
+	if err := a.deploy("synopsys-operator", true, []string{"alert"}, "synopsys-operator:2019.6.x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.create("alert", "alt-one", "alt-one"); err != nil {
+		t.Fatal(err)
+	}
+	gvr, _ := schemaFor("alert")
+	cr, err := a.dc.Resource(gvr).Get("alt-one", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns, _, _ := unstructured.NestedString(cr.Object, "spec", "namespace"); cr.GetNamespace() != "" || ns != "alt-one" {
+		t.Errorf("expected a cluster scoped alert of namespace alt-one, got %v", cr.Object)
+	}
+}
+
+func TestDestroyKeepsCRDsOfOtherOperators(t *testing.T) {
+	a := testApplier()
+	for _, ns := range []string{"so-one", "so-two"} {
+		if err := a.deploy(ns, false, []string{"alert", "blackduck"}, "synopsys-operator:2019.6.x"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.create("alert", "so-one", "alt-one"); err != nil {
+		t.Fatal(err)
+	}
+	crdExists := func(name string) bool {
+		_, err := a.aec.ApiextensionsV1beta1().CustomResourceDefinitions().Get(name, metav1.GetOptions{})
+		return !apierrs.IsNotFound(err)
+	}
+
+	if destroyed, err := a.destroy("so-two", false); !destroyed || err != nil {
+		t.Fatalf("expected so-two to be destroyed, got %v, %v", destroyed, err)
+	}
+	if !crdExists("alerts.synopsys.com") || !crdExists("blackducks.synopsys.com") {
+		t.Error("expected the crds of so-one to be kept")
+	}
+	if destroyed, err := a.destroy("so-one", false); destroyed || err != nil {
+		t.Fatalf("expected so-one to be kept while it manages alt-one, got %v, %v", destroyed, err)
+	}
+	if destroyed, err := a.destroy("so-one", true); !destroyed || err != nil {
+		t.Fatalf("expected so-one to be destroyed, got %v, %v", destroyed, err)
+	}
+	if crdExists("alerts.synopsys.com") || crdExists("blackducks.synopsys.com") {
+		t.Error("expected the crds to be removed with the last operator")
+	}
+}
+
+func TestDestroyRemovesRBACWithItsOperator(t *testing.T) {
+	a := testApplier()
+	if err := a.deploy("so-one", true, nil, "synopsys-operator:2019.6.x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.deploy("so-two", false, nil, "synopsys-operator:2019.6.x"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.destroy("so-two", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.kc.RbacV1().ClusterRoles().Get(operatorAdmin, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the cluster role of so-one to be kept: %v", err)
+	}
+	if _, err := a.destroy("so-one", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.kc.RbacV1().ClusterRoles().Get(operatorAdmin, metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Errorf("expected the cluster role to be removed, got %v", err)
+	}
+}
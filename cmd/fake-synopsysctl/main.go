@@ -0,0 +1,279 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// fake-synopsysctl mimics the deploy, create (including native), delete, destroy, update and --version
+// commands of synopsysctl so the test harness can be exercised without a real binary or a live cluster.
+//
+// It is configured through environment variables:
+//
+//	FAKE_SYNOPSYSCTL_LOG        append every invocation as a JSON line to this file
+//	FAKE_SYNOPSYSCTL_VERSION    version reported by --version (default 2019.6.0)
+//	FAKE_SYNOPSYSCTL_EXIT_CODE  exit with this code after logging the invocation
+//	FAKE_SYNOPSYSCTL_SLEEP      sleep for this duration (e.g. 10s) before running the command
+//	FAKE_SYNOPSYSCTL_APPLY      if "true", apply the equivalent objects to the cluster in KUBECONFIG
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultVersion = "2019.6.0"
+
+// invocation is one line of the FAKE_SYNOPSYSCTL_LOG file
+type invocation struct {
+	Time time.Time `json:"time"`
+	Args []string  `json:"args"`
+	Dir  string    `json:"dir"`
+}
+
+// command is a parsed synopsysctl command line
+type command struct {
+	positional []string
+	flags      map[string]string
+}
+
+func main() {
+	args := os.Args[1:]
+	if err := logInvocation(args); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to log invocation: %v\n", err)
+		os.Exit(1)
+	}
+	if d := os.Getenv("FAKE_SYNOPSYSCTL_SLEEP"); d != "" {
+		duration, err := time.ParseDuration(d)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid FAKE_SYNOPSYSCTL_SLEEP: %v\n", err)
+			os.Exit(1)
+		}
+		time.Sleep(duration)
+	}
+	if c := os.Getenv("FAKE_SYNOPSYSCTL_EXIT_CODE"); c != "" {
+		code, err := strconv.Atoi(c)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid FAKE_SYNOPSYSCTL_EXIT_CODE: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "fake failure with exit code %d\n", code)
+		os.Exit(code)
+	}
+	if err := run(parse(args)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func logInvocation(args []string) error {
+	path := os.Getenv("FAKE_SYNOPSYSCTL_LOG")
+	if path == "" {
+		return nil
+	}
+	dir, _ := os.Getwd()
+	line, err := json.Marshal(invocation{Time: time.Now(), Args: args, Dir: dir})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// parse splits args into positional arguments and --flag=value / -f=value / -f value flags
+func parse(args []string) command {
+	c := command{flags: map[string]string{}}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			c.positional = append(c.positional, arg)
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if idx := strings.Index(name, "="); idx >= 0 {
+			c.flags[canonical(name[:idx])] = name[idx+1:]
+			continue
+		}
+		// short flags that take a value may be separated from it by a space
+		if (name == "n" || name == "i") && i+1 < len(args) {
+			c.flags[canonical(name)] = args[i+1]
+			i++
+			continue
+		}
+		c.flags[canonical(name)] = "true"
+	}
+	return c
+}
+
+func canonical(flag string) string {
+	switch flag {
+	case "n":
+		return "namespace"
+	case "i":
+		return "image"
+	}
+	return flag
+}
+
+func (c command) arg(i int) string {
+	if i < len(c.positional) {
+		return c.positional[i]
+	}
+	return ""
+}
+
+func (c command) enabled(flag string) bool {
+	return c.flags[flag] == "true"
+}
+
+func run(c command) error {
+	if c.enabled("version") {
+		version := os.Getenv("FAKE_SYNOPSYSCTL_VERSION")
+		if version == "" {
+			version = defaultVersion
+		}
+		fmt.Printf("synopsysctl version %s\n", version)
+		return nil
+	}
+	var a applier = noopApplier{}
+	if os.Getenv("FAKE_SYNOPSYSCTL_APPLY") == "true" {
+		var err error
+		if a, err = newClusterApplier(); err != nil {
+			return err
+		}
+	}
+	switch c.arg(0) {
+	case "deploy":
+		return deploy(a, c)
+	case "create":
+		return create(a, c)
+	case "delete":
+		return remove(a, c)
+	case "destroy":
+		return destroy(a, c)
+	case "update":
+		return update(c)
+	case "":
+		return fmt.Errorf("no command was provided")
+	}
+	return fmt.Errorf("unknown command %q for \"synopsysctl\"", c.arg(0))
+}
+
+func deploy(a applier, c command) error {
+	clusterScoped := c.enabled("cluster-scoped")
+	namespace := c.flags["namespace"]
+	if clusterScoped && namespace != "" {
+		return fmt.Errorf("--cluster-scoped cannot be used with --namespace")
+	}
+	if namespace == "" {
+		namespace = "synopsys-operator"
+	}
+	resources := []string{}
+	for _, r := range []string{"alert", "blackduck", "opssight"} {
+		if c.enabled("enable-" + r) {
+			resources = append(resources, r)
+		}
+	}
+	if err := a.deploy(namespace, clusterScoped, resources, c.flags["image"]); err != nil {
+		return err
+	}
+	fmt.Printf("Deploying Synopsys Operator in namespace '%s'...\n", namespace)
+	fmt.Printf("Successfully deployed Synopsys Operator in namespace '%s'\n", namespace)
+	return nil
+}
+
+func create(a applier, c command) error {
+	resource, name := c.arg(1), c.arg(2)
+	if name == "native" {
+		return printNative(resource, c)
+	}
+	if name == "" {
+		return fmt.Errorf("this command takes 1 argument")
+	}
+	switch resource {
+	case "alert", "opssight":
+	case "blackduck":
+		for _, p := range []string{"admin-password", "postgres-password", "user-password"} {
+			if c.flags[p] == "" {
+				return fmt.Errorf("required flag(s) \"%s\" not set", p)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown resource %q", resource)
+	}
+	namespace := c.flags["namespace"]
+	if namespace == "" {
+		namespace = name
+	}
+	if err := a.create(resource, namespace, name); err != nil {
+		return err
+	}
+	fmt.Printf("Successfully created %s '%s' in namespace '%s'\n", resource, name, namespace)
+	return nil
+}
+
+func remove(a applier, c command) error {
+	resource, name := c.arg(1), c.arg(2)
+	if name == "" {
+		return fmt.Errorf("this command takes 1 argument")
+	}
+	namespace := c.flags["namespace"]
+	if namespace == "" {
+		namespace = name
+	}
+	if err := a.remove(resource, namespace, name); err != nil {
+		return err
+	}
+	fmt.Printf("Successfully deleted %s '%s' in namespace '%s'\n", resource, name, namespace)
+	return nil
+}
+
+func destroy(a applier, c command) error {
+	namespaces := c.positional[1:]
+	if len(namespaces) == 0 {
+		namespaces = []string{"synopsys-operator"}
+	}
+	for _, ns := range namespaces {
+		destroyed, err := a.destroy(ns, c.enabled("force"))
+		if err != nil {
+			return err
+		}
+		if !destroyed {
+			fmt.Printf("Synopsys Operator in namespace '%s' still manages instances, use --force to destroy it\n", ns)
+			continue
+		}
+		fmt.Printf("Finished destroying Synopsys Operator in namespace '%s'\n", ns)
+	}
+	return nil
+}
+
+func update(c command) error {
+	if c.arg(1) == "" {
+		return fmt.Errorf("update requires a resource")
+	}
+	fmt.Printf("Successfully updated %s\n", strings.TrimSpace(strings.Join(c.positional[1:], " ")))
+	return nil
+}
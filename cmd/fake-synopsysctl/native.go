@@ -0,0 +1,233 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+const defaultBlackDuckVersion = "2019.4.3"
+
+// component is a replication controller and its service, the way synopsysctl renders most containers
+type component struct {
+	name   string
+	image  string
+	memory string
+	port   int32
+}
+
+// printNative prints the objects "synopsysctl create <resource> native" prints. The objects are a small,
+// representative subset of what the real command renders; the native specs take the objects they
+// expect from the golden files, so they are not tied to the names made up here
+func printNative(resource string, c command) error {
+	name := c.arg(3)
+	if name == "" {
+		return fmt.Errorf("this command takes 1 argument")
+	}
+	namespace := c.flags["namespace"]
+	if namespace == "" {
+		namespace = name
+	}
+	persistent := c.flags["persistent-storage"] != "false"
+	var components []component
+	var objects []runtime.Object
+	switch resource {
+	case "alert":
+		components = []component{{name: name + "-alert", image: "docker.io/blackducksoftware/blackduck-alert:4.0.0", memory: "2560M", port: 8443}}
+		if c.flags["standalone"] != "false" {
+			components = append(components, component{name: name + "-cfssl", image: "docker.io/blackducksoftware/blackduck-cfssl:1.0.0", memory: "640M", port: 8888})
+		}
+		objects = append(objects,
+			configMap(name+"-alert-config", map[string]string{"ALERT_SERVER_PORT": "8443"}),
+			secret(name+"-alert-secret", map[string]string{"ALERT_ENCRYPTION_PASSWORD": randomPassword()}))
+		if persistent {
+			objects = append(objects, pvc(name+"-alert-pvc", "5G"))
+		}
+	case "blackduck":
+		for _, p := range []string{"admin-password", "postgres-password", "user-password"} {
+			if c.flags[p] == "" {
+				return fmt.Errorf("required flag(s) \"%s\" not set", p)
+			}
+		}
+		version := c.flags["version"]
+		if version == "" {
+			version = defaultBlackDuckVersion
+		}
+		components = []component{
+			{name: name + "-blackduck-webserver", image: "docker.io/blackducksoftware/blackduck-nginx:1.0.7", memory: "640M", port: 443},
+			{name: name + "-blackduck-webapp", image: "docker.io/blackducksoftware/blackduck-webapp:" + version, memory: "2560M", port: 8443},
+			{name: name + "-blackduck-registration", image: "docker.io/blackducksoftware/blackduck-registration:" + version, memory: "640M", port: 8443},
+			{name: name + "-blackduck-postgres", image: "docker.io/centos/postgresql-96-centos7:9.6", memory: "3072M", port: 5432},
+		}
+		objects = append(objects,
+			configMap(name+"-blackduck-config", map[string]string{"HUB_VERSION": version}),
+			secret(name+"-blackduck-db-creds", map[string]string{
+				"HUB_POSTGRES_ADMIN_PASSWORD_FILE": c.flags["admin-password"],
+				"HUB_POSTGRES_USER_PASSWORD_FILE":  c.flags["user-password"],
+				"HUB_POSTGRES_POSTGRES_PASSWORD":   c.flags["postgres-password"],
+			}))
+		if persistent {
+			objects = append(objects, pvc(name+"-blackduck-postgres", "150Gi"))
+		}
+	case "opssight":
+		components = []component{
+			{name: name + "-opssight-core", image: "docker.io/blackducksoftware/opssight-core:2.2.3", memory: "1300M", port: 3001},
+			{name: name + "-opssight-pod-processor", image: "docker.io/blackducksoftware/opssight-pod-processor:2.2.3", memory: "500M", port: 3002},
+			{name: name + "-opssight-scanner", image: "docker.io/blackducksoftware/opssight-scanner:2.2.3", memory: "500M", port: 3003},
+		}
+		objects = append(objects,
+			configMap(name+"-opssight-config", map[string]string{"opssight.json": "{}"}),
+			secret(name+"-opssight-blackduck", map[string]string{"securedRegistries.json": "{}"}))
+	default:
+		return fmt.Errorf("unknown resource %q", resource)
+	}
+	for _, comp := range components {
+		objects = append(objects, comp.replicationController(), comp.service())
+	}
+	for _, obj := range objects {
+		setNamespace(obj, namespace, resource, name)
+	}
+	return printObjects(objects, c.flags["output"])
+}
+
+func (comp component) labels() map[string]string {
+	return map[string]string{"component": comp.name}
+}
+
+func (comp component) replicationController() runtime.Object {
+	replicas := int32(1)
+	return &corev1.ReplicationController{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ReplicationController"},
+		ObjectMeta: metav1.ObjectMeta{Name: comp.name, Labels: comp.labels()},
+		Spec: corev1.ReplicationControllerSpec{
+			Replicas: &replicas,
+			Selector: comp.labels(),
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: comp.labels()},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  comp.name,
+						Image: comp.image,
+						Ports: []corev1.ContainerPort{{ContainerPort: comp.port, Protocol: corev1.ProtocolTCP}},
+						Resources: corev1.ResourceRequirements{
+							Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(comp.memory)},
+							Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(comp.memory)},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func (comp component) service() runtime.Object {
+	return &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: comp.name, Labels: comp.labels()},
+		Spec: corev1.ServiceSpec{
+			Selector: comp.labels(),
+			Ports:    []corev1.ServicePort{{Name: fmt.Sprintf("port-%d", comp.port), Port: comp.port, Protocol: corev1.ProtocolTCP}},
+			Type:     corev1.ServiceTypeClusterIP,
+		},
+	}
+}
+
+func configMap(name string, data map[string]string) runtime.Object {
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Data:       data,
+	}
+}
+
+func secret(name string, data map[string]string) runtime.Object {
+	return &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Type:       corev1.SecretTypeOpaque,
+		StringData: data,
+	}
+}
+
+func pvc(name, size string) runtime.Object {
+	return &corev1.PersistentVolumeClaim{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources:   corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}},
+		},
+	}
+}
+
+func setNamespace(obj runtime.Object, namespace, resource, name string) {
+	accessor := obj.(metav1.Object)
+	accessor.SetNamespace(namespace)
+	labels := accessor.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels["app"] = resource
+	labels["name"] = name
+	accessor.SetLabels(labels)
+}
+
+// randomPassword is a generated password like the ones synopsysctl creates when none is given
+func randomPassword() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// printObjects prints objects as YAML documents or, for "json", as a v1 List
+func printObjects(objects []runtime.Object, output string) error {
+	switch output {
+	case "", "yaml":
+		for _, obj := range objects {
+			out, err := yaml.Marshal(obj)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("---\n%s", out)
+		}
+		return nil
+	case "json":
+		list := &corev1.List{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "List"}}
+		for _, obj := range objects {
+			list.Items = append(list.Items, runtime.RawExtension{Object: obj})
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+	return fmt.Errorf("unknown output format %q", output)
+}
@@ -3,15 +3,11 @@ module github.com/blackducksoftware/cloud-native-tests
 go 1.12
 
 require (
-	github.com/juju/errors v0.0.0-20190207033735-e65537c515d7
-	github.com/juju/loggo v0.0.0-20190526231331-6e530bcce5d8 // indirect
-	github.com/juju/testing v0.0.0-20190613124551-e81189438503 // indirect
 	github.com/onsi/ginkgo v1.8.0
 	github.com/onsi/gomega v1.5.0
-	github.com/sirupsen/logrus v1.4.2
-	gopkg.in/mgo.v2 v2.0.0-20180705113604-9856a29383ce // indirect
 	k8s.io/api v0.0.0-20190703165250-25c1e1427dc7
 	k8s.io/apiextensions-apiserver v0.0.0-20190703050734-605b9c7e5417
 	k8s.io/apimachinery v0.0.0-20190703161233-99a332dfcf06
 	k8s.io/client-go v0.0.0-20190703045505-b3101450e21d
+	sigs.k8s.io/yaml v1.1.0
 )
@@ -1,25 +1,27 @@
 package smoke_test
 
 import (
+	"flag"
 	"fmt"
 	"testing"
-	"time"
 
 	utils "github.com/blackducksoftware/cloud-native-tests/utils"
-	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	"github.com/blackducksoftware/cloud-native-tests/utils/framework"
 	crutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/cr"
 	crdutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/crd"
-	namespaceutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/namespace"
 	podutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/pod"
+	"github.com/blackducksoftware/cloud-native-tests/utils/preflight"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	corev1 "k8s.io/api/core/v1"
-	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 )
 
+func init() {
+	config.RegisterFlags(flag.CommandLine)
+}
+
 func TestGinkgo(t *testing.T) {
 	fmt.Printf("[DEBUG] TestGinkgo\n")
 	RegisterFailHandler(Fail)
@@ -27,21 +29,14 @@ func TestGinkgo(t *testing.T) {
 	fmt.Printf("[DEBUG] After RunSpecs\n")
 }
 
+var _ = BeforeSuite(preflight.BeforeSuite)
+
 var _ = Describe("smoke", func() {
 	fmt.Printf("[DEBUG] smoke\n")
 
 	defer GinkgoRecover()
 
-	rc, _ := k8sutils.GetRestConfig()
-	kc, _ := k8sutils.GetKubeClient(rc)
-	dc, _ := k8sutils.GetDynamicClient(rc)
-
-	// get Crd
-	apiExtensionClient, err := apiextensionsclient.NewForConfig(rc)
-	if err != nil {
-		Fail(fmt.Sprintf("error creating the api extension client: %+v", err))
-	}
-	mySynopsysCtl := utils.NewSynopsysctl("synopsysctl")
+	f := framework.NewFramework("smoke")
 
 	Context("tests", func() {
 		fmt.Printf("[DEBUG] tests\n")
@@ -49,152 +44,161 @@ var _ = Describe("smoke", func() {
 		Specify("cluster scoped operations", func() {
 			fmt.Printf("[DEBUG] cluster scoped operations\n")
 			// Deploy Operator in Cluster Scope
-			out, err := mySynopsysCtl.Exec("deploy", "--cluster-scoped", "--enable-alert", "--enable-blackduck", "--enable-opssight", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
+			result, err := f.DeployOperator(utils.DeployOptions{
+				ClusterScoped:    true,
+				EnabledResources: []utils.Resource{utils.AlertResource, utils.BlackDuckResource, utils.OpsSightResource},
+				OperatorImage:    config.Get().OperatorImage,
+			})
 			if err != nil {
-				Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+				Fail(fmt.Sprintf("%v\n%s", err, result))
 			}
 			// Wait for operator to be running
 			soLabel := labels.NewSelector()
 			r, _ := labels.NewRequirement("app", selection.Equals, []string{"synopsys-operator"})
 			soLabel.Add(*r)
-			_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "synopsys-operator", soLabel, 2, time.Duration(10*time.Second))
+			_, err = podutils.WaitForPodsWithLabelRunningReady(f.KubeClient, config.Get().Namespaces.Operator, soLabel, 2, config.Get().Timeouts.PodReady.Duration)
 			if err != nil {
 				Fail(fmt.Sprintf("Synopsys Operator pods failed to come up: %v", err))
 			}
 			fmt.Printf("[DEBUG] Synopsys Operator is running\n")
 			// Wait for CRDs to be running
-			err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "alerts.synopsys.com", 10)
+			err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "alerts.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 			if err != nil {
 				Fail(fmt.Sprintf("Alert crd was not added: %v", err))
 			}
-			err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "blackducks.synopsys.com", 10)
+			err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "blackducks.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 			if err != nil {
 				Fail(fmt.Sprintf("Black Duck crd was not added: %v", err))
 			}
-			err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "opssights.synopsys.com", 10)
+			err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "opssights.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 			if err != nil {
 				Fail(fmt.Sprintf("OpsSight crd was not added: %v", err))
 			}
 			fmt.Printf("[DEBUG] CRDs exists\n")
-			// Create an Alert
-			out, err = mySynopsysCtl.Exec("create", "alert", "alt-one", "--standalone=false", "--persistent-storage=false")
+			// Create an Alert; synopsysctl creates each instance in a namespace of the same name
+			alertName := f.UniqueName("alt")
+			f.DeleteNamespaceOnCleanup(alertName)
+			result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.CreateAlertOptions{
+				Name:              alertName,
+				Standalone:        utils.BoolPtr(false),
+				PersistentStorage: utils.BoolPtr(false),
+			})
 			if err != nil {
-				Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+				Fail(fmt.Sprintf("%v\n%s", err, result))
 			}
-			time.Sleep(1 * time.Second)
-			alertExists, err := dc.Resource(crutils.GetAlertSchema()).Namespace("alt-one").Get("alt-one", metav1.GetOptions{})
+			// the crds of a cluster scoped operator are cluster scoped too
+			shape, err := crdutils.GetShape(f.APIExtensionClient, crdutils.AlertCRDName)
 			if err != nil {
-				Fail(fmt.Sprintf("bad get : %s", err))
+				Fail(fmt.Sprintf("failed to get the alert crd: %v", err))
 			}
-			if alertExists == nil {
-				Fail(fmt.Sprintf("Alert CR was not created : %v", alertExists))
+			err = crutils.WaitForAlertCR(f.KubeClient.RESTClient(), shape.Namespace(alertName), alertName, true, 0, 0)
+			if err != nil {
+				Fail(fmt.Sprintf("Alert CR was not created: %v", err))
 			}
 			By("Alert CR exists")
 			// Create a Black Duck
-			out, err = mySynopsysCtl.Exec("create", "blackduck", "bd-one", "--admin-password=blackduck", "--postgres-password=blackduck", "--user-password=blackduck")
+			blackDuckName := f.UniqueName("bd")
+			f.DeleteNamespaceOnCleanup(blackDuckName)
+			result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.CreateBlackDuckOptions{
+				Name:             blackDuckName,
+				AdminPassword:    "blackduck",
+				PostgresPassword: "blackduck",
+				UserPassword:     "blackduck",
+			})
 			if err != nil {
-				Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+				Fail(fmt.Sprintf("%v\n%s", err, result))
 			}
-			time.Sleep(1 * time.Second)
-			blackDuckExists, err := dc.Resource(crutils.GetBlackDuckSchema()).Namespace("bd-one").Get("bd-one", metav1.GetOptions{})
+			shape, err = crdutils.GetShape(f.APIExtensionClient, crdutils.BlackDuckCRDName)
 			if err != nil {
-				Fail(fmt.Sprintf("bad get : %s", err))
+				Fail(fmt.Sprintf("failed to get the Black Duck crd: %v", err))
 			}
-			if blackDuckExists == nil {
-				Fail(fmt.Sprintf("Black Duck CR was not created : %v", blackDuckExists))
+			err = crutils.WaitForBlackDuckCR(f.KubeClient.RESTClient(), shape.Namespace(blackDuckName), blackDuckName, true, 0, 0)
+			if err != nil {
+				Fail(fmt.Sprintf("Black Duck CR was not created: %v", err))
 			}
 			By("Black Duck CR exists")
 			// Create an OpsSight
-			out, err = mySynopsysCtl.Exec("create", "opssight", "ops-one")
+			opsSightName := f.UniqueName("ops")
+			f.DeleteNamespaceOnCleanup(opsSightName)
+			result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.CreateOpsSightOptions{
+				Name: opsSightName,
+			})
 			if err != nil {
-				Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+				Fail(fmt.Sprintf("%v\n%s", err, result))
 			}
-			time.Sleep(1 * time.Second)
-			opsSightExists, err := dc.Resource(crutils.GetOpssightSchema()).Namespace("ops-one").Get("ops-one", metav1.GetOptions{})
+			shape, err = crdutils.GetShape(f.APIExtensionClient, crdutils.OpsSightCRDName)
 			if err != nil {
-				Fail(fmt.Sprintf("bad get : %s", err))
+				Fail(fmt.Sprintf("failed to get the OpsSight crd: %v", err))
 			}
-			if opsSightExists == nil {
-				Fail(fmt.Sprintf("OpsSight CR was not created : %v", opsSightExists))
+			err = crutils.WaitForOpsSightCR(f.KubeClient.RESTClient(), shape.Namespace(opsSightName), opsSightName, true, 0, 0)
+			if err != nil {
+				Fail(fmt.Sprintf("OpsSight CR was not created: %v", err))
 			}
 			By("Opssight CR exists")
-			// Clean Up
-			// TODO : change to cleanup with synopsysctl
-			kc.CoreV1().Namespaces().Delete("synopsys-operator", &metav1.DeleteOptions{})
-			kc.CoreV1().Namespaces().Delete("alt-one", &metav1.DeleteOptions{})
-			kc.CoreV1().Namespaces().Delete("bd-one", &metav1.DeleteOptions{})
-			kc.CoreV1().Namespaces().Delete("ops-one", &metav1.DeleteOptions{})
-			kc.RbacV1().ClusterRoles().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-			kc.RbacV1().ClusterRoleBindings().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-			apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("alerts.synopsys.com", &metav1.DeleteOptions{})
-			apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("blackducks.synopsys.com", &metav1.DeleteOptions{})
-			apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("opssights.synopsys.com", &metav1.DeleteOptions{})
-			namespaceutils.WaitForNamespacesDeleted(kc, []string{"synopsys-operator", "alt-one", "bd-one", "ops-one"}, time.Duration(30*time.Second))
 		})
 
 		Specify("namespace scoped operations", func() {
 			// Deploy Operator in Namespace Scope
-			kc.CoreV1().Namespaces().Create(&corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Namespace: "so-test",
-					Name:      "so-test",
-				},
+			ns, err := f.CreateNamespace("so-test")
+			if err != nil {
+				Fail(err.Error())
+			}
+			result, err := f.DeployOperator(utils.DeployOptions{
+				Namespace:        ns.Name,
+				EnabledResources: []utils.Resource{utils.AlertResource, utils.BlackDuckResource},
+				OperatorImage:    config.Get().OperatorImage,
 			})
-			out, err := mySynopsysCtl.Exec("deploy", "-n=so-test", "--enable-alert", "--enable-blackduck", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
 			if err != nil {
-				Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+				Fail(fmt.Sprintf("%v\n%s", err, result))
 			}
 			// Wait for Operator to be running
 			soLabel := labels.NewSelector()
 			r, _ := labels.NewRequirement("app", selection.Equals, []string{"synopsys-operator"})
 			soLabel.Add(*r)
-			_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "so-test", soLabel, 2, time.Duration(10*time.Second))
+			_, err = podutils.WaitForPodsWithLabelRunningReady(f.KubeClient, ns.Name, soLabel, 2, config.Get().Timeouts.PodReady.Duration)
 			if err != nil {
 				Fail(fmt.Sprintf("Synopsys Operator pods failed to come up: %v", err))
 			}
 			// Wait for CRDs to be running
-			err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "alerts.synopsys.com", 10)
+			err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "alerts.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 			if err != nil {
 				Fail(fmt.Sprintf("Alert crd was not added: %v", err))
 			}
-			err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "blackducks.synopsys.com", 10)
+			err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "blackducks.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 			if err != nil {
 				Fail(fmt.Sprintf("Black Duck crd was not added: %v", err))
 			}
 			// Create an Alert
-			out, err = mySynopsysCtl.Exec("create", "alert", "alt-one", "--namespace=so-test", "--standalone=false", "--persistent-storage=false")
+			result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.CreateAlertOptions{
+				Name:              "alt-one",
+				Namespace:         ns.Name,
+				Standalone:        utils.BoolPtr(false),
+				PersistentStorage: utils.BoolPtr(false),
+			})
 			if err != nil {
-				Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+				Fail(fmt.Sprintf("%v\n%s", err, result))
 			}
-			time.Sleep(1 * time.Second)
-			alertExists, err := dc.Resource(crutils.GetAlertSchema()).Namespace("so-test").Get("alt-one", metav1.GetOptions{})
+			err = crutils.WaitForAlertCR(f.KubeClient.RESTClient(), ns.Name, "alt-one", true, 0, 0)
 			if err != nil {
-				Fail(fmt.Sprintf("bad get : %s", err))
-			}
-			if alertExists == nil {
-				Fail(fmt.Sprintf("Alert CR was not created : %v", alertExists))
+				Fail(fmt.Sprintf("Alert CR was not created: %v", err))
 			}
 			By("Alert CR exists")
 			// Create a Black Duck
-			out, err = mySynopsysCtl.Exec("create", "blackduck", "bd-one", "--namespace=so-test", "--admin-password=blackduck", "--postgres-password=blackduck", "--user-password=blackduck")
+			result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.CreateBlackDuckOptions{
+				Name:             "bd-one",
+				Namespace:        ns.Name,
+				AdminPassword:    "blackduck",
+				PostgresPassword: "blackduck",
+				UserPassword:     "blackduck",
+			})
 			if err != nil {
-				Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+				Fail(fmt.Sprintf("%v\n%s", err, result))
 			}
-			time.Sleep(1 * time.Second)
-			blackDuckExists, err := dc.Resource(crutils.GetBlackDuckSchema()).Namespace("so-test").Get("bd-one", metav1.GetOptions{})
+			err = crutils.WaitForBlackDuckCR(f.KubeClient.RESTClient(), ns.Name, "bd-one", true, 0, 0)
 			if err != nil {
-				Fail(fmt.Sprintf("bad get : %s", err))
-			}
-			if blackDuckExists == nil {
-				Fail(fmt.Sprintf("Black Duck CR was not created : %v", blackDuckExists))
+				Fail(fmt.Sprintf("Black Duck CR was not created: %v", err))
 			}
 			By("Black Duck CR exists")
-			// Clean Up
-			// TODO : change to cleanup with synopsysctl
-			kc.CoreV1().Namespaces().Delete("so-test", &metav1.DeleteOptions{})
-			apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("alerts.synopsys.com", &metav1.DeleteOptions{})
-			apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("blackducks.synopsys.com", &metav1.DeleteOptions{})
-			namespaceutils.WaitForNamespacesDeleted(kc, []string{"so-test"}, time.Duration(30*time.Second))
 		})
 	})
 })
@@ -1,30 +1,44 @@
 package alert_operator_test
 
 import (
+	"context"
+	"flag"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/blackducksoftware/cloud-native-tests/utils"
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	"github.com/blackducksoftware/cloud-native-tests/utils/preflight"
 )
 
+func init() {
+	config.RegisterFlags(flag.CommandLine)
+}
+
 func TestGinkgo(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Alert Operator Test Suite")
 }
 
+var _ = BeforeSuite(preflight.BeforeSuite)
+
 var _ = Describe("Alert Duck Operator", func() {
 
-	mySynopsysCtl := utils.NewSynopsysctl("synopsysctl")
+	mySynopsysCtl := utils.NewSynopsysctl("")
 
 	Context("in Cluster Scope", func() {
 		BeforeEach(func() {
-			mySynopsysCtl.Exec("deploy", "--cluster-scoped", "--enable-alert", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
+			mySynopsysCtl.RunCommand(context.Background(), utils.DeployOptions{
+				ClusterScoped:    true,
+				EnabledResources: []utils.Resource{utils.AlertResource},
+				OperatorImage:    config.Get().OperatorImage,
+			})
 			// TODO: wait until pods are running
 		})
 		AfterEach(func() {
-			mySynopsysCtl.Exec("destroy")
+			mySynopsysCtl.RunCommand(context.Background(), utils.DestroyOptions{})
 			// TODO: wait until pads are deleted
 		})
 
@@ -1,13 +1,17 @@
 package synopsys_operator_test
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"testing"
 	"time"
 
 	utils "github.com/blackducksoftware/cloud-native-tests/utils"
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
 	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
 	podutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/pod"
+	"github.com/blackducksoftware/cloud-native-tests/utils/preflight"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -16,12 +20,18 @@ import (
 	"k8s.io/apimachinery/pkg/selection"
 )
 
+func init() {
+	config.RegisterFlags(flag.CommandLine)
+}
+
 // TestGinkgo TODO
 func TestGinkgo(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Synopsys Operator - Operator Manager Test Suite")
 }
 
+var _ = BeforeSuite(preflight.BeforeSuite)
+
 var _ = Describe("Synopsys Operator Manager Tests", func() {
 
 	defer GinkgoRecover()
@@ -35,7 +45,7 @@ var _ = Describe("Synopsys Operator Manager Tests", func() {
 	// Fail(fmt.Sprintf("error creating the api extension client: %+v", err))
 	// }
 
-	mySynopsysCtl := utils.NewSynopsysctl("synopsysctl")
+	mySynopsysCtl := utils.NewSynopsysctl("")
 
 	Describe("operator in namespace scope", func() {
 
@@ -47,15 +57,22 @@ var _ = Describe("Synopsys Operator Manager Tests", func() {
 					Name:      "alert",
 				},
 			})
-			out, err := mySynopsysCtl.Exec("deploy", "-n=alert", "--enable-alert", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
+			result, err := mySynopsysCtl.RunCommand(context.Background(), utils.DeployOptions{
+				Namespace:        "alert",
+				EnabledResources: []utils.Resource{utils.AlertResource},
+				OperatorImage:    config.Get().OperatorImage,
+			})
 			if err != nil {
-				Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+				Fail(fmt.Sprintf("%v\n%s", err, result))
 			}
 
 			Specify("default alert", func() {
 				// assumes synopsysctl works and we have an operator running in namespace, and crd is made correctly
 				// assuming this, create an alert
-				_, err := mySynopsysCtl.Exec("create", "alert", "alt", "-n=alert")
+				_, err := mySynopsysCtl.RunCommand(context.Background(), utils.CreateAlertOptions{
+					Name:      "alt",
+					Namespace: "alert",
+				})
 
 				//TODO: wait till pods are running with label app=alert
 				label := labels.NewSelector()
@@ -63,7 +80,7 @@ var _ = Describe("Synopsys Operator Manager Tests", func() {
 				r2, _ := labels.NewRequirement("name", selection.Equals, []string{"alt"})
 				label.Add(*r, *r2)
 
-				_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "alert", label, 2, time.Duration(30*time.Second))
+				_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "alert", label, 2, config.Get().Timeouts.PodReady.Duration)
 				if err != nil {
 					Fail(fmt.Sprintf("alert pods failed to come up: %v", err))
 				}
@@ -78,9 +95,15 @@ var _ = Describe("Synopsys Operator Manager Tests", func() {
 			AfterEach(func() {
 				// cleanup
 				time.Sleep(5 * time.Second)
-				mySynopsysCtl.Exec("delete", "alert", "alt", "-n=alert")
+				mySynopsysCtl.RunCommand(context.Background(), utils.DeleteOptions{
+					Resource:  utils.AlertResource,
+					Name:      "alt",
+					Namespace: "alert",
+				})
 				time.Sleep(5 * time.Second)
-				mySynopsysCtl.Exec("destroy", "alert")
+				mySynopsysCtl.RunCommand(context.Background(), utils.DestroyOptions{
+					Namespaces: []string{"alert"},
+				})
 			})
 
 		})
@@ -1,53 +1,67 @@
 package synopsysctl_test
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
-	"time"
 
 	utils "github.com/blackducksoftware/cloud-native-tests/utils"
-	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	"github.com/blackducksoftware/cloud-native-tests/utils/framework"
 	crutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/cr"
 	crdutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/crd"
-	namespaceutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/namespace"
 	podutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/pod"
+	"github.com/blackducksoftware/cloud-native-tests/utils/native"
+	"github.com/blackducksoftware/cloud-native-tests/utils/preflight"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	corev1 "k8s.io/api/core/v1"
-	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 )
 
+func init() {
+	config.RegisterFlags(flag.CommandLine)
+}
+
 func TestGinkgo(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Ginkgo Suite")
 }
 
+var _ = BeforeSuite(preflight.BeforeSuite)
+
 var _ = Describe("synopsysctl", func() {
 
 	defer GinkgoRecover()
 
-	rc, _ := k8sutils.GetRestConfig()
-	kc, _ := k8sutils.GetKubeClient(rc)
-
-	// get Crd
-	apiExtensionClient, err := apiextensionsclient.NewForConfig(rc)
-	if err != nil {
-		Fail(fmt.Sprintf("error creating the api extension client: %+v", err))
-	}
-	mySynopsysCtl := utils.NewSynopsysctl("synopsysctl")
+	f := framework.NewFramework("synopsysctl")
 
 	Describe("--version command", func() {
 		Context("--version", func() {
-			Specify("the version is 2019.6.0", func() {
-				out, err := mySynopsysCtl.Exec("--version")
+			Specify("the version is in the format 'synopsysctl version YEAR.MONTH.PATCH'", func() {
+				ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Command.Duration)
+				defer cancel()
+				result, err := f.Synopsysctl.Run(ctx, "--version")
+				if err != nil {
+					Fail(fmt.Sprintf("%s\n%s", err, result))
+				}
+				Expect(strings.TrimSpace(result.Stdout)).To(MatchRegexp(`^synopsysctl version \d{4}\.\d{1,2}\.\d+$`))
+			})
+			Specify("the version is from the same release as the operator image", func() {
+				ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Command.Duration)
+				defer cancel()
+				version, err := f.Synopsysctl.Version(ctx)
 				if err != nil {
-					Fail(fmt.Sprintf("%s", err))
+					Fail(fmt.Sprintf("%v", err))
 				}
-				Expect(strings.TrimSpace(out)).To(Equal("synopsysctl version 2019.6.0"))
+				operatorVersion, err := utils.OperatorImageVersion(config.Get().OperatorImage)
+				if err != nil {
+					Fail(fmt.Sprintf("%v", err))
+				}
+				Expect(version.Compare(operatorVersion)).To(Equal(0), "synopsysctl %s, operator %s", version, operatorVersion)
 			})
 		})
 	})
@@ -55,12 +69,20 @@ var _ = Describe("synopsysctl", func() {
 	Describe("deploy command", func() {
 
 		Context("deploying Synopsys Operator in cluster scope", func() {
+			BeforeEach(func() {
+				// --cluster-scoped was added to deploy in 2019.6.0
+				utils.SkipUnlessSynopsysctlVersion(f.Synopsysctl, "2019.6.0", "")
+			})
 
 			Specify("all crds can be enabled", func() {
 				// BEGIN SETUP
-				out, err := mySynopsysCtl.Exec("deploy", "--cluster-scoped", "--enable-alert", "--enable-blackduck", "--enable-opssight", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
+				result, err := f.DeployOperator(utils.DeployOptions{
+					ClusterScoped:    true,
+					EnabledResources: []utils.Resource{utils.AlertResource, utils.BlackDuckResource, utils.OpsSightResource},
+					OperatorImage:    config.Get().OperatorImage,
+				})
 				if err != nil {
-					Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+					Fail(fmt.Sprintf("%v\n%s", err, result))
 				}
 				// END SETUP
 
@@ -68,37 +90,29 @@ var _ = Describe("synopsysctl", func() {
 				label := labels.NewSelector()
 				r, _ := labels.NewRequirement("app", selection.Equals, []string{"synopsys-operator"})
 				label.Add(*r)
-				_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "synopsys-operator", label, 2, time.Duration(10*time.Second))
+				_, err = podutils.WaitForPodsWithLabelRunningReady(f.KubeClient, config.Get().Namespaces.Operator, label, 2, config.Get().Timeouts.PodReady.Duration)
 				if err != nil {
 					Fail(fmt.Sprintf("Operator pods failed to come up: %v", err))
 				}
-				err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "alerts.synopsys.com", 10)
+				err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "alerts.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 				if err != nil {
 					Fail(fmt.Sprintf("alert crd was not added: %v", err))
 				}
-				err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "blackducks.synopsys.com", 10)
+				err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "blackducks.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 				if err != nil {
 					Fail(fmt.Sprintf("black duck crd was not added: %v", err))
 				}
-				err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "opssights.synopsys.com", 10)
+				err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "opssights.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 				if err != nil {
 					Fail(fmt.Sprintf("opssight crd was not added: %v", err))
 				}
-				// END VERIFICATION
-
-				// BEGIN CLEANUP
-				kc.CoreV1().Namespaces().Delete("synopsys-operator", &metav1.DeleteOptions{})
-				kc.RbacV1().ClusterRoles().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-				kc.RbacV1().ClusterRoleBindings().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("alerts.synopsys.com", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("blackducks.synopsys.com", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("opssights.synopsys.com", &metav1.DeleteOptions{})
-				//TODO:WAIT FOR NAMESPACE AND CRD TO BE DELETED
-				err = namespaceutils.WaitForNamespacesDeleted(kc, []string{"synopsys-operator"}, time.Duration(30*time.Second))
-				if err != nil {
-					fmt.Printf("WAIT NS ERROR : %+v", err)
+				for _, name := range []string{crdutils.AlertCRDName, crdutils.BlackDuckCRDName, crdutils.OpsSightCRDName} {
+					shape, err := crdutils.GetShape(f.APIExtensionClient, name)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(shape).To(crdutils.BeClusterScoped())
+					Expect(shape).To(crdutils.HaveGroup("synopsys.com"))
 				}
-				// END CLEANUP
+				// END VERIFICATION
 			})
 
 			Specify("just alert crd can be enabled", func() {
@@ -148,37 +162,43 @@ var _ = Describe("synopsysctl", func() {
 
 				Specify("one operator is deployed in 'alert' namespace with alert crd enabled, one operator is deployed in 'bd' namespace with blackduck crd enabled, and one operator is deployed in 'alert-and-bd' namespace with both alert and blackduck crd enabled", func() {
 					// BEGIN SETUP
-					kc.CoreV1().Namespaces().Create(&corev1.Namespace{
-						ObjectMeta: metav1.ObjectMeta{
-							Namespace: "alert",
-							Name:      "alert",
-						},
+					alertNs, err := f.CreateNamespace("alert")
+					if err != nil {
+						Fail(err.Error())
+					}
+					result, err := f.DeployOperator(utils.DeployOptions{
+						Namespace:        alertNs.Name,
+						EnabledResources: []utils.Resource{utils.AlertResource},
+						OperatorImage:    config.Get().OperatorImage,
 					})
-					out, err := mySynopsysCtl.Exec("deploy", "-n=alert", "--enable-alert", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
 					if err != nil {
-						Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+						Fail(fmt.Sprintf("%v\n%s", err, result))
 					}
 
-					kc.CoreV1().Namespaces().Create(&corev1.Namespace{
-						ObjectMeta: metav1.ObjectMeta{
-							Namespace: "bd",
-							Name:      "bd",
-						},
+					bdNs, err := f.CreateNamespace("bd")
+					if err != nil {
+						Fail(err.Error())
+					}
+					result, err = f.DeployOperator(utils.DeployOptions{
+						Namespace:        bdNs.Name,
+						EnabledResources: []utils.Resource{utils.BlackDuckResource},
+						OperatorImage:    config.Get().OperatorImage,
 					})
-					out, err = mySynopsysCtl.Exec("deploy", "-n=bd", "--enable-blackduck", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
 					if err != nil {
-						Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+						Fail(fmt.Sprintf("%v\n%s", err, result))
 					}
 
-					kc.CoreV1().Namespaces().Create(&corev1.Namespace{
-						ObjectMeta: metav1.ObjectMeta{
-							Namespace: "alert-and-bd",
-							Name:      "alert-and-bd",
-						},
+					alertAndBdNs, err := f.CreateNamespace("alert-and-bd")
+					if err != nil {
+						Fail(err.Error())
+					}
+					result, err = f.DeployOperator(utils.DeployOptions{
+						Namespace:        alertAndBdNs.Name,
+						EnabledResources: []utils.Resource{utils.AlertResource, utils.BlackDuckResource},
+						OperatorImage:    config.Get().OperatorImage,
 					})
-					out, err = mySynopsysCtl.Exec("deploy", "-n=alert-and-bd", "--enable-alert", "--enable-blackduck", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
 					if err != nil {
-						Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+						Fail(fmt.Sprintf("%v\n%s", err, result))
 					}
 					// END SETUP
 
@@ -186,60 +206,37 @@ var _ = Describe("synopsysctl", func() {
 					label := labels.NewSelector()
 					r, _ := labels.NewRequirement("app", selection.Equals, []string{"synopsys-operator"})
 					label.Add(*r)
-					_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "alert", label, 2, time.Duration(30*time.Second))
+					_, err = podutils.WaitForPodsWithLabelRunningReady(f.KubeClient, alertNs.Name, label, 2, config.Get().Timeouts.PodReady.Duration)
 					if err != nil {
 						Fail(fmt.Sprintf("Operator pods failed to come up: %v", err))
 					}
-					// TODO: make sure the crd has scope: Namespaced
-					err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "alerts.synopsys.com", 10)
+					err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "alerts.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 					if err != nil {
 						Fail(fmt.Sprintf("alert crd was not added: %v", err))
 					}
+					Expect(crdutils.GetShape(f.APIExtensionClient, crdutils.AlertCRDName)).To(crdutils.BeNamespaced())
 
 					label = labels.NewSelector()
 					r, _ = labels.NewRequirement("app", selection.Equals, []string{"synopsys-operator"})
 					label.Add(*r)
-					_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "bd", label, 2, time.Duration(30*time.Second))
+					_, err = podutils.WaitForPodsWithLabelRunningReady(f.KubeClient, bdNs.Name, label, 2, config.Get().Timeouts.PodReady.Duration)
 					if err != nil {
 						Fail(fmt.Sprintf("Operator pods failed to come up: %v", err))
 					}
-					// TODO: make sure the crd has scope: Namespaced
-					err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "blackducks.synopsys.com", 10)
+					err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "blackducks.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 					if err != nil {
 						Fail(fmt.Sprintf("black duck crd was not added: %v", err))
 					}
+					Expect(crdutils.GetShape(f.APIExtensionClient, crdutils.BlackDuckCRDName)).To(crdutils.BeNamespaced())
 
 					label = labels.NewSelector()
 					r, _ = labels.NewRequirement("app", selection.Equals, []string{"synopsys-operator"})
 					label.Add(*r)
-					_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "alert-and-bd", label, 2, time.Duration(30*time.Second))
+					_, err = podutils.WaitForPodsWithLabelRunningReady(f.KubeClient, alertAndBdNs.Name, label, 2, config.Get().Timeouts.PodReady.Duration)
 					if err != nil {
 						Fail(fmt.Sprintf("Operator pods failed to come up: %v", err))
 					}
 					// END VERIFICATION
-
-					// BEGIN CLEANUP
-					cleanErrs := []error{}
-					err = kc.CoreV1().Namespaces().Delete("alert", &metav1.DeleteOptions{})
-					cleanErrs = append(cleanErrs, err)
-					err = apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("alerts.synopsys.com", &metav1.DeleteOptions{})
-					cleanErrs = append(cleanErrs, err)
-					err = kc.CoreV1().Namespaces().Delete("bd", &metav1.DeleteOptions{})
-					cleanErrs = append(cleanErrs, err)
-					err = apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("blackducks.synopsys.com", &metav1.DeleteOptions{})
-					cleanErrs = append(cleanErrs, err)
-					err = kc.CoreV1().Namespaces().Delete("alert-and-bd", &metav1.DeleteOptions{})
-					cleanErrs = append(cleanErrs, err)
-					err = kc.RbacV1().ClusterRoles().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-					cleanErrs = append(cleanErrs, err)
-					err = kc.RbacV1().ClusterRoleBindings().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-					cleanErrs = append(cleanErrs, err)
-					err = namespaceutils.WaitForNamespacesDeleted(kc, []string{"alert", "bd", "alert-and-bd"}, time.Duration(30*time.Second))
-					cleanErrs = append(cleanErrs, err)
-					// if len(cleanErrs) != 0 {
-					// 	Fail(fmt.Sprintf("%+v", cleanErrs))
-					// }
-					// END CLEANUP
 				})
 				// create three namespaces
 				// execute three deploy commands
@@ -254,219 +251,200 @@ var _ = Describe("synopsysctl", func() {
 		Context("destroying Synopsys Operator in cluster scope", func() {
 			Specify("all resources are removed", func() {
 				// BEGIN SETUP
-				out, err := mySynopsysCtl.Exec("deploy", "--cluster-scoped", "--enable-alert", "--enable-blackduck", "--enable-opssight", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
+				result, err := f.DeployOperator(utils.DeployOptions{
+					ClusterScoped:    true,
+					EnabledResources: []utils.Resource{utils.AlertResource, utils.BlackDuckResource, utils.OpsSightResource},
+					OperatorImage:    config.Get().OperatorImage,
+				})
 				if err != nil {
-					Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+					Fail(fmt.Sprintf("%v\n%s", err, result))
 				}
 				// END SETUP
 
 				// BEGIN VERIFICATION
-				out, err = mySynopsysCtl.Exec("destroy")
+				result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.DestroyOptions{})
 				if err != nil {
-					Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+					Fail(fmt.Sprintf("%v\n%s", err, result))
 				}
 				label := labels.NewSelector()
 				r, _ := labels.NewRequirement("app", selection.Equals, []string{"synopsys-operator"})
 				label.Add(*r)
-				_, err = podutils.WaitForPodsWithLabelDeleted(kc, "synopsys-operator", label)
+				_, err = podutils.WaitForPodsWithLabelDeleted(f.KubeClient, config.Get().Namespaces.Operator, label)
 				if err != nil {
 					Fail(fmt.Sprintf("Synopsys Operator pods failed to stop running: %v", err))
 				}
 				// TODO : check that CRDs are removed
 				// END VERIFICATION
-
-				// BEGIN CLEANUP
-				kc.CoreV1().Namespaces().Delete("synopsys-operator", &metav1.DeleteOptions{})
-				kc.RbacV1().ClusterRoles().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-				kc.RbacV1().ClusterRoleBindings().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("alerts.synopsys.com", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("blackducks.synopsys.com", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("opssights.synopsys.com", &metav1.DeleteOptions{})
-				//TODO:WAIT FOR NAMESPACE AND CRD TO BE DELETED
-				namespaceutils.WaitForNamespacesDeleted(kc, []string{"synopsys-operator"}, time.Duration(30*time.Second))
-				// END CLEANUP
 			})
 		})
 		Context("destroying Synopsys Operator in namespace scope", func() {
 			Specify("one instance can be destroyed", func() {
 				// BEGIN SETUP
-				kc.CoreV1().Namespaces().Create(&corev1.Namespace{
-					ObjectMeta: metav1.ObjectMeta{
-						Namespace: "so-one",
-						Name:      "so-one",
-					},
+				ns, err := f.CreateNamespace("so")
+				if err != nil {
+					Fail(err.Error())
+				}
+				result, err := f.DeployOperator(utils.DeployOptions{
+					Namespace:        ns.Name,
+					EnabledResources: []utils.Resource{utils.AlertResource, utils.BlackDuckResource},
+					OperatorImage:    config.Get().OperatorImage,
 				})
-				out, err := mySynopsysCtl.Exec("deploy", "--enable-alert", "--enable-blackduck", "--namespace=so-one", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
 				if err != nil {
-					Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+					Fail(fmt.Sprintf("%v\n%s", err, result))
 				}
 				// END SETUP
 
 				// BEGIN VERIFICATION
-				out, err = mySynopsysCtl.Exec("destroy", "so-one")
+				result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.DestroyOptions{
+					Namespaces: []string{ns.Name},
+				})
 				if err != nil {
-					Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+					Fail(fmt.Sprintf("%v\n%s", err, result))
 				}
 				label := labels.NewSelector()
 				r, _ := labels.NewRequirement("app", selection.Equals, []string{"synopsys-operator"})
 				label.Add(*r)
-				_, err = podutils.WaitForPodsWithLabelDeleted(kc, "so-one", label)
+				_, err = podutils.WaitForPodsWithLabelDeleted(f.KubeClient, ns.Name, label)
 				if err != nil {
 					Fail(fmt.Sprintf("Synopsys Operator pods failed to stop running: %v", err))
 				}
 				// TODO : check that CRDs are removed
 				// END VERIFICATION
-
-				// BEGIN CLEANUP
-				kc.CoreV1().Namespaces().Delete("so-one", &metav1.DeleteOptions{})
-				kc.RbacV1().ClusterRoles().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-				kc.RbacV1().ClusterRoleBindings().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("alerts.synopsys.com", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("blackducks.synopsys.com", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("opssights.synopsys.com", &metav1.DeleteOptions{})
-				//TODO:WAIT FOR NAMESPACE AND CRD TO BE DELETED
-				namespaceutils.WaitForNamespacesDeleted(kc, []string{"so-one"}, time.Duration(30*time.Second))
-				// END CLEANUP
 			})
 			// Specify("multiple instances can be destroyed at once", func() {
 			// 	// BEGIN SETUP
-			// 	kc.CoreV1().Namespaces().Create(&corev1.Namespace{
-			// 		ObjectMeta: metav1.ObjectMeta{
-			// 			Namespace: "so-one",
-			// 			Name:      "so-one",
-			// 		},
+			// 	soOne, err := f.CreateNamespace("so-one")
+			// 	if err != nil {
+			// 		Fail(err.Error())
+			// 	}
+			// 	result, err := f.DeployOperator(utils.DeployOptions{
+			// 		Namespace:        soOne.Name,
+			// 		EnabledResources: []utils.Resource{utils.AlertResource, utils.BlackDuckResource},
+			// 		OperatorImage:    config.Get().OperatorImage,
 			// 	})
-			// 	out, err := mySynopsysCtl.Exec("deploy", "--enable-alert", "--enable-blackduck", "--namespace=so-one", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
 			// 	if err != nil {
-			// 		Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+			// 		Fail(fmt.Sprintf("%v\n%s", err, result))
+			// 	}
+			// 	soTwo, err := f.CreateNamespace("so-two")
+			// 	if err != nil {
+			// 		Fail(err.Error())
 			// 	}
-			// 	kc.CoreV1().Namespaces().Create(&corev1.Namespace{
-			// 		ObjectMeta: metav1.ObjectMeta{
-			// 			Namespace: "so-two",
-			// 			Name:      "so-two",
-			// 		},
+			// 	result, err = f.DeployOperator(utils.DeployOptions{
+			// 		Namespace:        soTwo.Name,
+			// 		EnabledResources: []utils.Resource{utils.BlackDuckResource, utils.AlertResource},
+			// 		OperatorImage:    config.Get().OperatorImage,
 			// 	})
-			// 	out, err = mySynopsysCtl.Exec("deploy", "--enable-blackduck", "--enable-alert", "--namespace=so-two", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
 			// 	if err != nil {
-			// 		Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+			// 		Fail(fmt.Sprintf("%v\n%s", err, result))
 			// 	}
 			// 	soLabel := labels.NewSelector()
 			// 	r, _ := labels.NewRequirement("app", selection.Equals, []string{"synopsys-operator"})
 			// 	soLabel.Add(*r)
-			// 	_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "so-one", soLabel, 2, time.Duration(10*time.Second))
+			// 	_, err = podutils.WaitForPodsWithLabelRunningReady(f.KubeClient, soOne.Name, soLabel, 2, config.Get().Timeouts.PodReady.Duration)
 			// 	if err != nil {
 			// 		Fail(fmt.Sprintf("Synopsys Operator pods failed to come up: %v", err))
 			// 	}
-			// 	_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "so-two", soLabel, 2, time.Duration(10*time.Second))
+			// 	_, err = podutils.WaitForPodsWithLabelRunningReady(f.KubeClient, soTwo.Name, soLabel, 2, config.Get().Timeouts.PodReady.Duration)
 			// 	if err != nil {
 			// 		Fail(fmt.Sprintf("Synopsys Operator pods failed to come up: %v", err))
 			// 	}
-			// 	err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "alerts.synopsys.com", 10)
+			// 	err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "alerts.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 			// 	if err != nil {
 			// 		Fail(fmt.Sprintf("Alert CRD was not added: %v", err))
 			// 	}
-			// 	err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "blackducks.synopsys.com", 10)
+			// 	err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "blackducks.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 			// 	if err != nil {
 			// 		Fail(fmt.Sprintf("Black Duck CRD was not added: %v", err))
 			// 	}
 			// 	// END SETUP
 
 			// 	// BEGIN VERIFICATION
-			// 	out, err = mySynopsysCtl.Exec("destroy", "so-one", "so-two")
+			// 	result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.DestroyOptions{
+			// 		Namespaces: []string{soOne.Name, soTwo.Name},
+			// 	})
 			// 	if err != nil {
-			// 		Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+			// 		Fail(fmt.Sprintf("%v\n%s", err, result))
 			// 	}
-			// 	_, err = podutils.WaitForPodsWithLabelDeleted(kc, "so-one", soLabel)
+			// 	_, err = podutils.WaitForPodsWithLabelDeleted(f.KubeClient, soOne.Name, soLabel)
 			// 	if err != nil {
 			// 		Fail(fmt.Sprintf("Synopsys Operator pods failed to stop running: %v", err))
 			// 	}
 			// 	// TODO : check that CRDs are removed
-			// 	_, err = podutils.WaitForPodsWithLabelDeleted(kc, "so-two", soLabel)
+			// 	_, err = podutils.WaitForPodsWithLabelDeleted(f.KubeClient, soTwo.Name, soLabel)
 			// 	if err != nil {
 			// 		Fail(fmt.Sprintf("Synopsys Operator pods failed to stop running: %v", err))
 			// 	}
 			// 	// TODO : check that CRDs are removed
 			// 	// END VERIFICATION
-
-			// 	// BEGIN CLEANUP
-			// 	kc.CoreV1().Namespaces().Delete("so-one", &metav1.DeleteOptions{})
-			// 	kc.CoreV1().Namespaces().Delete("so-two", &metav1.DeleteOptions{})
-			// 	kc.RbacV1().ClusterRoles().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-			// 	kc.RbacV1().ClusterRoleBindings().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-			// 	apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("alerts.synopsys.com", &metav1.DeleteOptions{})
-			// 	apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("blackducks.synopsys.com", &metav1.DeleteOptions{})
-			// 	apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("opssights.synopsys.com", &metav1.DeleteOptions{})
-			// 	//TODO:WAIT FOR NAMESPACE AND CRD TO BE DELETED
-			// 	namespaceutils.WaitForNamespacesDeleted(kc, []string{"so-one", "so-two"}, time.Duration(30*time.Second))
-			// 	// END CLEANUP
 			// })
 			Specify("a Synopsys Operator instance can be forcefully destroyed", func() {
 				// BEGIN SETUP
 				// create the namespace
-				kc.CoreV1().Namespaces().Create(&corev1.Namespace{
-					ObjectMeta: metav1.ObjectMeta{
-						Namespace: "so-one",
-						Name:      "so-one",
-					},
-				})
+				ns, err := f.CreateNamespace("so")
+				if err != nil {
+					Fail(err.Error())
+				}
 				// deploy a Synopsys Operator instance
-				out, err := mySynopsysCtl.Exec("deploy", "--enable-alert", "--namespace=so-one", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
+				result, err := f.DeployOperator(utils.DeployOptions{
+					Namespace:        ns.Name,
+					EnabledResources: []utils.Resource{utils.AlertResource},
+					OperatorImage:    config.Get().OperatorImage,
+				})
 				if err != nil {
-					Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+					Fail(fmt.Sprintf("%v\n%s", err, result))
 				}
 				soLabel := labels.NewSelector()
 				r, _ := labels.NewRequirement("app", selection.Equals, []string{"synopsys-operator"})
 				soLabel.Add(*r)
-				_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "so-one", soLabel, 2, time.Duration(10*time.Second))
+				_, err = podutils.WaitForPodsWithLabelRunningReady(f.KubeClient, ns.Name, soLabel, 2, config.Get().Timeouts.PodReady.Duration)
 				if err != nil {
 					Fail(fmt.Sprintf("Synopsys Operator pods failed to come up: %v", err))
 				}
-				err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "alerts.synopsys.com", 10)
+				err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "alerts.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 				if err != nil {
 					Fail(fmt.Sprintf("alert crd was not added: %v", err))
 				}
 				// create an Alert instance
-				out, err = mySynopsysCtl.Exec("create", "alert", "alt-one", "--namespace=so-one", "--standalone=false", "--persistent-storage=false")
+				f.DeleteCROnCleanup(crutils.GetAlertSchema(), ns.Name, "alt-one")
+				result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.CreateAlertOptions{
+					Name:              "alt-one",
+					Namespace:         ns.Name,
+					Standalone:        utils.BoolPtr(false),
+					PersistentStorage: utils.BoolPtr(false),
+				})
 				if err != nil {
-					Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+					Fail(fmt.Sprintf("%v\n%s", err, result))
 				}
 				altLabel := labels.NewSelector()
 				r, _ = labels.NewRequirement("app", selection.Equals, []string{"alert"})
 				altLabel.Add(*r)
-				_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "so-one", altLabel, 1, time.Duration(10*time.Second))
+				_, err = podutils.WaitForPodsWithLabelRunningReady(f.KubeClient, ns.Name, altLabel, 1, config.Get().Timeouts.PodReady.Duration)
 				if err != nil {
 					Fail(fmt.Sprintf("Synopsys Operator pods failed to come up: %v", err))
 				}
 				// END SETUP
 
 				// BEGIN VERIFICATION
-				out, err = mySynopsysCtl.Exec("destroy", "so-one")
+				result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.DestroyOptions{
+					Namespaces: []string{ns.Name},
+				})
 				if err != nil {
-					Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+					Fail(fmt.Sprintf("%v\n%s", err, result))
 				}
 				// TODO : Check that instance isn't destroyed
-				out, err = mySynopsysCtl.Exec("destroy", "so-one", "--force")
+				result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.DestroyOptions{
+					Namespaces: []string{ns.Name},
+					Force:      true,
+				})
 				if err != nil {
-					Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+					Fail(fmt.Sprintf("%v\n%s", err, result))
 				}
-				_, err = podutils.WaitForPodsWithLabelDeleted(kc, "so-one", soLabel)
+				_, err = podutils.WaitForPodsWithLabelDeleted(f.KubeClient, ns.Name, soLabel)
 				if err != nil {
 					Fail(fmt.Sprintf("Synopsys Operator pods failed to stop running: %v", err))
 				}
 				// TODO : check that CRDs are removed
 				// END VERIFICATION
-
-				// BEGIN CLEANUP
-				kc.CoreV1().Namespaces().Delete("so-one", &metav1.DeleteOptions{})
-				kc.RbacV1().ClusterRoles().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-				kc.RbacV1().ClusterRoleBindings().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("alerts.synopsys.com", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("blackducks.synopsys.com", &metav1.DeleteOptions{})
-				apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("opssights.synopsys.com", &metav1.DeleteOptions{})
-				// TODO : wait for namesapce and crd to be deleted
-				// TODO : clean up the Alert instance
-				namespaceutils.WaitForNamespacesDeleted(kc, []string{"so-one"}, time.Duration(30*time.Second))
-				// END CLEANUP
 			})
 		})
 	})
@@ -487,52 +465,66 @@ var _ = Describe("synopsysctl", func() {
 				// defer -> cleanup
 				Specify("the CR appears", func() {
 					// BEGIN SETUP
-					out, err := mySynopsysCtl.Exec("deploy", "--cluster-scoped", "--enable-alert", "-i=gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
+					result, err := f.DeployOperator(utils.DeployOptions{
+						ClusterScoped:    true,
+						EnabledResources: []utils.Resource{utils.AlertResource},
+						OperatorImage:    config.Get().OperatorImage,
+					})
 					if err != nil {
-						Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+						Fail(fmt.Sprintf("%v\n%s", err, result))
 					}
 					label := labels.NewSelector()
 					r, _ := labels.NewRequirement("app", selection.Equals, []string{"synopsys-operator"})
 					label.Add(*r)
-					_, err = podutils.WaitForPodsWithLabelRunningReady(kc, "synopsys-operator", label, 2, time.Duration(10*time.Second))
+					_, err = podutils.WaitForPodsWithLabelRunningReady(f.KubeClient, config.Get().Namespaces.Operator, label, 2, config.Get().Timeouts.PodReady.Duration)
 					if err != nil {
 						Fail(fmt.Sprintf("Operator pods failed to come up: %v", err))
 					}
-					err = crdutils.BlockUntilCrdIsAdded(apiExtensionClient, "alerts.synopsys.com", 10)
+					err = crdutils.BlockUntilCrdIsAdded(f.APIExtensionClient, "alerts.synopsys.com", int(config.Get().Timeouts.CRDAdded.Seconds()))
 					if err != nil {
 						Fail(fmt.Sprintf("alert crd was not added: %v", err))
 					}
 					// END SETUP
 
 					// BEGIN VERIFICATION
-					out, err = mySynopsysCtl.Exec("create", "alert", "alt-one", "--standalone=false", "--persistent-storage=false")
+					// synopsysctl creates the alert in a namespace of the same name
+					alertName := f.UniqueName("alt")
+					f.DeleteNamespaceOnCleanup(alertName)
+					result, err = f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, utils.CreateAlertOptions{
+						Name:              alertName,
+						Standalone:        utils.BoolPtr(false),
+						PersistentStorage: utils.BoolPtr(false),
+					})
 					if err != nil {
-						Fail(fmt.Sprintf("Out: %s Error: %v", out, err))
+						Fail(fmt.Sprintf("%v\n%s", err, result))
 					}
-					good, err := crutils.AlertCRExists(kc.RESTClient(), "alt-one", "alt-one")
+					// the alert crd of a cluster scoped operator is cluster scoped too
+					shape, err := crdutils.GetShape(f.APIExtensionClient, crdutils.AlertCRDName)
 					if err != nil {
-						Fail(fmt.Sprintf("bad get : %s", err))
+						Fail(fmt.Sprintf("failed to get the alert crd: %v", err))
 					}
-					if !good {
-						Fail(fmt.Sprintf("result : %v", good))
+					err = crutils.WaitForAlertCR(f.KubeClient.RESTClient(), shape.Namespace(alertName), alertName, true, 0, 0)
+					if err != nil {
+						Fail(fmt.Sprintf("alert %s did not appear: %v", alertName, err))
 					}
 					// END VERIFICATION
-
-					// BEGIN CLEANUP
-					kc.CoreV1().Namespaces().Delete("so-one", &metav1.DeleteOptions{})
-					kc.CoreV1().Namespaces().Delete("synopsys-operator", &metav1.DeleteOptions{})
-					kc.RbacV1().ClusterRoles().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-					kc.RbacV1().ClusterRoleBindings().Delete("synopsys-operator-admin", &metav1.DeleteOptions{})
-					apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("alerts.synopsys.com", &metav1.DeleteOptions{})
-					namespaceutils.WaitForNamespacesDeleted(kc, []string{"alt-one", "synopsys-operator"}, time.Duration(30*time.Second))
-					// END CLEANUP
 				})
 			})
 			Context("in namespaced scope", func() {
 				Specify("the CR appears", func() {})
 			})
 			Context("Native", func() {
-				Specify("resources can be deployed", func() {})
+				Specify("resources can be deployed", func() {
+					m := createNative(f.Synopsysctl, utils.CreateAlertOptions{
+						Name:              "alt-native",
+						PersistentStorage: utils.BoolPtr(true),
+					})
+					Expect(m.ReplicationControllers()).NotTo(BeEmpty())
+					Expect(m.Services()).NotTo(BeEmpty())
+					Expect(m.PersistentVolumeClaims()).NotTo(BeEmpty())
+					expectGolden(f.Synopsysctl, utils.AlertResource, m)
+					applyNative(f, m)
+				})
 			})
 		})
 		Context("creating Black Duck", func() {
@@ -543,7 +535,20 @@ var _ = Describe("synopsysctl", func() {
 				Specify("the CR appears", func() {})
 			})
 			Context("Native", func() {
-				Specify("resources can be deployed", func() {})
+				Specify("resources can be deployed", func() {
+					m := createNative(f.Synopsysctl, utils.CreateBlackDuckOptions{
+						Name:              "bd-native",
+						AdminPassword:     "blackduck",
+						PostgresPassword:  "blackduck",
+						UserPassword:      "blackduck",
+						PersistentStorage: utils.BoolPtr(false),
+					})
+					Expect(m.ReplicationControllers()).NotTo(BeEmpty())
+					Expect(m.Secrets()).NotTo(BeEmpty())
+					Expect(m.PersistentVolumeClaims()).To(BeEmpty())
+					expectGolden(f.Synopsysctl, utils.BlackDuckResource, m)
+					applyNative(f, m)
+				})
 			})
 		})
 		Context("creating OpsSight", func() {
@@ -557,7 +562,12 @@ var _ = Describe("synopsysctl", func() {
 				Specify("the CR appears", func() {})
 			})
 			Context("Native", func() {
-				Specify("resources can be deployed", func() {})
+				Specify("resources can be deployed", func() {
+					m := createNative(f.Synopsysctl, utils.CreateOpsSightOptions{Name: "ops-native"})
+					Expect(m.ReplicationControllers()).NotTo(BeEmpty())
+					expectGolden(f.Synopsysctl, utils.OpsSightResource, m)
+					applyNative(f, m)
+				})
 			})
 			defer func() {
 				fmt.Printf("CLEANING\n")
@@ -566,3 +576,53 @@ var _ = Describe("synopsysctl", func() {
 	})
 
 })
+
+// createNative runs "synopsysctl create <resource> native" for create and decodes its output
+func createNative(sCtl *utils.Synopsysctl, create utils.Command) *native.Manifest {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Command.Duration)
+	defer cancel()
+	m, result, err := sCtl.CreateNative(ctx, utils.CreateNativeOptions{Create: create})
+	if err != nil {
+		Fail(fmt.Sprintf("%v\n%s", err, result))
+	}
+	return m
+}
+
+// goldenDir holds the golden native manifests, per synopsysctl release line and resource
+const goldenDir = "testdata/golden"
+
+// expectGolden checks that m has the objects, images and container limits of the golden file of
+// resource for the release line of sCtl, which were printed by a real synopsysctl of that line, and
+// then compares the whole manifest with it. A release line without golden files fails; run with
+// -cnt.update-golden against a real synopsysctl to add them
+func expectGolden(sCtl *utils.Synopsysctl, resource utils.Resource, m *native.Manifest) {
+	version, err := sCtl.Version(context.Background())
+	if err != nil {
+		Fail(fmt.Sprintf("failed to get the synopsysctl version: %v", err))
+	}
+	path := native.GoldenPath(goldenDir, version.ReleaseLine().String(), string(resource))
+	if !native.UpdateGolden() {
+		golden, err := native.ReadGolden(path)
+		if os.IsNotExist(err) {
+			Fail(fmt.Sprintf("golden file %s does not exist, run with -cnt.update-golden against a real synopsysctl to create it", path))
+		} else if err != nil {
+			Fail(err.Error())
+		}
+		Expect(m).To(native.HaveObjectsOf(golden))
+	}
+	Expect(m).To(native.MatchGolden(path))
+}
+
+// applyNative creates the objects of m in a new namespace if -cnt.apply-native is set
+func applyNative(f *framework.Framework, m *native.Manifest) {
+	if !config.Get().ApplyNative {
+		return
+	}
+	ns, err := f.CreateNamespace("native")
+	if err != nil {
+		Fail(err.Error())
+	}
+	if err := native.Apply(f.RestConfig, ns.Name, m); err != nil {
+		Fail(fmt.Sprintf("failed to apply the native objects:\n%s%v", m.Summary(), err))
+	}
+}
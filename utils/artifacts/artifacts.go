@@ -0,0 +1,268 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package artifacts dumps the state of the namespaces a failed spec touched, so the failure can be
+// diagnosed after the cluster is cleaned up
+package artifacts
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	crutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/cr"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// Collector writes the artifacts of a spec with the k8shelper clients
+type Collector struct {
+	KubeClient         kubernetes.Interface
+	DynamicClient      dynamic.Interface
+	APIExtensionClient apiextensionsclient.Interface
+	// Logs reads container logs; nil reads them through KubeClient
+	Logs func(namespace, pod string, options *corev1.PodLogOptions) ([]byte, error)
+}
+
+// crGroup is the API group of the Synopsys custom resources
+const crGroup = "synopsys.com"
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SpecDir returns the artifacts directory of the spec with the full text specText under dir
+func SpecDir(dir, specText string) string {
+	name := strings.Trim(unsafeChars.ReplaceAllString(specText, "_"), "_")
+	if len(name) > 150 {
+		name = name[:150]
+	}
+	return filepath.Join(dir, name)
+}
+
+// Collect writes into dir the synopsys CRDs, the transcript at transcriptPath (if any) and, for every
+// namespace, its pods, container logs, events and Alert, Black Duck and OpsSight CRs. It keeps going
+// after a failure and returns every failure at the end
+func (c *Collector) Collect(dir string, namespaces []string, transcriptPath string) error {
+	errs := []string{}
+	record := func(err error) {
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	record(c.dumpCRDs(dir))
+	if transcriptPath != "" {
+		record(copyFile(transcriptPath, filepath.Join(dir, "transcript.jsonl")))
+	}
+	seen := map[string]bool{}
+	for _, ns := range namespaces {
+		if seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		nsDir := filepath.Join(dir, ns)
+		if err := os.MkdirAll(filepath.Join(nsDir, "logs"), 0755); err != nil {
+			record(err)
+			continue
+		}
+		record(c.dumpPods(ns, nsDir))
+		record(c.dumpEvents(ns, nsDir))
+		for _, gvr := range []schema.GroupVersionResource{crutils.GetAlertSchema(), crutils.GetBlackDuckSchema(), crutils.GetOpssightSchema()} {
+			record(c.dumpCRs(gvr, ns, nsDir))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to collect some artifacts into %s:\n%s", dir, strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func writeYAML(path string, obj interface{}) error {
+	b, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+func copyFile(src, dst string) error {
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, b, 0644)
+}
+
+func (c *Collector) dumpCRDs(dir string) error {
+	crds, err := c.APIExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list crds: %v", err)
+	}
+	items := crds.Items[:0]
+	for _, crd := range crds.Items {
+		if crd.Spec.Group == crGroup {
+			items = append(items, crd)
+		}
+	}
+	crds.Items = items
+	return writeYAML(filepath.Join(dir, "crds.yaml"), crds)
+}
+
+func (c *Collector) dumpPods(ns, dir string) error {
+	pods, err := c.KubeClient.CoreV1().Pods(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pods in %s: %v", ns, err)
+	}
+	if err := writeYAML(filepath.Join(dir, "pods.yaml"), pods); err != nil {
+		return err
+	}
+	describe := &bytes.Buffer{}
+	errs := []string{}
+	for _, pod := range pods.Items {
+		describePod(describe, &pod)
+		containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, container := range containers {
+			if err := c.dumpLogs(&pod, container.Name, false, dir); err != nil {
+				errs = append(errs, err.Error())
+			}
+			if restarted(&pod, container.Name) {
+				if err := c.dumpLogs(&pod, container.Name, true, dir); err != nil {
+					errs = append(errs, err.Error())
+				}
+			}
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "pods.txt"), describe.Bytes(), 0644); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// restarted returns true if container has a previous instance whose logs can be read
+func restarted(pod *corev1.Pod, container string) bool {
+	for _, s := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if s.Name == container {
+			return s.RestartCount > 0
+		}
+	}
+	return false
+}
+
+func (c *Collector) dumpLogs(pod *corev1.Pod, container string, previous bool, dir string) error {
+	name := fmt.Sprintf("%s-%s.log", pod.Name, container)
+	if previous {
+		name = fmt.Sprintf("%s-%s.previous.log", pod.Name, container)
+	}
+	logs := c.Logs
+	if logs == nil {
+		logs = func(namespace, pod string, options *corev1.PodLogOptions) ([]byte, error) {
+			return c.KubeClient.CoreV1().Pods(namespace).GetLogs(pod, options).DoRaw()
+		}
+	}
+	b, err := logs(pod.Namespace, pod.Name, &corev1.PodLogOptions{Container: container, Previous: previous})
+	if err != nil {
+		// a container that never started has no logs; record why instead
+		b = []byte(fmt.Sprintf("failed to get logs: %v\n", err))
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "logs", name), b, 0644)
+}
+
+// describePod writes a summary of pod like "kubectl describe pod" to buf
+func describePod(buf *bytes.Buffer, pod *corev1.Pod) {
+	fmt.Fprintf(buf, "Name:      %s\n", pod.Name)
+	fmt.Fprintf(buf, "Node:      %s\n", pod.Spec.NodeName)
+	fmt.Fprintf(buf, "Phase:     %s\n", pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		fmt.Fprintf(buf, "Reason:    %s: %s\n", pod.Status.Reason, pod.Status.Message)
+	}
+	fmt.Fprintf(buf, "Conditions:\n")
+	for _, cond := range pod.Status.Conditions {
+		fmt.Fprintf(buf, "  %s=%s %s %s\n", cond.Type, cond.Status, cond.Reason, cond.Message)
+	}
+	fmt.Fprintf(buf, "Containers:\n")
+	for _, s := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		fmt.Fprintf(buf, "  %s (%s): ready=%t restarts=%d state=%s", s.Name, s.Image, s.Ready, s.RestartCount, containerState(s.State))
+		if s.LastTerminationState.Terminated != nil {
+			fmt.Fprintf(buf, " last=%s", containerState(s.LastTerminationState))
+		}
+		fmt.Fprintf(buf, "\n")
+	}
+	fmt.Fprintf(buf, "\n")
+}
+
+func containerState(state corev1.ContainerState) string {
+	switch {
+	case state.Waiting != nil:
+		return fmt.Sprintf("Waiting(%s: %s)", state.Waiting.Reason, state.Waiting.Message)
+	case state.Running != nil:
+		return fmt.Sprintf("Running(since %s)", state.Running.StartedAt.Format(time.RFC3339))
+	case state.Terminated != nil:
+		return fmt.Sprintf("Terminated(%s, exit code %d: %s)", state.Terminated.Reason, state.Terminated.ExitCode, state.Terminated.Message)
+	}
+	return "Unknown"
+}
+
+func (c *Collector) dumpEvents(ns, dir string) error {
+	events, err := c.KubeClient.CoreV1().Events(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list events in %s: %v", ns, err)
+	}
+	sort.SliceStable(events.Items, func(i, j int) bool {
+		return events.Items[i].LastTimestamp.Before(&events.Items[j].LastTimestamp)
+	})
+	buf := &bytes.Buffer{}
+	for _, e := range events.Items {
+		fmt.Fprintf(buf, "%s\t%s\t%s/%s\t%s\t%s (x%d)\n", e.LastTimestamp.Format(time.RFC3339), e.Type, e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Reason, e.Message, e.Count)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "events.txt"), buf.Bytes(), 0644)
+}
+
+func (c *Collector) dumpCRs(gvr schema.GroupVersionResource, ns, dir string) error {
+	items, err := crutils.ListInNamespace(c.DynamicClient, gvr, ns)
+	if apierrs.IsNotFound(err) {
+		// the crd is not installed
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to list %s in %s: %v", gvr.Resource, ns, err)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"}, Items: items}
+	return writeYAML(filepath.Join(dir, gvr.Resource+".yaml"), list.UnstructuredContent())
+}
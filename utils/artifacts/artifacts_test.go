@@ -0,0 +1,100 @@
+package artifacts
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCollect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	transcript := filepath.Join(dir, "in.jsonl")
+	if err := ioutil.WriteFile(transcript, []byte(`{"args":["synopsysctl","deploy"]}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	kc := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "synopsys-operator-x", Namespace: "so-one"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "synopsys-operator"}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "synopsys-operator",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+				}},
+			},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "synopsys-operator-x.1", Namespace: "so-one"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "synopsys-operator-x"},
+			Reason:         "Failed",
+			Message:        "Back-off pulling image",
+		},
+	)
+	alert := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "synopsys.com/v1",
+		"kind":       "Alert",
+		"metadata":   map[string]interface{}{"name": "alt-one", "namespace": "so-one"},
+	}}
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), alert)
+	aec := apiextensionsfake.NewSimpleClientset(
+		&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "alerts.synopsys.com"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Group: "synopsys.com"}},
+		&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.com"}, Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{Group: "example.com"}},
+	)
+
+	logs := func(namespace, pod string, options *corev1.PodLogOptions) ([]byte, error) {
+		return []byte("starting " + options.Container), nil
+	}
+	c := &Collector{KubeClient: kc, DynamicClient: dc, APIExtensionClient: aec, Logs: logs}
+	specDir := SpecDir(dir, "synopsysctl deploy command all crds can be enabled")
+	if err := c.Collect(specDir, []string{"so-one", "so-one"}, transcript); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"crds.yaml":          "alerts.synopsys.com",
+		"transcript.jsonl":   "deploy",
+		"so-one/pods.yaml":   "synopsys-operator-x",
+		"so-one/pods.txt":    "ImagePullBackOff",
+		"so-one/events.txt":  "Back-off pulling image",
+		"so-one/alerts.yaml": "alt-one",
+		"so-one/logs/synopsys-operator-x-synopsys-operator.log": "starting synopsys-operator",
+	}
+	for file, content := range expected {
+		b, err := ioutil.ReadFile(filepath.Join(specDir, file))
+		if err != nil {
+			t.Errorf("%s was not written: %v", file, err)
+			continue
+		}
+		if !strings.Contains(string(b), content) {
+			t.Errorf("%s does not contain %q:\n%s", file, content, b)
+		}
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(specDir, "crds.yaml")); strings.Contains(string(b), "widgets") {
+		t.Errorf("crds of other groups were collected")
+	}
+}
+
+func TestSpecDir(t *testing.T) {
+	got := SpecDir("_artifacts", "synopsysctl --version command the version is in the format 'synopsysctl version YEAR.MONTH.PATCH'")
+	want := filepath.Join("_artifacts", "synopsysctl_--version_command_the_version_is_in_the_format_synopsysctl_version_YEAR.MONTH.PATCH")
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
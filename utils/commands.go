@@ -0,0 +1,332 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Resource is a custom resource type that synopsysctl can manage
+type Resource string
+
+const (
+	// AlertResource is the synopsysctl name of the Alert custom resource
+	AlertResource Resource = "alert"
+	// BlackDuckResource is the synopsysctl name of the Black Duck custom resource
+	BlackDuckResource Resource = "blackduck"
+	// OpsSightResource is the synopsysctl name of the OpsSight custom resource
+	OpsSightResource Resource = "opssight"
+)
+
+// Command is a synopsysctl command that can render its own arguments
+type Command interface {
+	// Args returns everything after "synopsysctl", or an error if the options are not valid together
+	Args() ([]string, error)
+}
+
+// BoolPtr takes a bool and returns a *bool
+func BoolPtr(b bool) *bool {
+	return &b
+}
+
+func validResource(r Resource) bool {
+	switch r {
+	case AlertResource, BlackDuckResource, OpsSightResource:
+		return true
+	}
+	return false
+}
+
+func appendString(args []string, flag, value string) []string {
+	if value == "" {
+		return args
+	}
+	return append(args, fmt.Sprintf("--%s=%s", flag, value))
+}
+
+func appendBool(args []string, flag string, value *bool) []string {
+	if value == nil {
+		return args
+	}
+	return append(args, fmt.Sprintf("--%s=%s", flag, strconv.FormatBool(*value)))
+}
+
+// DeployOptions renders "synopsysctl deploy"
+type DeployOptions struct {
+	// ClusterScoped deploys the operator with cluster wide permissions; it cannot be combined with Namespace
+	ClusterScoped bool
+	// Namespace is the namespace of a namespace scoped operator
+	Namespace        string
+	EnabledResources []Resource
+	OperatorImage    string
+}
+
+// Args returns the arguments of the deploy command
+func (o DeployOptions) Args() ([]string, error) {
+	if o.ClusterScoped && o.Namespace != "" {
+		return nil, fmt.Errorf("--cluster-scoped cannot be used with --namespace %s", o.Namespace)
+	}
+	args := []string{"deploy"}
+	args = appendString(args, "namespace", o.Namespace)
+	if o.ClusterScoped {
+		args = append(args, "--cluster-scoped")
+	}
+	seen := map[Resource]bool{}
+	for _, r := range o.EnabledResources {
+		if !validResource(r) {
+			return nil, fmt.Errorf("unknown resource %q", r)
+		}
+		if seen[r] {
+			return nil, fmt.Errorf("resource %q is enabled more than once", r)
+		}
+		seen[r] = true
+		args = append(args, fmt.Sprintf("--enable-%s", r))
+	}
+	if o.OperatorImage != "" {
+		args = append(args, fmt.Sprintf("-i=%s", o.OperatorImage))
+	}
+	return args, nil
+}
+
+// CreateAlertOptions renders "synopsysctl create alert"
+type CreateAlertOptions struct {
+	Name              string
+	Namespace         string
+	Standalone        *bool
+	PersistentStorage *bool
+	ExposeUI          string
+}
+
+// Args returns the arguments of the create alert command
+func (o CreateAlertOptions) Args() ([]string, error) {
+	if o.Name == "" {
+		return nil, fmt.Errorf("alert name is required")
+	}
+	args := []string{"create", string(AlertResource), o.Name}
+	args = appendString(args, "namespace", o.Namespace)
+	args = appendBool(args, "standalone", o.Standalone)
+	args = appendBool(args, "persistent-storage", o.PersistentStorage)
+	args = appendString(args, "expose-ui", o.ExposeUI)
+	return args, nil
+}
+
+// CreateBlackDuckOptions renders "synopsysctl create blackduck"
+type CreateBlackDuckOptions struct {
+	Name              string
+	Namespace         string
+	AdminPassword     string
+	PostgresPassword  string
+	UserPassword      string
+	Version           string
+	PersistentStorage *bool
+	ExposeUI          string
+}
+
+// Args returns the arguments of the create blackduck command
+func (o CreateBlackDuckOptions) Args() ([]string, error) {
+	if o.Name == "" {
+		return nil, fmt.Errorf("black duck name is required")
+	}
+	if o.AdminPassword == "" || o.PostgresPassword == "" || o.UserPassword == "" {
+		return nil, fmt.Errorf("black duck %s requires the admin, postgres and user passwords", o.Name)
+	}
+	args := []string{"create", string(BlackDuckResource), o.Name}
+	args = appendString(args, "namespace", o.Namespace)
+	args = appendString(args, "admin-password", o.AdminPassword)
+	args = appendString(args, "postgres-password", o.PostgresPassword)
+	args = appendString(args, "user-password", o.UserPassword)
+	args = appendString(args, "version", o.Version)
+	args = appendBool(args, "persistent-storage", o.PersistentStorage)
+	args = appendString(args, "expose-ui", o.ExposeUI)
+	return args, nil
+}
+
+// CreateOpsSightOptions renders "synopsysctl create opssight"
+type CreateOpsSightOptions struct {
+	Name      string
+	Namespace string
+}
+
+// Args returns the arguments of the create opssight command
+func (o CreateOpsSightOptions) Args() ([]string, error) {
+	if o.Name == "" {
+		return nil, fmt.Errorf("opssight name is required")
+	}
+	args := []string{"create", string(OpsSightResource), o.Name}
+	args = appendString(args, "namespace", o.Namespace)
+	return args, nil
+}
+
+// CreateNativeOptions renders "synopsysctl create <resource> native", which prints the objects of
+// Create instead of creating its custom resource
+type CreateNativeOptions struct {
+	// Create is a CreateAlertOptions, CreateBlackDuckOptions or CreateOpsSightOptions
+	Create Command
+	// Output is the format of the printed objects, "yaml" or "json"; empty uses the synopsysctl default
+	Output string
+}
+
+// Args returns the arguments of the create native command
+func (o CreateNativeOptions) Args() ([]string, error) {
+	if o.Create == nil {
+		return nil, fmt.Errorf("create native requires a create command")
+	}
+	createArgs, err := o.Create.Args()
+	if err != nil {
+		return nil, err
+	}
+	if len(createArgs) < 3 || createArgs[0] != "create" {
+		return nil, fmt.Errorf("create native requires a create command, got %v", createArgs)
+	}
+	switch o.Output {
+	case "", "yaml", "json":
+	default:
+		return nil, fmt.Errorf("unknown native output format %q", o.Output)
+	}
+	args := []string{"create", createArgs[1], "native"}
+	args = append(args, createArgs[2:]...)
+	args = appendString(args, "output", o.Output)
+	return args, nil
+}
+
+// DeleteOptions renders "synopsysctl delete"
+type DeleteOptions struct {
+	Resource  Resource
+	Name      string
+	Namespace string
+}
+
+// Args returns the arguments of the delete command
+func (o DeleteOptions) Args() ([]string, error) {
+	if !validResource(o.Resource) {
+		return nil, fmt.Errorf("unknown resource %q", o.Resource)
+	}
+	if o.Name == "" {
+		return nil, fmt.Errorf("%s name is required", o.Resource)
+	}
+	args := []string{"delete", string(o.Resource), o.Name}
+	args = appendString(args, "namespace", o.Namespace)
+	return args, nil
+}
+
+// DestroyOptions renders "synopsysctl destroy"
+type DestroyOptions struct {
+	// Namespaces of namespace scoped operators to destroy; empty destroys the cluster scoped operator
+	Namespaces []string
+	// Force destroys the operator even if instances of its resources still exist
+	Force bool
+}
+
+// Args returns the arguments of the destroy command
+func (o DestroyOptions) Args() ([]string, error) {
+	args := []string{"destroy"}
+	for _, ns := range o.Namespaces {
+		if ns == "" {
+			return nil, fmt.Errorf("destroy namespaces cannot be empty")
+		}
+		args = append(args, ns)
+	}
+	if o.Force {
+		args = append(args, "--force")
+	}
+	return args, nil
+}
+
+// UpdateOperatorOptions renders "synopsysctl update operator"
+type UpdateOperatorOptions struct {
+	Namespace     string
+	OperatorImage string
+}
+
+// Args returns the arguments of the update operator command
+func (o UpdateOperatorOptions) Args() ([]string, error) {
+	if o.OperatorImage == "" {
+		return nil, fmt.Errorf("update operator requires an operator image")
+	}
+	args := []string{"update", "operator"}
+	args = appendString(args, "namespace", o.Namespace)
+	args = append(args, fmt.Sprintf("-i=%s", o.OperatorImage))
+	return args, nil
+}
+
+// UpdateAlertOptions renders "synopsysctl update alert"
+type UpdateAlertOptions struct {
+	Name              string
+	Namespace         string
+	Standalone        *bool
+	PersistentStorage *bool
+	ExposeUI          string
+}
+
+// Args returns the arguments of the update alert command
+func (o UpdateAlertOptions) Args() ([]string, error) {
+	if o.Name == "" {
+		return nil, fmt.Errorf("alert name is required")
+	}
+	args := []string{"update", string(AlertResource), o.Name}
+	args = appendString(args, "namespace", o.Namespace)
+	args = appendBool(args, "standalone", o.Standalone)
+	args = appendBool(args, "persistent-storage", o.PersistentStorage)
+	args = appendString(args, "expose-ui", o.ExposeUI)
+	return args, nil
+}
+
+// UpdateBlackDuckOptions renders "synopsysctl update blackduck"
+type UpdateBlackDuckOptions struct {
+	Name              string
+	Namespace         string
+	Version           string
+	PersistentStorage *bool
+	ExposeUI          string
+}
+
+// Args returns the arguments of the update blackduck command
+func (o UpdateBlackDuckOptions) Args() ([]string, error) {
+	if o.Name == "" {
+		return nil, fmt.Errorf("black duck name is required")
+	}
+	args := []string{"update", string(BlackDuckResource), o.Name}
+	args = appendString(args, "namespace", o.Namespace)
+	args = appendString(args, "version", o.Version)
+	args = appendBool(args, "persistent-storage", o.PersistentStorage)
+	args = appendString(args, "expose-ui", o.ExposeUI)
+	return args, nil
+}
+
+// RunCommand renders command and runs it. Invalid options are reported without running synopsysctl
+func (sCtl *Synopsysctl) RunCommand(ctx context.Context, command Command) (*ExecResult, error) {
+	args, err := command.Args()
+	if err != nil {
+		return &ExecResult{ExitCode: -1}, fmt.Errorf("invalid synopsysctl command: %v", err)
+	}
+	return sCtl.Run(ctx, args...)
+}
+
+// RunCommandWithTimeout is RunCommand bounded by timeout
+func (sCtl *Synopsysctl) RunCommandWithTimeout(timeout time.Duration, command Command) (*ExecResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return sCtl.RunCommand(ctx, command)
+}
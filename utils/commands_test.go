@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandArgs(t *testing.T) {
+	image := "gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x"
+	tests := []struct {
+		name    string
+		command Command
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "deploy cluster scoped",
+			command: DeployOptions{ClusterScoped: true, EnabledResources: []Resource{AlertResource, BlackDuckResource, OpsSightResource}, OperatorImage: image},
+			want:    []string{"deploy", "--cluster-scoped", "--enable-alert", "--enable-blackduck", "--enable-opssight", "-i=" + image},
+		},
+		{
+			name:    "deploy namespace scoped",
+			command: DeployOptions{Namespace: "so-one", EnabledResources: []Resource{AlertResource}, OperatorImage: image},
+			want:    []string{"deploy", "--namespace=so-one", "--enable-alert", "-i=" + image},
+		},
+		{
+			name:    "deploy cluster scoped with namespace",
+			command: DeployOptions{ClusterScoped: true, Namespace: "so-one"},
+			wantErr: true,
+		},
+		{
+			name:    "deploy unknown resource",
+			command: DeployOptions{EnabledResources: []Resource{"polaris"}},
+			wantErr: true,
+		},
+		{
+			name:    "deploy duplicate resource",
+			command: DeployOptions{EnabledResources: []Resource{AlertResource, AlertResource}},
+			wantErr: true,
+		},
+		{
+			name:    "create alert",
+			command: CreateAlertOptions{Name: "alt-one", Namespace: "so-one", Standalone: BoolPtr(false), PersistentStorage: BoolPtr(false)},
+			want:    []string{"create", "alert", "alt-one", "--namespace=so-one", "--standalone=false", "--persistent-storage=false"},
+		},
+		{
+			name:    "create alert without name",
+			command: CreateAlertOptions{},
+			wantErr: true,
+		},
+		{
+			name:    "create black duck",
+			command: CreateBlackDuckOptions{Name: "bd-one", AdminPassword: "a", PostgresPassword: "p", UserPassword: "u"},
+			want:    []string{"create", "blackduck", "bd-one", "--admin-password=a", "--postgres-password=p", "--user-password=u"},
+		},
+		{
+			name:    "create black duck without passwords",
+			command: CreateBlackDuckOptions{Name: "bd-one", AdminPassword: "a"},
+			wantErr: true,
+		},
+		{
+			name:    "create opssight",
+			command: CreateOpsSightOptions{Name: "ops-one"},
+			want:    []string{"create", "opssight", "ops-one"},
+		},
+		{
+			name:    "create alert native",
+			command: CreateNativeOptions{Create: CreateAlertOptions{Name: "alt-one", PersistentStorage: BoolPtr(false)}, Output: "json"},
+			want:    []string{"create", "alert", "native", "alt-one", "--persistent-storage=false", "--output=json"},
+		},
+		{
+			name:    "create native without create command",
+			command: CreateNativeOptions{Create: DeleteOptions{Resource: AlertResource, Name: "alt"}},
+			wantErr: true,
+		},
+		{
+			name:    "create native unknown output",
+			command: CreateNativeOptions{Create: CreateOpsSightOptions{Name: "ops-one"}, Output: "xml"},
+			wantErr: true,
+		},
+		{
+			name:    "delete alert",
+			command: DeleteOptions{Resource: AlertResource, Name: "alt", Namespace: "alert"},
+			want:    []string{"delete", "alert", "alt", "--namespace=alert"},
+		},
+		{
+			name:    "destroy cluster scoped",
+			command: DestroyOptions{},
+			want:    []string{"destroy"},
+		},
+		{
+			name:    "destroy namespaces forcefully",
+			command: DestroyOptions{Namespaces: []string{"so-one", "so-two"}, Force: true},
+			want:    []string{"destroy", "so-one", "so-two", "--force"},
+		},
+		{
+			name:    "update operator without image",
+			command: UpdateOperatorOptions{Namespace: "so-one"},
+			wantErr: true,
+		},
+		{
+			name:    "update black duck",
+			command: UpdateBlackDuckOptions{Name: "bd-one", Version: "2019.6.0"},
+			want:    []string{"update", "blackduck", "bd-one", "--version=2019.6.0"},
+		},
+	}
+	for _, tt := range tests {
+		got, err := tt.command.Args()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Args() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Args() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Config holds the settings shared by every suite. It is loaded from defaults, then the YAML or
+// JSON file named by -cnt.config or CNT_CONFIG, then CNT_* environment variables, then -cnt.* flags
+type Config struct {
+	// SynopsysctlPath is the synopsysctl binary that is run by the suites
+	SynopsysctlPath string `json:"synopsysctlPath"`
+	// OperatorImage is the Synopsys Operator image that is deployed by the suites
+	OperatorImage string `json:"operatorImage"`
+	// ApplyNative makes the native specs apply the objects they verified to the cluster
+	ApplyNative bool `json:"applyNative"`
+	// UpdateGolden makes the native specs rewrite their golden files instead of comparing against them
+	UpdateGolden bool `json:"updateGolden"`
+	// ArtifactsDir receives the cluster state of every failed spec; empty disables collection
+	ArtifactsDir string `json:"artifactsDir"`
+	// LogLevel is the lowest level of the messages the test utilities log: debug, info, warn or error
+	LogLevel string `json:"logLevel"`
+	// Preflight checks that the cluster can run the suites before any spec runs
+	Preflight bool `json:"preflight"`
+	// Cluster is the cluster the suites run against
+	Cluster Cluster `json:"cluster"`
+	// Clusters are further clusters a spec can target by name, e.g. to run OpsSight on another
+	// cluster than Black Duck
+	Clusters   map[string]Cluster `json:"clusters"`
+	Namespaces Namespaces         `json:"namespaces"`
+	Timeouts   Timeouts           `json:"timeouts"`
+}
+
+// Cluster selects a cluster and how the suites talk to it
+type Cluster struct {
+	// Kubeconfig is the kubeconfig file; empty uses $KUBECONFIG or ~/.kube/config
+	Kubeconfig string `json:"kubeconfig"`
+	// Context is the kube context; empty uses the current context
+	Context string `json:"context"`
+	// Impersonate is the user the suites act as
+	Impersonate string `json:"impersonate"`
+	// QPS and Burst limit the requests to the API server; zero uses the client-go defaults
+	QPS   float32 `json:"qps"`
+	Burst int     `json:"burst"`
+	// RequestTimeout bounds every request to the API server; zero does not
+	RequestTimeout metav1.Duration `json:"requestTimeout"`
+}
+
+// ClusterNamed returns the cluster name of Clusters, or Cluster for an empty name
+func (c *Config) ClusterNamed(name string) (Cluster, error) {
+	if name == "" {
+		return c.Cluster, nil
+	}
+	cluster, ok := c.Clusters[name]
+	if !ok {
+		return Cluster{}, fmt.Errorf("cluster %q is not configured", name)
+	}
+	return cluster, nil
+}
+
+// Namespaces holds the namespace names used by the suites
+type Namespaces struct {
+	// Operator is the namespace synopsysctl deploys a cluster scoped operator into
+	Operator string `json:"operator"`
+	// Prefix is prepended to the namespaces created by the suites
+	Prefix string `json:"prefix"`
+	// TTL is how long after their creation the namespaces and cluster scoped objects of the suites may be garbage collected
+	TTL metav1.Duration `json:"ttl"`
+	// RemoveCRFinalizers removes the finalizers of the custom resources in namespaces that are not
+	// deleted in time, so a broken operator cannot keep them terminating
+	RemoveCRFinalizers bool `json:"removeCRFinalizers"`
+}
+
+// Timeouts holds how long the suites wait for things to happen
+type Timeouts struct {
+	// Command bounds a single synopsysctl command
+	Command metav1.Duration `json:"command"`
+	// PodReady bounds waiting for pods to be running and ready
+	PodReady metav1.Duration `json:"podReady"`
+	// PodFailureGrace is how long a pod may stay in a state like ImagePullBackOff or CrashLoopBackOff
+	// before the pod waiters give up on it
+	PodFailureGrace metav1.Duration `json:"podFailureGrace"`
+	// PodList bounds waiting for pods to appear or disappear
+	PodList metav1.Duration `json:"podList"`
+	// CRDAdded bounds waiting for a custom resource definition to be added
+	CRDAdded metav1.Duration `json:"crdAdded"`
+	// CRDDeleted bounds waiting for a deleted custom resource definition, and its custom resources, to be gone
+	CRDDeleted metav1.Duration `json:"crdDeleted"`
+	// CR bounds waiting for a custom resource to appear, disappear or change state
+	CR metav1.Duration `json:"cr"`
+	// NamespaceActive bounds waiting for a created namespace to be active
+	NamespaceActive metav1.Duration `json:"namespaceActive"`
+	// NamespaceDeleted bounds waiting for namespaces to be deleted
+	NamespaceDeleted metav1.Duration `json:"namespaceDeleted"`
+	// Service bounds waiting for services to appear or disappear
+	Service metav1.Duration `json:"service"`
+	// PVC bounds waiting for persistent volumes and claims
+	PVC metav1.Duration `json:"pvc"`
+	// Poll is how often the waiters poll the API server
+	Poll metav1.Duration `json:"poll"`
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() *Config {
+	return &Config{
+		SynopsysctlPath: "synopsysctl",
+		OperatorImage:   "gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x",
+		ArtifactsDir:    "_artifacts",
+		LogLevel:        "info",
+		Preflight:       true,
+		Namespaces: Namespaces{
+			Operator: "synopsys-operator",
+			Prefix:   "cnt",
+			TTL:      metav1.Duration{Duration: 6 * time.Hour},
+		},
+		Timeouts: Timeouts{
+			Command:          metav1.Duration{Duration: 5 * time.Minute},
+			PodReady:         metav1.Duration{Duration: 5 * time.Minute},
+			PodFailureGrace:  metav1.Duration{Duration: time.Minute},
+			PodList:          metav1.Duration{Duration: time.Minute},
+			CRDAdded:         metav1.Duration{Duration: 30 * time.Second},
+			CRDDeleted:       metav1.Duration{Duration: 2 * time.Minute},
+			CR:               metav1.Duration{Duration: time.Minute},
+			NamespaceActive:  metav1.Duration{Duration: 30 * time.Second},
+			NamespaceDeleted: metav1.Duration{Duration: 2 * time.Minute},
+			Service:          metav1.Duration{Duration: time.Minute},
+			PVC:              metav1.Duration{Duration: 5 * time.Minute},
+			Poll:             metav1.Duration{Duration: 2 * time.Second},
+		},
+	}
+}
+
+// setting is a value that can be overridden by an environment variable and a flag
+type setting struct {
+	name  string
+	env   string
+	usage string
+	set   func(c *Config, value string) error
+	// isBool lets the flag be passed without a value, e.g. -cnt.apply-native
+	isBool bool
+}
+
+func stringSetting(name, env, usage string, field func(c *Config) *string) setting {
+	return setting{name: name, env: env, usage: usage, set: func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}}
+}
+
+func boolSetting(name, env, usage string, field func(c *Config) *bool) setting {
+	return setting{name: name, env: env, usage: usage, isBool: true, set: func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %v", name, value, err)
+		}
+		*field(c) = b
+		return nil
+	}}
+}
+
+func intSetting(name, env, usage string, field func(c *Config) *int) setting {
+	return setting{name: name, env: env, usage: usage, set: func(c *Config, value string) error {
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %v", name, value, err)
+		}
+		*field(c) = i
+		return nil
+	}}
+}
+
+func float32Setting(name, env, usage string, field func(c *Config) *float32) setting {
+	return setting{name: name, env: env, usage: usage, set: func(c *Config, value string) error {
+		f, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %v", name, value, err)
+		}
+		*field(c) = float32(f)
+		return nil
+	}}
+}
+
+func durationSetting(name, env, usage string, field func(c *Config) *metav1.Duration) setting {
+	return setting{name: name, env: env, usage: usage, set: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %v", name, value, err)
+		}
+		field(c).Duration = d
+		return nil
+	}}
+}
+
+var settings = []setting{
+	stringSetting("synopsysctl", "CNT_SYNOPSYSCTL_PATH", "synopsysctl binary run by the suites", func(c *Config) *string { return &c.SynopsysctlPath }),
+	stringSetting("operator-image", "CNT_OPERATOR_IMAGE", "Synopsys Operator image deployed by the suites", func(c *Config) *string { return &c.OperatorImage }),
+	boolSetting("apply-native", "CNT_APPLY_NATIVE", "apply the objects verified by the native specs to the cluster", func(c *Config) *bool { return &c.ApplyNative }),
+	boolSetting("update-golden", "CNT_UPDATE_GOLDEN", "rewrite the golden files of the native specs instead of comparing against them", func(c *Config) *bool { return &c.UpdateGolden }),
+	stringSetting("artifacts-dir", "CNT_ARTIFACTS_DIR", "directory that receives the cluster state of failed specs", func(c *Config) *string { return &c.ArtifactsDir }),
+	stringSetting("log-level", "CNT_LOG_LEVEL", "lowest level of the messages the test utilities log: debug, info, warn or error", func(c *Config) *string { return &c.LogLevel }),
+	boolSetting("preflight", "CNT_PREFLIGHT", "check that the cluster can run the suites before any spec runs", func(c *Config) *bool { return &c.Preflight }),
+	stringSetting("kubeconfig", "CNT_KUBECONFIG", "kubeconfig file of the cluster (default $KUBECONFIG or ~/.kube/config)", func(c *Config) *string { return &c.Cluster.Kubeconfig }),
+	stringSetting("kube-context", "CNT_KUBE_CONTEXT", "kube context of the cluster (default the current context)", func(c *Config) *string { return &c.Cluster.Context }),
+	stringSetting("kube-as", "CNT_KUBE_AS", "user to impersonate in the cluster", func(c *Config) *string { return &c.Cluster.Impersonate }),
+	float32Setting("kube-qps", "CNT_KUBE_QPS", "requests per second to the API server", func(c *Config) *float32 { return &c.Cluster.QPS }),
+	intSetting("kube-burst", "CNT_KUBE_BURST", "burst of requests to the API server", func(c *Config) *int { return &c.Cluster.Burst }),
+	durationSetting("kube-request-timeout", "CNT_KUBE_REQUEST_TIMEOUT", "timeout of a single request to the API server", func(c *Config) *metav1.Duration { return &c.Cluster.RequestTimeout }),
+	stringSetting("operator-namespace", "CNT_OPERATOR_NAMESPACE", "namespace of a cluster scoped Synopsys Operator", func(c *Config) *string { return &c.Namespaces.Operator }),
+	stringSetting("namespace-prefix", "CNT_NAMESPACE_PREFIX", "prefix of the namespaces created by the suites", func(c *Config) *string { return &c.Namespaces.Prefix }),
+	durationSetting("namespace-ttl", "CNT_NAMESPACE_TTL", "how long after their creation the namespaces of the suites may be garbage collected", func(c *Config) *metav1.Duration { return &c.Namespaces.TTL }),
+	boolSetting("remove-cr-finalizers", "CNT_REMOVE_CR_FINALIZERS", "remove the finalizers of custom resources in namespaces that are not deleted in time", func(c *Config) *bool { return &c.Namespaces.RemoveCRFinalizers }),
+	durationSetting("command-timeout", "CNT_COMMAND_TIMEOUT", "timeout of a single synopsysctl command", func(c *Config) *metav1.Duration { return &c.Timeouts.Command }),
+	durationSetting("pod-ready-timeout", "CNT_POD_READY_TIMEOUT", "timeout for pods to be running and ready", func(c *Config) *metav1.Duration { return &c.Timeouts.PodReady }),
+	durationSetting("pod-failure-grace", "CNT_POD_FAILURE_GRACE", "how long pods may be stuck in ImagePullBackOff, CrashLoopBackOff and similar states", func(c *Config) *metav1.Duration { return &c.Timeouts.PodFailureGrace }),
+	durationSetting("pod-list-timeout", "CNT_POD_LIST_TIMEOUT", "timeout for pods to appear or disappear", func(c *Config) *metav1.Duration { return &c.Timeouts.PodList }),
+	durationSetting("crd-added-timeout", "CNT_CRD_ADDED_TIMEOUT", "timeout for a custom resource definition to be added", func(c *Config) *metav1.Duration { return &c.Timeouts.CRDAdded }),
+	durationSetting("crd-deleted-timeout", "CNT_CRD_DELETED_TIMEOUT", "timeout for a deleted custom resource definition to be gone", func(c *Config) *metav1.Duration { return &c.Timeouts.CRDDeleted }),
+	durationSetting("cr-timeout", "CNT_CR_TIMEOUT", "timeout for a custom resource to appear, disappear or change state", func(c *Config) *metav1.Duration { return &c.Timeouts.CR }),
+	durationSetting("namespace-active-timeout", "CNT_NAMESPACE_ACTIVE_TIMEOUT", "timeout for a created namespace to be active", func(c *Config) *metav1.Duration { return &c.Timeouts.NamespaceActive }),
+	durationSetting("namespace-deleted-timeout", "CNT_NAMESPACE_DELETED_TIMEOUT", "timeout for namespaces to be deleted", func(c *Config) *metav1.Duration { return &c.Timeouts.NamespaceDeleted }),
+	durationSetting("service-timeout", "CNT_SERVICE_TIMEOUT", "timeout for services to appear or disappear", func(c *Config) *metav1.Duration { return &c.Timeouts.Service }),
+	durationSetting("pvc-timeout", "CNT_PVC_TIMEOUT", "timeout for persistent volumes and claims", func(c *Config) *metav1.Duration { return &c.Timeouts.PVC }),
+	durationSetting("poll-interval", "CNT_POLL_INTERVAL", "how often the waiters poll the API server", func(c *Config) *metav1.Duration { return &c.Timeouts.Poll }),
+}
+
+// flagValue is the value of a -cnt.* flag, applied by Load over the file and the environment
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+// IsBoolFlag tells the flag package that a bool setting may be passed without a value
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+var (
+	flagLock   sync.Mutex
+	flagSet    *flag.FlagSet
+	configFlag string
+	flagValues = map[string]*flagValue{}
+
+	loadLock      sync.Mutex
+	loaded        *Config
+	loadErr       error
+	errNotParsed  = fmt.Errorf("the suite configuration is loaded before flags are parsed")
+	warnNotParsed sync.Once
+)
+
+// RegisterFlags registers -cnt.config and a -cnt.* flag for every setting on fs. Call it from an
+// init function of a suite so the flags can be passed with "ginkgo -- -cnt.operator-image=..."
+func RegisterFlags(fs *flag.FlagSet) {
+	flagLock.Lock()
+	defer flagLock.Unlock()
+	flagSet = fs
+	flagValues = map[string]*flagValue{}
+	fs.StringVar(&configFlag, "cnt.config", "", "YAML or JSON suite configuration file (or CNT_CONFIG)")
+	for _, s := range settings {
+		value := &flagValue{isBool: s.isBool}
+		flagValues[s.name] = value
+		fs.Var(value, "cnt."+s.name, fmt.Sprintf("%s (or %s)", s.usage, s.env))
+	}
+}
+
+// Load returns the defaults overridden by the file at path (if not empty), CNT_* environment
+// variables and any -cnt.* flags that were set. A variable or flag that is set to "" clears a string
+// setting. Unknown fields in the file are an error
+func Load(path string) (*Config, error) {
+	c := Default()
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.UnmarshalStrict(b, c); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+	}
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(c, value); err != nil {
+				return nil, fmt.Errorf("%s: %v", s.env, err)
+			}
+		}
+	}
+	flagLock.Lock()
+	defer flagLock.Unlock()
+	set := visitedFlags()
+	for _, s := range settings {
+		if set["cnt."+s.name] {
+			if err := s.set(c, flagValues[s.name].value); err != nil {
+				return nil, fmt.Errorf("-cnt.%s: %v", s.name, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// visitedFlags returns the names of the flags that were set on the registered flag set. The caller
+// holds flagLock
+func visitedFlags() map[string]bool {
+	set := map[string]bool{}
+	if flagSet != nil {
+		flagSet.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	}
+	return set
+}
+
+// Init loads the suite configuration once flags are parsed and caches it, or the error. Suites call
+// it from BeforeSuite and commands after flag.Parse so a bad configuration is reported before it is used
+func Init() error {
+	_, err := load()
+	return err
+}
+
+// Get returns the suite configuration loaded by Init, loading it on the first call. It panics if the
+// configuration is invalid, so a typo never runs the suites with the defaults. Until flags are parsed,
+// e.g. while Ginkgo builds the spec tree, it warns once and returns the defaults
+func Get() *Config {
+	c, err := load()
+	if err == errNotParsed {
+		warnNotParsed.Do(func() {
+			fmt.Fprintf(os.Stderr, "WARNING: the suite configuration is used before flags are parsed; using the defaults\n")
+		})
+		return Default()
+	}
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func load() (*Config, error) {
+	loadLock.Lock()
+	defer loadLock.Unlock()
+	if loaded != nil || loadErr != nil {
+		return loaded, loadErr
+	}
+	if !flag.Parsed() {
+		return nil, errNotParsed
+	}
+	path := os.Getenv("CNT_CONFIG")
+	flagLock.Lock()
+	if visitedFlags()["cnt.config"] {
+		path = configFlag
+	}
+	flagLock.Unlock()
+	loaded, loadErr = Load(path)
+	if loadErr != nil {
+		loadErr = fmt.Errorf("failed to load the suite configuration: %v", loadErr)
+	}
+	return loaded, loadErr
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	file := `
+operatorImage: gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.8.x
+timeouts:
+  podReady: 10m
+  poll: 5s
+`
+	if err := ioutil.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("CNT_POLL_INTERVAL", "1s")
+	defer os.Unsetenv("CNT_POLL_INTERVAL")
+	os.Setenv("CNT_APPLY_NATIVE", "true")
+	defer os.Unsetenv("CNT_APPLY_NATIVE")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", path, err)
+	}
+	if c.OperatorImage != "gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.8.x" {
+		t.Errorf("operator image from the file was not loaded: %s", c.OperatorImage)
+	}
+	if c.Timeouts.PodReady.Duration != 10*time.Minute {
+		t.Errorf("pod ready timeout from the file was not loaded: %v", c.Timeouts.PodReady)
+	}
+	if c.Timeouts.Poll.Duration != time.Second {
+		t.Errorf("CNT_POLL_INTERVAL did not override the file: %v", c.Timeouts.Poll)
+	}
+	if !c.ApplyNative {
+		t.Errorf("CNT_APPLY_NATIVE was not loaded")
+	}
+	if c.SynopsysctlPath != Default().SynopsysctlPath {
+		t.Errorf("default synopsysctl path was not kept: %s", c.SynopsysctlPath)
+	}
+
+	os.Setenv("CNT_POD_READY_TIMEOUT", "soon")
+	defer os.Unsetenv("CNT_POD_READY_TIMEOUT")
+	if _, err := Load(""); err == nil {
+		t.Errorf("expected an error for an invalid duration")
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte("timeouts:\n  podReadyTimeout: 10m\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for the unknown podReadyTimeout")
+	}
+}
+
+func TestBoolFlags(t *testing.T) {
+	fs := flag.NewFlagSet("suite", flag.ContinueOnError)
+	RegisterFlags(fs)
+	defer RegisterFlags(flag.NewFlagSet("reset", flag.ContinueOnError))
+	if err := fs.Parse([]string{"-cnt.apply-native", "-cnt.preflight=false", "-cnt.poll-interval=1s"}); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.ApplyNative || c.Preflight || c.Timeouts.Poll.Duration != time.Second {
+		t.Errorf("flags were not applied: apply native %v, preflight %v, poll %v", c.ApplyNative, c.Preflight, c.Timeouts.Poll)
+	}
+}
+
+func TestEmptyValuesClearSettings(t *testing.T) {
+	os.Setenv("CNT_NAMESPACE_PREFIX", "")
+	defer os.Unsetenv("CNT_NAMESPACE_PREFIX")
+	c, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Namespaces.Prefix != "" {
+		t.Errorf("an empty CNT_NAMESPACE_PREFIX did not clear the prefix: %q", c.Namespaces.Prefix)
+	}
+
+	os.Setenv("CNT_NAMESPACE_PREFIX", "env")
+	fs := flag.NewFlagSet("suite", flag.ContinueOnError)
+	RegisterFlags(fs)
+	defer RegisterFlags(flag.NewFlagSet("reset", flag.ContinueOnError))
+	if err := fs.Parse([]string{"-cnt.namespace-prefix="}); err != nil {
+		t.Fatal(err)
+	}
+	if c, err = Load(""); err != nil {
+		t.Fatal(err)
+	}
+	if c.Namespaces.Prefix != "" {
+		t.Errorf("an empty -cnt.namespace-prefix did not clear the prefix: %q", c.Namespaces.Prefix)
+	}
+
+	os.Setenv("CNT_POLL_INTERVAL", "")
+	defer os.Unsetenv("CNT_POLL_INTERVAL")
+	if _, err := Load(""); err == nil {
+		t.Error("expected an error for an empty duration")
+	}
+}
+
+func TestClusterNamed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	file := `
+cluster:
+  context: blackduck
+clusters:
+  opssight:
+    context: opssight
+    qps: 20
+`
+	if err := ioutil.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("CNT_KUBE_BURST", "40")
+	defer os.Unsetenv("CNT_KUBE_BURST")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", path, err)
+	}
+	cluster, err := c.ClusterNamed("")
+	if err != nil || cluster.Context != "blackduck" || cluster.Burst != 40 {
+		t.Errorf("unexpected default cluster %+v, %v", cluster, err)
+	}
+	cluster, err = c.ClusterNamed("opssight")
+	if err != nil || cluster.Context != "opssight" || cluster.QPS != 20 {
+		t.Errorf("unexpected opssight cluster %+v, %v", cluster, err)
+	}
+	if _, err := c.ClusterNamed("alert"); err == nil {
+		t.Error("expected an error for a cluster that is not configured")
+	}
+}
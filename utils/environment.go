@@ -0,0 +1,200 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
+	clientcmdapiv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// The With* methods return a copy of sCtl so specs running in parallel can each drive their own
+// cluster, namespace or scratch directory without changing a Synopsysctl that others are using.
+// The copy shares the transcript recorder and replayer of sCtl
+
+func (sCtl *Synopsysctl) clone() *Synopsysctl {
+	return &Synopsysctl{
+		path:     sCtl.path,
+		env:      append([]string{}, sCtl.env...),
+		workDir:  sCtl.workDir,
+		stdin:    sCtl.stdin,
+		recorder: sCtl.recorder,
+		replayer: sCtl.replayer,
+		logger:   sCtl.logger,
+	}
+}
+
+// WithEnv returns a copy of sCtl that adds env, in KEY=VALUE form, to the environment of every command
+func (sCtl *Synopsysctl) WithEnv(env ...string) *Synopsysctl {
+	c := sCtl.clone()
+	c.env = append(c.env, env...)
+	return c
+}
+
+// WithKubeconfig returns a copy of sCtl that runs every command against the kubeconfig at path
+func (sCtl *Synopsysctl) WithKubeconfig(path string) *Synopsysctl {
+	return sCtl.WithEnv("KUBECONFIG=" + path)
+}
+
+// WithKubeContext returns a copy of sCtl that runs every command against the kube context name.
+// The context is selected by writing a copy of the current kubeconfig whose current-context is name
+// into a temporary file, so it works with every synopsysctl release. The file holds the credentials of
+// the kubeconfig; call the returned cleanup to remove it once the copy is no longer used
+func (sCtl *Synopsysctl) WithKubeContext(name string) (*Synopsysctl, func() error, error) {
+	return sCtl.withKubeconfigCopy(name, "")
+}
+
+// WithImpersonation returns a copy of sCtl that runs every command as user, like kubectl --as, against
+// the kube context name or the current context if name is empty. Like WithKubeContext, it writes a copy
+// of the kubeconfig, with user in the act-as field of the user of the context; call the returned
+// cleanup to remove it
+func (sCtl *Synopsysctl) WithImpersonation(name, user string) (*Synopsysctl, func() error, error) {
+	return sCtl.withKubeconfigCopy(name, user)
+}
+
+// ForCluster returns a copy of sCtl that runs against cluster: its kubeconfig, kube context and
+// impersonated user. The cleanup removes the kubeconfig copy written for a context or user, if any
+func (sCtl *Synopsysctl) ForCluster(cluster config.Cluster) (*Synopsysctl, func() error, error) {
+	if cluster.Kubeconfig != "" {
+		sCtl = sCtl.WithKubeconfig(cluster.Kubeconfig)
+	}
+	if cluster.Context == "" && cluster.Impersonate == "" {
+		return sCtl, func() error { return nil }, nil
+	}
+	return sCtl.WithImpersonation(cluster.Context, cluster.Impersonate)
+}
+
+func (sCtl *Synopsysctl) withKubeconfigCopy(name, impersonate string) (*Synopsysctl, func() error, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig := lookupEnv(sCtl.environ(), "KUBECONFIG"); kubeconfig != "" {
+		rules.Precedence = filepath.SplitList(kubeconfig)
+	}
+	kubeConfig, err := rules.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load the kubeconfig: %v", err)
+	}
+	if name == "" {
+		name = kubeConfig.CurrentContext
+	}
+	kubeContext, ok := kubeConfig.Contexts[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("kube context %q does not exist", name)
+	}
+	kubeConfig.CurrentContext = name
+	if impersonate != "" {
+		authInfo, ok := kubeConfig.AuthInfos[kubeContext.AuthInfo]
+		if !ok {
+			return nil, nil, fmt.Errorf("user %q of kube context %s does not exist", kubeContext.AuthInfo, name)
+		}
+		authInfo.Impersonate = impersonate
+	}
+	f, err := ioutil.TempFile("", "synopsysctl-kubeconfig-")
+	if err != nil {
+		return nil, nil, err
+	}
+	f.Close()
+	cleanup := func() error {
+		if err := os.Remove(f.Name()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := writeKubeconfig(kubeConfig, f.Name()); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to write the kubeconfig for context %s: %v", name, err)
+	}
+	return sCtl.WithKubeconfig(f.Name()), cleanup, nil
+}
+
+// writeKubeconfig writes kubeConfig to path as a v1 Config. It is encoded with encoding/json rather
+// than the clientcmd codec, whose json-iterator release cannot encode the map fields of users with
+// recent Go releases
+func writeKubeconfig(kubeConfig *clientcmdapi.Config, path string) error {
+	v1Config := &clientcmdapiv1.Config{}
+	if err := clientcmdlatest.Scheme.Convert(kubeConfig, v1Config, nil); err != nil {
+		return err
+	}
+	v1Config.APIVersion, v1Config.Kind = "v1", "Config"
+	b, err := yaml.Marshal(v1Config)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0600)
+}
+
+// WithLogger returns a copy of sCtl that logs every command to l instead of the default logger
+func (sCtl *Synopsysctl) WithLogger(l logging.Logger) *Synopsysctl {
+	c := sCtl.clone()
+	c.logger = l
+	return c
+}
+
+// WithDir returns a copy of sCtl that runs every command in dir
+func (sCtl *Synopsysctl) WithDir(dir string) *Synopsysctl {
+	c := sCtl.clone()
+	c.workDir = dir
+	return c
+}
+
+// WithStdin returns a copy of sCtl that writes input to the stdin of every command, for commands that prompt
+func (sCtl *Synopsysctl) WithStdin(input string) *Synopsysctl {
+	c := sCtl.clone()
+	c.stdin = &input
+	return c
+}
+
+// mergeEnv returns base with every KEY=VALUE of overrides replacing the same KEY in base
+func mergeEnv(base, overrides []string) []string {
+	merged := []string{}
+	index := map[string]int{}
+	for _, kv := range append(append([]string{}, base...), overrides...) {
+		key := strings.SplitN(kv, "=", 2)[0]
+		if i, ok := index[key]; ok {
+			merged[i] = kv
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, kv)
+	}
+	return merged
+}
+
+// lookupEnv returns the value of key in env
+func lookupEnv(env []string, key string) string {
+	value := ""
+	for _, kv := range env {
+		if strings.HasPrefix(kv, key+"=") {
+			value = strings.TrimPrefix(kv, key+"=")
+		}
+	}
+	return value
+}
@@ -0,0 +1,364 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package framework is the per-spec setup and cleanup shared by the suites, modeled on the framework
+// of the Kubernetes e2e tests
+package framework
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	utils "github.com/blackducksoftware/cloud-native-tests/utils"
+	"github.com/blackducksoftware/cloud-native-tests/utils/artifacts"
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	crutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/cr"
+	crdutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/crd"
+	namespaceutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/namespace"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
+	"github.com/onsi/ginkgo"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Framework builds the clients of a suite once and tracks what every spec creates so it can be
+// removed after the spec, even if the spec failed
+type Framework struct {
+	// BaseName is part of the name of every namespace the framework creates
+	BaseName string
+	// Cluster is the name of the configured cluster the framework targets; empty is the default cluster
+	Cluster string
+	// Logger receives the messages of the framework and its synopsysctl; nil is the default logger.
+	// Pass Context to the waiters so they log to it too
+	Logger logging.Logger
+
+	RestConfig         *rest.Config
+	KubeClient         *kubernetes.Clientset
+	DynamicClient      dynamic.Interface
+	APIExtensionClient *apiextensionsclient.Clientset
+	CRClient           *crutils.Client
+	Synopsysctl        *utils.Synopsysctl
+
+	// Namespaces are the namespaces the current spec created or registered for deletion
+	Namespaces []string
+
+	// Synopsysctl is a copy of synopsysctl for cluster that is made for every spec, since the copy
+	// writes the credentials into a file that the spec removes
+	synopsysctl *utils.Synopsysctl
+	cluster     config.Cluster
+	// transcript records the synopsysctl commands of the current spec
+	transcript string
+
+	cleanupLock sync.Mutex
+	cleanups    []cleanupAction
+}
+
+// cleanupAction is a registered cleanup; its description is reported if it fails
+type cleanupAction struct {
+	description string
+	run         func() error
+}
+
+// NewFramework returns a Framework and registers its BeforeEach and AfterEach in the enclosing
+// container. Call it from a Describe body
+func NewFramework(baseName string) *Framework {
+	return NewFrameworkForCluster(baseName, "")
+}
+
+// NewFrameworkForCluster is NewFramework for the cluster name of the clusters of the suite
+// configuration. Its clients and synopsysctl target that cluster, so one suite can use several
+func NewFrameworkForCluster(baseName, cluster string) *Framework {
+	f := &Framework{BaseName: baseName, Cluster: cluster}
+	ginkgo.BeforeEach(f.BeforeEach)
+	ginkgo.AfterEach(f.AfterEach)
+	return f
+}
+
+// log returns the logger of the framework
+func (f *Framework) log() logging.Logger {
+	if f.Logger == nil {
+		return logging.Default()
+	}
+	return f.Logger
+}
+
+// Context returns a copy of parent that carries the logger of the framework for the waiters that take
+// a context, e.g. pod.WaitForPodsWithLabelRunningReadyContext and workload.WaitForReady
+func (f *Framework) Context(parent context.Context) context.Context {
+	return logging.NewContext(parent, f.log())
+}
+
+// BeforeEach fails the spec if the configuration cannot be loaded, builds the clients the first time
+// it runs and resets what the previous spec tracked
+func (f *Framework) BeforeEach() {
+	if err := config.Init(); err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	if f.KubeClient == nil {
+		if err := f.buildClients(); err != nil {
+			ginkgo.Fail(fmt.Sprintf("failed to create the clients: %v", err))
+		}
+	}
+	f.Namespaces = nil
+	f.cleanupLock.Lock()
+	f.cleanups = nil
+	f.cleanupLock.Unlock()
+	if err := f.selectKubeContext(); err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	f.startTranscript()
+}
+
+// selectKubeContext points Synopsysctl at the kube context and impersonated user of the cluster for
+// the current spec
+func (f *Framework) selectKubeContext() error {
+	base := f.synopsysctl
+	if f.Logger != nil {
+		base = base.WithLogger(f.Logger)
+	}
+	sCtl, cleanup, err := base.ForCluster(f.cluster)
+	if err != nil {
+		return err
+	}
+	f.Synopsysctl = sCtl
+	if f.cluster.Context != "" || f.cluster.Impersonate != "" {
+		f.AddCleanup(fmt.Sprintf("remove the kubeconfig of context %q", f.cluster.Context), cleanup)
+	}
+	return nil
+}
+
+func (f *Framework) startTranscript() {
+	tmp, err := ioutil.TempFile("", "synopsysctl-transcript-")
+	if err != nil {
+		f.log().Warnf("failed to create the synopsysctl transcript: %v", err)
+		return
+	}
+	tmp.Close()
+	if err := f.Synopsysctl.RecordTo(tmp.Name()); err != nil {
+		f.log().Warnf("failed to record the synopsysctl transcript: %v", err)
+		os.Remove(tmp.Name())
+		return
+	}
+	f.transcript = tmp.Name()
+}
+
+func (f *Framework) stopTranscript() {
+	if f.transcript == "" {
+		return
+	}
+	f.Synopsysctl.StopRecording()
+	os.Remove(f.transcript)
+	f.transcript = ""
+}
+
+// CollectArtifacts writes the cluster state of the namespaces of the current spec and its synopsysctl
+// transcript into the configured artifacts directory
+func (f *Framework) CollectArtifacts() {
+	dir := config.Get().ArtifactsDir
+	if dir == "" {
+		return
+	}
+	dir = artifacts.SpecDir(dir, ginkgo.CurrentGinkgoTestDescription().FullTestText)
+	collector := &artifacts.Collector{KubeClient: f.KubeClient, DynamicClient: f.DynamicClient, APIExtensionClient: f.APIExtensionClient}
+	if err := collector.Collect(dir, f.Namespaces, f.transcript); err != nil {
+		f.log().Warnf("%v", err)
+	}
+	f.log().Infof("artifacts of the failed spec are in %s", dir)
+}
+
+func (f *Framework) buildClients() error {
+	cluster, err := config.Get().ClusterNamed(f.Cluster)
+	if err != nil {
+		return err
+	}
+	clients, err := k8sutils.DefaultClientFactory.Clients(k8sutils.ClientOptionsFor(cluster))
+	if err != nil {
+		return fmt.Errorf("failed to get the kube config: %v", err)
+	}
+	f.RestConfig, f.KubeClient, f.DynamicClient, f.APIExtensionClient = clients.RestConfig, clients.KubeClient, clients.DynamicClient, clients.APIExtensionClient
+	f.CRClient = crutils.NewClient(clients.DynamicClient)
+	f.synopsysctl, f.cluster = utils.NewSynopsysctl(""), cluster
+	return nil
+}
+
+// AfterEach collects the artifacts of a failed spec, runs the registered cleanups in reverse order,
+// waits for the namespaces of the spec to be deleted and fails the spec if any of that failed
+func (f *Framework) AfterEach() {
+	if f.KubeClient == nil {
+		return
+	}
+	if ginkgo.CurrentGinkgoTestDescription().Failed {
+		f.CollectArtifacts()
+	}
+	f.stopTranscript()
+	errs := f.RunCleanups()
+	if len(f.Namespaces) > 0 {
+		if err := f.waitForNamespacesDeleted(); err != nil {
+			errs = append(errs, fmt.Sprintf("namespaces %s were not deleted: %v", strings.Join(f.Namespaces, ", "), err))
+		}
+	}
+	if len(errs) > 0 {
+		ginkgo.Fail(fmt.Sprintf("cleanup failed:\n%s", strings.Join(errs, "\n")))
+	}
+}
+
+// waitForNamespacesDeleted waits for the namespaces of the spec to be deleted. If they are not and
+// removing CR finalizers is configured, it removes them and waits once more
+func (f *Framework) waitForNamespacesDeleted() error {
+	timeout := config.Get().Timeouts.NamespaceDeleted.Duration
+	err := namespaceutils.WaitForNamespacesDeletedWithReport(f.KubeClient, f.DynamicClient, f.Namespaces, timeout)
+	timeoutErr, ok := err.(*namespaceutils.DeletionTimeoutError)
+	if !ok || !config.Get().Namespaces.RemoveCRFinalizers {
+		return err
+	}
+	f.log().Warnf("%v", timeoutErr)
+	for _, ns := range timeoutErr.Namespaces() {
+		removed, err := namespaceutils.RemoveCRFinalizers(f.DynamicClient, ns)
+		if err != nil {
+			return err
+		}
+		for _, r := range removed {
+			f.log().Infof("removed the finalizers of %s in namespace %s", r, ns)
+		}
+	}
+	return namespaceutils.WaitForNamespacesDeletedWithReport(f.KubeClient, f.DynamicClient, timeoutErr.Namespaces(), timeout)
+}
+
+// AddCleanup registers action to run after the current spec. Cleanups run in the reverse order they
+// were added, and every cleanup runs even if an earlier one failed
+func (f *Framework) AddCleanup(description string, action func() error) {
+	f.cleanupLock.Lock()
+	defer f.cleanupLock.Unlock()
+	f.cleanups = append(f.cleanups, cleanupAction{description: description, run: action})
+}
+
+// RunCleanups runs and forgets the registered cleanups and returns the failures
+func (f *Framework) RunCleanups() []string {
+	f.cleanupLock.Lock()
+	cleanups := f.cleanups
+	f.cleanups = nil
+	f.cleanupLock.Unlock()
+	errs := []string{}
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		if err := f.runCleanup(cleanups[i]); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", cleanups[i].description, err))
+		}
+	}
+	return errs
+}
+
+func (f *Framework) runCleanup(action cleanupAction) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	f.log().Debugf("cleanup: %s", action.description)
+	return action.run()
+}
+
+// ignoreNotFound treats objects that are already gone as cleaned up
+func ignoreNotFound(err error) error {
+	if apierrs.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// UniqueName returns baseName, prefixed with the configured namespace prefix and followed by a random suffix
+func (f *Framework) UniqueName(baseName string) string {
+	name := fmt.Sprintf("%s-%s", baseName, utilrand.String(5))
+	if prefix := config.Get().Namespaces.Prefix; prefix != "" {
+		name = fmt.Sprintf("%s-%s", prefix, name)
+	}
+	return name
+}
+
+// CreateNamespace creates a uniquely named namespace for the current spec, waits for it to be active
+// and deletes it after the spec. The namespace is labeled as owned by the framework and expires after
+// the configured TTL so it can be garbage collected if the suite is aborted
+func (f *Framework) CreateNamespace(baseName string) (*corev1.Namespace, error) {
+	name := f.UniqueName(baseName)
+	_, err := namespaceutils.Create(f.KubeClient, namespaceutils.CreateOptions{
+		Name:  name,
+		Owner: f.BaseName,
+		TTL:   config.Get().Namespaces.TTL.Duration,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create namespace %s: %v", name, err)
+	}
+	f.DeleteNamespaceOnCleanup(name)
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.NamespaceActive.Duration)
+	defer cancel()
+	return namespaceutils.WaitForActive(ctx, f.KubeClient, name)
+}
+
+// DeleteNamespaceOnCleanup deletes the namespace name after the current spec, e.g. a namespace created by synopsysctl
+func (f *Framework) DeleteNamespaceOnCleanup(name string) {
+	f.Namespaces = append(f.Namespaces, name)
+	f.AddCleanup(fmt.Sprintf("delete namespace %s", name), func() error {
+		return ignoreNotFound(f.KubeClient.CoreV1().Namespaces().Delete(name, &metav1.DeleteOptions{}))
+	})
+}
+
+// DeleteClusterRoleOnCleanup deletes the ClusterRole name after the current spec
+func (f *Framework) DeleteClusterRoleOnCleanup(name string) {
+	f.AddCleanup(fmt.Sprintf("delete cluster role %s", name), func() error {
+		return ignoreNotFound(f.KubeClient.RbacV1().ClusterRoles().Delete(name, &metav1.DeleteOptions{}))
+	})
+}
+
+// DeleteClusterRoleBindingOnCleanup deletes the ClusterRoleBinding name after the current spec
+func (f *Framework) DeleteClusterRoleBindingOnCleanup(name string) {
+	f.AddCleanup(fmt.Sprintf("delete cluster role binding %s", name), func() error {
+		return ignoreNotFound(f.KubeClient.RbacV1().ClusterRoleBindings().Delete(name, &metav1.DeleteOptions{}))
+	})
+}
+
+// DeleteCRDOnCleanup deletes the CustomResourceDefinition name after the current spec and waits until it is gone
+func (f *Framework) DeleteCRDOnCleanup(name string) {
+	f.AddCleanup(fmt.Sprintf("delete crd %s", name), func() error {
+		if err := ignoreNotFound(f.APIExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete(name, &metav1.DeleteOptions{})); err != nil {
+			return err
+		}
+		// the next spec would otherwise find the terminating crd when it waits for the crd to be added
+		return crdutils.BlockUntilCrdIsDeleted(f.APIExtensionClient, name, int(config.Get().Timeouts.CRDDeleted.Seconds()))
+	})
+}
+
+// DeleteCROnCleanup deletes the custom resource name of gvr in namespace after the current spec
+func (f *Framework) DeleteCROnCleanup(gvr schema.GroupVersionResource, namespace, name string) {
+	f.AddCleanup(fmt.Sprintf("delete %s %s/%s", gvr.Resource, namespace, name), func() error {
+		return ignoreNotFound(f.DynamicClient.Resource(gvr).Namespace(namespace).Delete(name, &metav1.DeleteOptions{}))
+	})
+}
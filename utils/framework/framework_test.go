@@ -0,0 +1,80 @@
+package framework
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	utils "github.com/blackducksoftware/cloud-native-tests/utils"
+)
+
+func TestRunCleanups(t *testing.T) {
+	f := &Framework{BaseName: "test"}
+	ran := []string{}
+	f.AddCleanup("first", func() error {
+		ran = append(ran, "first")
+		return nil
+	})
+	f.AddCleanup("second", func() error {
+		ran = append(ran, "second")
+		return fmt.Errorf("forbidden")
+	})
+	f.AddCleanup("third", func() error {
+		ran = append(ran, "third")
+		panic("nil client")
+	})
+
+	errs := f.RunCleanups()
+	if want := []string{"third", "second", "first"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("cleanups ran in order %v, want %v", ran, want)
+	}
+	if len(errs) != 2 || !strings.HasPrefix(errs[0], "third: panic: nil client") || errs[1] != "second: forbidden" {
+		t.Errorf("unexpected errors %q", errs)
+	}
+	if errs := f.RunCleanups(); len(errs) != 0 || len(ran) != 3 {
+		t.Errorf("cleanups ran twice")
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	f := &Framework{BaseName: "test"}
+	a, b := f.UniqueName("so"), f.UniqueName("so")
+	if a == b {
+		t.Errorf("expected unique names, got %s twice", a)
+	}
+	if !regexp.MustCompile(`^cnt-so-[a-z0-9]{5}$`).MatchString(a) {
+		t.Errorf("unexpected name %s", a)
+	}
+}
+
+func TestDeleteOperatorOnCleanup(t *testing.T) {
+	f := &Framework{BaseName: "test"}
+	f.DeleteOperatorOnCleanup(utils.DeployOptions{Namespace: "so-one", EnabledResources: []utils.Resource{utils.AlertResource}})
+	descriptions := []string{}
+	for _, c := range f.cleanups {
+		descriptions = append(descriptions, c.description)
+	}
+	want := []string{"delete cluster role synopsys-operator-admin", "delete cluster role binding synopsys-operator-admin", "delete crd alerts.synopsys.com"}
+	if !reflect.DeepEqual(descriptions, want) {
+		t.Errorf("a namespaced deploy registered %v, want %v", descriptions, want)
+	}
+}
+
+func TestDeleteOperatorOnCleanupKeepsExistingObjects(t *testing.T) {
+	f := &Framework{BaseName: "test"}
+	existed := map[clusterObject]bool{
+		{kind: "namespace", name: "synopsys-operator"}:                  true,
+		{kind: "customresourcedefinition", name: "alerts.synopsys.com"}: true,
+	}
+	f.deleteOperatorOnCleanup(utils.DeployOptions{EnabledResources: []utils.Resource{utils.AlertResource, utils.BlackDuckResource}}, existed)
+	descriptions := []string{}
+	for _, c := range f.cleanups {
+		descriptions = append(descriptions, c.description)
+	}
+	want := []string{"delete cluster role synopsys-operator-admin", "delete cluster role binding synopsys-operator-admin", "delete crd blackducks.synopsys.com"}
+	if !reflect.DeepEqual(descriptions, want) {
+		t.Errorf("a deploy over an existing operator registered %v, want %v", descriptions, want)
+	}
+}
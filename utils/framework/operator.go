@@ -0,0 +1,173 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"time"
+
+	utils "github.com/blackducksoftware/cloud-native-tests/utils"
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	crutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/cr"
+	namespaceutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/namespace"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// OperatorAdmin is the name of the ClusterRole and ClusterRoleBinding of a cluster scoped operator
+const OperatorAdmin = "synopsys-operator-admin"
+
+// Schema returns the custom resource schema of resource
+func Schema(resource utils.Resource) schema.GroupVersionResource {
+	switch resource {
+	case utils.AlertResource:
+		return crutils.GetAlertSchema()
+	case utils.BlackDuckResource:
+		return crutils.GetBlackDuckSchema()
+	case utils.OpsSightResource:
+		return crutils.GetOpssightSchema()
+	}
+	return schema.GroupVersionResource{}
+}
+
+// CRDName returns the name of the custom resource definition of resource, e.g. alerts.synopsys.com
+func CRDName(resource utils.Resource) string {
+	gvr := Schema(resource)
+	return fmt.Sprintf("%s.%s", gvr.Resource, gvr.Group)
+}
+
+// DeployOperator runs "synopsysctl deploy" with options and registers the removal of everything it
+// creates, even if the command fails part way
+func (f *Framework) DeployOperator(options utils.DeployOptions) (*utils.ExecResult, error) {
+	objects := operatorObjects(options)
+	existed, err := f.existingObjects(objects)
+	if err != nil {
+		return nil, err
+	}
+	f.deleteOperatorOnCleanup(options, existed)
+	result, err := f.Synopsysctl.RunCommandWithTimeout(config.Get().Timeouts.Command.Duration, options)
+	if markErr := f.markCreatedObjects(objects, existed); markErr != nil {
+		f.log().Warnf("%v", markErr)
+	}
+	return result, err
+}
+
+// clusterObject is a cluster scoped object "synopsysctl deploy" may create
+type clusterObject struct {
+	kind string
+	name string
+}
+
+func (o clusterObject) String() string {
+	return fmt.Sprintf("%s %s", o.kind, o.name)
+}
+
+// operatorObjects returns the cluster scoped objects "synopsysctl deploy" may create for options
+func operatorObjects(options utils.DeployOptions) []clusterObject {
+	objects := []clusterObject{{kind: "clusterrole", name: OperatorAdmin}, {kind: "clusterrolebinding", name: OperatorAdmin}}
+	if options.Namespace == "" {
+		objects = append(objects, clusterObject{kind: "namespace", name: config.Get().Namespaces.Operator})
+	}
+	for _, r := range options.EnabledResources {
+		objects = append(objects, clusterObject{kind: "customresourcedefinition", name: CRDName(r)})
+	}
+	return objects
+}
+
+// existingObjects returns which of objects exist
+func (f *Framework) existingObjects(objects []clusterObject) (map[clusterObject]bool, error) {
+	existing := map[clusterObject]bool{}
+	for _, o := range objects {
+		var err error
+		switch o.kind {
+		case "clusterrole":
+			_, err = f.KubeClient.RbacV1().ClusterRoles().Get(o.name, metav1.GetOptions{})
+		case "clusterrolebinding":
+			_, err = f.KubeClient.RbacV1().ClusterRoleBindings().Get(o.name, metav1.GetOptions{})
+		case "namespace":
+			_, err = f.KubeClient.CoreV1().Namespaces().Get(o.name, metav1.GetOptions{})
+		case "customresourcedefinition":
+			_, err = f.APIExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(o.name, metav1.GetOptions{})
+		}
+		if apierrs.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %v", o, err)
+		}
+		existing[o] = true
+	}
+	return existing, nil
+}
+
+// markCreatedObjects labels the objects that did not exist before as created by the suites, with the
+// namespace TTL, so cnt-gc collects them after an aborted run and leaves those of other installs alone
+func (f *Framework) markCreatedObjects(objects []clusterObject, existed map[clusterObject]bool) error {
+	patch := namespaceutils.OwnershipPatch(f.BaseName, config.Get().Namespaces.TTL.Duration, time.Now())
+	for _, o := range objects {
+		if existed[o] {
+			continue
+		}
+		var err error
+		switch o.kind {
+		case "clusterrole":
+			_, err = f.KubeClient.RbacV1().ClusterRoles().Patch(o.name, types.MergePatchType, patch)
+		case "clusterrolebinding":
+			_, err = f.KubeClient.RbacV1().ClusterRoleBindings().Patch(o.name, types.MergePatchType, patch)
+		case "namespace":
+			_, err = f.KubeClient.CoreV1().Namespaces().Patch(o.name, types.MergePatchType, patch)
+		case "customresourcedefinition":
+			_, err = f.APIExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Patch(o.name, types.MergePatchType, patch)
+		}
+		if err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to label %s as created by the suites: %v", o, err)
+		}
+	}
+	return nil
+}
+
+// DeleteOperatorOnCleanup removes what "synopsysctl deploy" creates for options after the current spec:
+// the CRDs of the enabled resources, the synopsys-operator-admin ClusterRole and ClusterRoleBinding and
+// the default operator namespace. The RBAC is removed for namespaced deploys too, so a leftover never
+// fails the preflight checks of the next run. A namespace passed in options is left to whoever created it
+func (f *Framework) DeleteOperatorOnCleanup(options utils.DeployOptions) {
+	f.deleteOperatorOnCleanup(options, nil)
+}
+
+// deleteOperatorOnCleanup is DeleteOperatorOnCleanup, except that the operator namespace and CRDs in
+// existed are kept: they belong to an operator that was installed before the spec, and deleting a CRD
+// deletes all of its custom resources
+func (f *Framework) deleteOperatorOnCleanup(options utils.DeployOptions, existed map[clusterObject]bool) {
+	namespace := clusterObject{kind: "namespace", name: config.Get().Namespaces.Operator}
+	if options.Namespace == "" && !existed[namespace] {
+		f.DeleteNamespaceOnCleanup(namespace.name)
+	}
+	f.DeleteClusterRoleOnCleanup(OperatorAdmin)
+	f.DeleteClusterRoleBindingOnCleanup(OperatorAdmin)
+	for _, r := range options.EnabledResources {
+		if crd := (clusterObject{kind: "customresourcedefinition", name: CRDName(r)}); !existed[crd] {
+			f.DeleteCRDOnCleanup(crd.name)
+		}
+	}
+}
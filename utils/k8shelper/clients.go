@@ -0,0 +1,147 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package k8shelper
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ClientOptions select a cluster and how to talk to it. The zero value is the in-cluster config when
+// running in a pod and otherwise the current context of $KUBECONFIG or ~/.kube/config
+type ClientOptions struct {
+	// Kubeconfig is the kubeconfig file; empty uses $KUBECONFIG or ~/.kube/config
+	Kubeconfig string
+	// Context is the kube context; empty uses the current context
+	Context string
+	// Impersonate is the user to act as
+	Impersonate string
+	// QPS and Burst limit the requests to the API server; zero uses the client-go defaults
+	QPS   float32
+	Burst int
+	// Timeout bounds every request; zero does not
+	Timeout               time.Duration
+	InsecureSkipTLSVerify bool
+}
+
+// ClientOptionsFor returns the options of the cluster of the suite configuration
+func ClientOptionsFor(cluster config.Cluster) ClientOptions {
+	return ClientOptions{
+		Kubeconfig:  cluster.Kubeconfig,
+		Context:     cluster.Context,
+		Impersonate: cluster.Impersonate,
+		QPS:         cluster.QPS,
+		Burst:       cluster.Burst,
+		Timeout:     cluster.RequestTimeout.Duration,
+	}
+}
+
+// Clients are the clients of one cluster
+type Clients struct {
+	RestConfig         *rest.Config
+	KubeClient         *kubernetes.Clientset
+	DynamicClient      dynamic.Interface
+	APIExtensionClient *apiextensionsclient.Clientset
+}
+
+// ClientFactory builds the clients of clusters and keeps them, so every suite or framework that
+// targets the same cluster with the same options shares them
+type ClientFactory struct {
+	lock    sync.Mutex
+	clients map[ClientOptions]*Clients
+}
+
+// NewClientFactory returns an empty ClientFactory
+func NewClientFactory() *ClientFactory {
+	return &ClientFactory{clients: map[ClientOptions]*Clients{}}
+}
+
+// DefaultClientFactory is shared by the frameworks of a test process
+var DefaultClientFactory = NewClientFactory()
+
+// RestConfig returns a new rest config for options
+func (f *ClientFactory) RestConfig(options ClientOptions) (*rest.Config, error) {
+	var rc *rest.Config
+	var err error
+	if options.Kubeconfig == "" && options.Context == "" {
+		rc, err = rest.InClusterConfig()
+	}
+	if rc == nil {
+		rules := clientcmd.NewDefaultClientConfigLoadingRules()
+		rules.ExplicitPath = options.Kubeconfig
+		overrides := &clientcmd.ConfigOverrides{CurrentContext: options.Context}
+		overrides.ClusterInfo.InsecureSkipTLSVerify = options.InsecureSkipTLSVerify
+		rc, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the kube config of context %q: %v", options.Context, err)
+		}
+	}
+	if options.Impersonate != "" {
+		rc.Impersonate.UserName = options.Impersonate
+	}
+	if options.QPS > 0 {
+		rc.QPS = options.QPS
+	}
+	if options.Burst > 0 {
+		rc.Burst = options.Burst
+	}
+	if options.Timeout > 0 {
+		rc.Timeout = options.Timeout
+	}
+	return rc, nil
+}
+
+// Clients returns the clients for options, building them the first time
+func (f *ClientFactory) Clients(options ClientOptions) (*Clients, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if c, ok := f.clients[options]; ok {
+		return c, nil
+	}
+	rc, err := f.RestConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	kc, err := GetKubeClient(rc)
+	if err != nil {
+		return nil, err
+	}
+	dc, err := GetDynamicClient(rc)
+	if err != nil {
+		return nil, err
+	}
+	aec, err := apiextensionsclient.NewForConfig(rc)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the api extension client: %v", err)
+	}
+	c := &Clients{RestConfig: rc, KubeClient: kc, DynamicClient: dc, APIExtensionClient: aec}
+	f.clients[options] = c
+	return c, nil
+}
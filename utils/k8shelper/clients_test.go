@@ -0,0 +1,97 @@
+package k8shelper
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: blackduck
+  cluster:
+    server: https://blackduck.example.com
+- name: opssight
+  cluster:
+    server: https://opssight.example.com
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: blackduck
+  context:
+    cluster: blackduck
+    user: admin
+- name: opssight
+  context:
+    cluster: opssight
+    user: admin
+current-context: blackduck
+`
+
+func writeKubeconfig(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(path, []byte(testKubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRestConfig(t *testing.T) {
+	path := writeKubeconfig(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	f := NewClientFactory()
+
+	rc, err := f.RestConfig(ClientOptions{Kubeconfig: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Host != "https://blackduck.example.com" {
+		t.Errorf("expected the current context, got %s", rc.Host)
+	}
+
+	rc, err = f.RestConfig(ClientOptions{Kubeconfig: path, Context: "opssight", Impersonate: "tester", QPS: 50, Burst: 100, Timeout: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Host != "https://opssight.example.com" || rc.Impersonate.UserName != "tester" || rc.QPS != 50 || rc.Burst != 100 || rc.Timeout != time.Minute {
+		t.Errorf("unexpected rest config %+v", rc)
+	}
+
+	if _, err := f.RestConfig(ClientOptions{Kubeconfig: path, Context: "alert"}); err == nil {
+		t.Error("expected an error for a missing context")
+	}
+}
+
+func TestClientsAreCachedPerContext(t *testing.T) {
+	path := writeKubeconfig(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	f := NewClientFactory()
+
+	blackDuck, err := f.Clients(ClientOptions{Kubeconfig: path, Context: "blackduck"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := f.Clients(ClientOptions{Kubeconfig: path, Context: "blackduck"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opsSight, err := f.Clients(ClientOptions{Kubeconfig: path, Context: "opssight"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blackDuck != again {
+		t.Error("expected the clients of a context to be reused")
+	}
+	if blackDuck == opsSight || opsSight.RestConfig.Host != "https://opssight.example.com" {
+		t.Error("expected separate clients for every context")
+	}
+}
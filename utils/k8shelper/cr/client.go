@@ -0,0 +1,425 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package cr
+
+import (
+	"fmt"
+
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// Client reads and writes Alerts, BlackDucks and OpsSights through the dynamic client. Update and
+// UpdateStatus keep the stored fields the structs do not model; use Patch to change a single field
+type Client struct {
+	dc dynamic.Interface
+}
+
+// NewClient returns a Client that uses dc
+func NewClient(dc dynamic.Interface) *Client {
+	return &Client{dc: dc}
+}
+
+// NewClientForConfig returns a Client for the cluster of rc
+func NewClientForConfig(rc *rest.Config) (*Client, error) {
+	dc, err := k8sutils.GetDynamicClient(rc)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(dc), nil
+}
+
+// Alerts returns a client of the Alerts in namespace
+func (c *Client) Alerts(namespace string) *AlertClient {
+	return &AlertClient{c.resource(GetAlertSchema(), AlertKind, namespace)}
+}
+
+// BlackDucks returns a client of the BlackDucks in namespace
+func (c *Client) BlackDucks(namespace string) *BlackDuckClient {
+	return &BlackDuckClient{c.resource(GetBlackDuckSchema(), BlackDuckKind, namespace)}
+}
+
+// OpsSights returns a client of the OpsSights in namespace
+func (c *Client) OpsSights(namespace string) *OpsSightClient {
+	return &OpsSightClient{c.resource(GetOpssightSchema(), OpsSightKind, namespace)}
+}
+
+func (c *Client) resource(gvr schema.GroupVersionResource, kind, namespace string) resourceClient {
+	return resourceClient{
+		client: c.dc.Resource(gvr).Namespace(namespace),
+		gvk:    gvr.GroupVersion().WithKind(kind),
+	}
+}
+
+// resourceClient converts between the typed objects and the dynamic client
+type resourceClient struct {
+	client dynamic.ResourceInterface
+	gvk    schema.GroupVersionKind
+}
+
+func (c resourceClient) toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	u, err := ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	if u.GetKind() == "" {
+		u.SetGroupVersionKind(c.gvk)
+	}
+	return u, nil
+}
+
+func (c resourceClient) into(u *unstructured.Unstructured, err error, obj runtime.Object) error {
+	if err != nil {
+		return err
+	}
+	return FromUnstructured(u, obj)
+}
+
+func (c resourceClient) get(name string, obj runtime.Object) error {
+	u, err := c.client.Get(name, metav1.GetOptions{})
+	return c.into(u, err, obj)
+}
+
+func (c resourceClient) list(options metav1.ListOptions, list runtime.Object) error {
+	l, err := c.client.List(options)
+	if err != nil {
+		return err
+	}
+	return FromUnstructured(l, list)
+}
+
+func (c resourceClient) create(in, out runtime.Object) error {
+	u, err := c.toUnstructured(in)
+	if err != nil {
+		return err
+	}
+	u, err = c.client.Create(u, metav1.CreateOptions{})
+	return c.into(u, err, out)
+}
+
+func (c resourceClient) update(in, out runtime.Object) error {
+	u, err := c.withUnmodeled(in, out)
+	if err != nil {
+		return err
+	}
+	u, err = c.client.Update(u, metav1.UpdateOptions{})
+	return c.into(u, err, out)
+}
+
+func (c resourceClient) updateStatus(in, out runtime.Object) error {
+	u, err := c.withUnmodeled(in, out)
+	if err != nil {
+		return err
+	}
+	u, err = c.client.UpdateStatus(u, metav1.UpdateOptions{})
+	return c.into(u, err, out)
+}
+
+// withUnmodeled converts in to unstructured and adds the fields of the stored object that the type of
+// empty, an empty object of the type of in, does not model, so an update does not drop them
+func (c resourceClient) withUnmodeled(in, empty runtime.Object) (*unstructured.Unstructured, error) {
+	u, err := c.toUnstructured(in)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := c.client.Get(u.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	modeled := empty.DeepCopyObject()
+	if modeled == nil {
+		return nil, fmt.Errorf("failed to copy %T", empty)
+	}
+	if err := FromUnstructured(stored, modeled); err != nil {
+		return nil, err
+	}
+	m, err := ToUnstructured(modeled)
+	if err != nil {
+		return nil, err
+	}
+	addUnmodeled(u.Object, stored.Object, m.Object)
+	return u, nil
+}
+
+// addUnmodeled copies the fields of stored that are not in modeled, the part of stored that the typed
+// object models, into obj. Nested objects are merged; lists are replaced as a whole
+func addUnmodeled(obj, stored, modeled map[string]interface{}) {
+	for key, value := range stored {
+		modeledValue, ok := modeled[key]
+		if !ok {
+			if _, set := obj[key]; !set {
+				obj[key] = value
+			}
+			continue
+		}
+		storedMap, isMap := value.(map[string]interface{})
+		modeledMap, modeledIsMap := modeledValue.(map[string]interface{})
+		objMap, objIsMap := obj[key].(map[string]interface{})
+		if isMap && modeledIsMap && objIsMap {
+			addUnmodeled(objMap, storedMap, modeledMap)
+		}
+	}
+}
+
+func (c resourceClient) patch(name string, pt types.PatchType, data []byte, out runtime.Object) error {
+	u, err := c.client.Patch(name, pt, data, metav1.PatchOptions{})
+	return c.into(u, err, out)
+}
+
+func (c resourceClient) delete(name string, options *metav1.DeleteOptions) error {
+	return c.client.Delete(name, options)
+}
+
+// watch converts the objects of the events to the type returned by newObj. An object that cannot be
+// converted becomes an Error event
+func (c resourceClient) watch(options metav1.ListOptions, newObj func() runtime.Object) (watch.Interface, error) {
+	w, err := c.client.Watch(options)
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+		u, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			return event, true
+		}
+		obj := newObj()
+		if err := FromUnstructured(u, obj); err != nil {
+			status := &metav1.Status{Status: metav1.StatusFailure, Message: fmt.Sprintf("failed to convert %s %s: %v", c.gvk.Kind, u.GetName(), err)}
+			return watch.Event{Type: watch.Error, Object: status}, true
+		}
+		return watch.Event{Type: event.Type, Object: obj}, true
+	}), nil
+}
+
+// AlertClient reads and writes the Alerts of a namespace
+type AlertClient struct {
+	resourceClient
+}
+
+// Get returns the Alert name
+func (c *AlertClient) Get(name string) (*Alert, error) {
+	out := &Alert{}
+	if err := c.get(name, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List returns the Alerts selected by options
+func (c *AlertClient) List(options metav1.ListOptions) (*AlertList, error) {
+	out := &AlertList{}
+	if err := c.list(options, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create creates alert
+func (c *AlertClient) Create(alert *Alert) (*Alert, error) {
+	out := &Alert{}
+	if err := c.create(alert, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update replaces alert
+func (c *AlertClient) Update(alert *Alert) (*Alert, error) {
+	out := &Alert{}
+	if err := c.update(alert, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateStatus replaces the status of alert
+func (c *AlertClient) UpdateStatus(alert *Alert) (*Alert, error) {
+	out := &Alert{}
+	if err := c.updateStatus(alert, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Patch patches the Alert name
+func (c *AlertClient) Patch(name string, pt types.PatchType, data []byte) (*Alert, error) {
+	out := &Alert{}
+	if err := c.patch(name, pt, data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete deletes the Alert name
+func (c *AlertClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.delete(name, options)
+}
+
+// Watch watches the Alerts selected by options; the events carry *Alert objects
+func (c *AlertClient) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	return c.watch(options, func() runtime.Object { return &Alert{} })
+}
+
+// BlackDuckClient reads and writes the BlackDucks of a namespace
+type BlackDuckClient struct {
+	resourceClient
+}
+
+// Get returns the BlackDuck name
+func (c *BlackDuckClient) Get(name string) (*BlackDuck, error) {
+	out := &BlackDuck{}
+	if err := c.get(name, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List returns the BlackDucks selected by options
+func (c *BlackDuckClient) List(options metav1.ListOptions) (*BlackDuckList, error) {
+	out := &BlackDuckList{}
+	if err := c.list(options, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create creates blackDuck
+func (c *BlackDuckClient) Create(blackDuck *BlackDuck) (*BlackDuck, error) {
+	out := &BlackDuck{}
+	if err := c.create(blackDuck, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update replaces blackDuck
+func (c *BlackDuckClient) Update(blackDuck *BlackDuck) (*BlackDuck, error) {
+	out := &BlackDuck{}
+	if err := c.update(blackDuck, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateStatus replaces the status of blackDuck
+func (c *BlackDuckClient) UpdateStatus(blackDuck *BlackDuck) (*BlackDuck, error) {
+	out := &BlackDuck{}
+	if err := c.updateStatus(blackDuck, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Patch patches the BlackDuck name
+func (c *BlackDuckClient) Patch(name string, pt types.PatchType, data []byte) (*BlackDuck, error) {
+	out := &BlackDuck{}
+	if err := c.patch(name, pt, data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete deletes the BlackDuck name
+func (c *BlackDuckClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.delete(name, options)
+}
+
+// Watch watches the BlackDucks selected by options; the events carry *BlackDuck objects
+func (c *BlackDuckClient) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	return c.watch(options, func() runtime.Object { return &BlackDuck{} })
+}
+
+// OpsSightClient reads and writes the OpsSights of a namespace
+type OpsSightClient struct {
+	resourceClient
+}
+
+// Get returns the OpsSight name
+func (c *OpsSightClient) Get(name string) (*OpsSight, error) {
+	out := &OpsSight{}
+	if err := c.get(name, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List returns the OpsSights selected by options
+func (c *OpsSightClient) List(options metav1.ListOptions) (*OpsSightList, error) {
+	out := &OpsSightList{}
+	if err := c.list(options, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create creates opsSight
+func (c *OpsSightClient) Create(opsSight *OpsSight) (*OpsSight, error) {
+	out := &OpsSight{}
+	if err := c.create(opsSight, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update replaces opsSight
+func (c *OpsSightClient) Update(opsSight *OpsSight) (*OpsSight, error) {
+	out := &OpsSight{}
+	if err := c.update(opsSight, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateStatus replaces the status of opsSight
+func (c *OpsSightClient) UpdateStatus(opsSight *OpsSight) (*OpsSight, error) {
+	out := &OpsSight{}
+	if err := c.updateStatus(opsSight, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Patch patches the OpsSight name
+func (c *OpsSightClient) Patch(name string, pt types.PatchType, data []byte) (*OpsSight, error) {
+	out := &OpsSight{}
+	if err := c.patch(name, pt, data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete deletes the OpsSight name
+func (c *OpsSightClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.delete(name, options)
+}
+
+// Watch watches the OpsSights selected by options; the events carry *OpsSight objects
+func (c *OpsSightClient) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	return c.watch(options, func() runtime.Object { return &OpsSight{} })
+}
@@ -0,0 +1,134 @@
+package cr
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestFromUnstructured(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "synopsys.com/v1",
+		"kind":       "Blackduck",
+		"metadata":   map[string]interface{}{"name": "bd", "namespace": "bd"},
+		"spec": map[string]interface{}{
+			"size":              "small",
+			"exposeService":     "LOADBALANCER",
+			"persistentStorage": true,
+			"pvc":               []interface{}{map[string]interface{}{"name": "blackduck-postgres", "size": "150Gi"}},
+			"futureField":       "ignored",
+		},
+		"status": map[string]interface{}{"state": "Running", "fqdn": "bd.example.com"},
+	}}
+	bd := &BlackDuck{}
+	if err := FromUnstructured(u, bd); err != nil {
+		t.Fatal(err)
+	}
+	if bd.Name != "bd" || bd.Spec.Size != "small" || bd.Spec.ExposeService != "LOADBALANCER" || !bd.Spec.PersistentStorage {
+		t.Errorf("unexpected black duck %+v", bd)
+	}
+	if len(bd.Spec.PVC) != 1 || bd.Spec.PVC[0].Size != "150Gi" || bd.Status.State != "Running" {
+		t.Errorf("unexpected black duck %+v", bd)
+	}
+}
+
+func TestAlertClient(t *testing.T) {
+	c := NewClient(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()))
+	alerts := c.Alerts("alt")
+
+	w, err := alerts.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	created, err := alerts.Create(&Alert{
+		ObjectMeta: metav1.ObjectMeta{Name: "alt", Namespace: "alt"},
+		Spec:       AlertSpec{AlertImage: "docker.io/blackducksoftware/blackduck-alert:4.0.0", ExposeService: "NODEPORT", DesiredState: "Running"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Kind != AlertKind || created.APIVersion != "synopsys.com/v1" {
+		t.Errorf("the type meta was not set: %+v", created.TypeMeta)
+	}
+
+	select {
+	case event := <-w.ResultChan():
+		if a, ok := event.Object.(*Alert); event.Type != watch.Added || !ok || a.Spec.ExposeService != "NODEPORT" {
+			t.Errorf("unexpected event %v %#v", event.Type, event.Object)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no watch event was received")
+	}
+
+	created.Spec.DesiredState = "Stopped"
+	if _, err := alerts.Update(created); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := alerts.Patch("alt", types.MergePatchType, []byte(`{"spec":{"persistentStorage":true}}`)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := alerts.Get("alt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Spec.DesiredState != "Stopped" || !got.Spec.PersistentStorage || got.Spec.AlertImage != "docker.io/blackducksoftware/blackduck-alert:4.0.0" {
+		t.Errorf("unexpected alert %+v", got.Spec)
+	}
+
+	if err := alerts.Delete("alt", &metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := alerts.Get("alt"); err == nil {
+		t.Error("the alert was not deleted")
+	}
+}
+
+func TestUpdateKeepsUnmodeledFields(t *testing.T) {
+	stored := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "synopsys.com/v1",
+		"kind":       "Blackduck",
+		"metadata":   map[string]interface{}{"name": "bd", "namespace": "bd"},
+		"spec":       map[string]interface{}{"size": "small", "licenseKey": "key", "futureField": "kept"},
+		"futureTop":  map[string]interface{}{"a": "b"},
+	}}
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), stored)
+	blackDucks := NewClient(dc).BlackDucks("bd")
+	bd, err := blackDucks.Get("bd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bd.Spec.Size = "medium"
+	bd.Spec.LicenseKey = ""
+	if _, err := blackDucks.Update(bd); err != nil {
+		t.Fatal(err)
+	}
+	u, err := dc.Resource(GetBlackDuckSchema()).Namespace("bd").Get("bd", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := u.Object["spec"].(map[string]interface{})
+	if spec["size"] != "medium" || spec["futureField"] != "kept" || u.Object["futureTop"] == nil {
+		t.Errorf("unexpected black duck %v", u.Object)
+	}
+	if _, ok := spec["licenseKey"]; ok {
+		t.Errorf("expected the cleared license key to be removed, got %v", spec)
+	}
+}
+
+func TestClientReturnsNilOnError(t *testing.T) {
+	alerts := NewClient(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())).Alerts("alt")
+	if a, err := alerts.Get("missing"); err == nil || a != nil {
+		t.Errorf("expected nil and an error, got %v and %v", a, err)
+	}
+	if a, err := alerts.Update(&Alert{ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "alt"}}); err == nil || a != nil {
+		t.Errorf("expected nil and an error, got %v and %v", a, err)
+	}
+}
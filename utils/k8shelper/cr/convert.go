@@ -0,0 +1,104 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package cr
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Kinds of the synopsys.com/v1 custom resources
+const (
+	AlertKind     = "Alert"
+	BlackDuckKind = "Blackduck"
+	OpsSightKind  = "OpsSight"
+)
+
+// ToUnstructured converts a typed custom resource such as an *Alert to unstructured
+func ToUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// FromUnstructured converts u into obj, a typed custom resource or list such as an *Alert or *AlertList.
+// Fields obj does not model are ignored
+func FromUnstructured(u runtime.Unstructured, obj runtime.Object) error {
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), obj)
+}
+
+// deepCopyInto copies in to out through JSON, which is good enough for the test harness and saves
+// generating deep copy functions for every nested type
+func deepCopyInto(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to copy %T: %v", in, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to copy %T: %v", in, err)
+	}
+	return nil
+}
+
+// deepCopyObject copies in to out and returns out. runtime.Object has no way to report an error and
+// its users do not expect nil, so it panics if in cannot be copied, which the JSON types never cause
+func deepCopyObject(in interface{}, out runtime.Object) runtime.Object {
+	if err := deepCopyInto(in, out); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *Alert) DeepCopyObject() runtime.Object {
+	return deepCopyObject(in, &Alert{})
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *AlertList) DeepCopyObject() runtime.Object {
+	return deepCopyObject(in, &AlertList{})
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *BlackDuck) DeepCopyObject() runtime.Object {
+	return deepCopyObject(in, &BlackDuck{})
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *BlackDuckList) DeepCopyObject() runtime.Object {
+	return deepCopyObject(in, &BlackDuckList{})
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *OpsSight) DeepCopyObject() runtime.Object {
+	return deepCopyObject(in, &OpsSight{})
+}
+
+// DeepCopyObject implements runtime.Object
+func (in *OpsSightList) DeepCopyObject() runtime.Object {
+	return deepCopyObject(in, &OpsSightList{})
+}
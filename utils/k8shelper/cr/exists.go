@@ -0,0 +1,115 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package cr
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+)
+
+// CRExists returns whether the custom resource namespace/name of gvr exists. An empty namespace names
+// a cluster scoped custom resource. restcli can be the REST client of any clientset of the cluster,
+// e.g. kubeClient.RESTClient(), because the request uses an absolute path
+func CRExists(restcli rest.Interface, gvr schema.GroupVersionResource, namespace, name string) (bool, error) {
+	path := []string{"/apis", gvr.Group, gvr.Version, gvr.Resource, name}
+	if namespace != "" {
+		path = []string{"/apis", gvr.Group, gvr.Version, "namespaces", namespace, gvr.Resource, name}
+	}
+	err := restcli.Get().AbsPath(path...).Do().Error()
+	switch {
+	case err == nil:
+		return true, nil
+	case apierrs.IsNotFound(err):
+		return false, nil
+	}
+	return false, err
+}
+
+// AlertCRExists returns whether the Alert namespace/name exists
+func AlertCRExists(restcli rest.Interface, namespace, name string) (bool, error) {
+	return CRExists(restcli, GetAlertSchema(), namespace, name)
+}
+
+// BlackDuckCRExists returns whether the Black Duck namespace/name exists
+func BlackDuckCRExists(restcli rest.Interface, namespace, name string) (bool, error) {
+	return CRExists(restcli, GetBlackDuckSchema(), namespace, name)
+}
+
+// OpsSightCRExists returns whether the OpsSight namespace/name exists
+func OpsSightCRExists(restcli rest.Interface, namespace, name string) (bool, error) {
+	return CRExists(restcli, GetOpssightSchema(), namespace, name)
+}
+
+// WaitForCR waits until the custom resource namespace/name of gvr appears (exist == true), or disappears
+// (exist == false). Transient API errors are retried, other errors stop the wait.
+// A zero interval or timeout uses the configured poll interval or CR timeout
+func WaitForCR(restcli rest.Interface, gvr schema.GroupVersionResource, namespace, name string, exist bool, interval, timeout time.Duration) error {
+	timeouts := config.Get().Timeouts
+	if interval == 0 {
+		interval = timeouts.Poll.Duration
+	}
+	if timeout == 0 {
+		timeout = timeouts.CR.Duration
+	}
+	var lastErr error
+	err := wait.PollImmediate(interval, timeout, func() (bool, error) {
+		exists, err := CRExists(restcli, gvr, namespace, name)
+		switch {
+		case err == nil:
+			return exists == exist, nil
+		case k8sutils.IsRetryableAPIError(err):
+			lastErr = err
+			return false, nil
+		}
+		return false, err
+	})
+	if err == wait.ErrWaitTimeout && lastErr != nil {
+		err = fmt.Errorf("%v, last error: %v", err, lastErr)
+	}
+	if err != nil {
+		stateMsg := map[bool]string{true: "to appear", false: "to disappear"}
+		return fmt.Errorf("error waiting for %s %s/%s %s: %v", gvr.Resource, namespace, name, stateMsg[exist], err)
+	}
+	return nil
+}
+
+// WaitForAlertCR waits until the Alert namespace/name appears (exist == true), or disappears (exist == false)
+func WaitForAlertCR(restcli rest.Interface, namespace, name string, exist bool, interval, timeout time.Duration) error {
+	return WaitForCR(restcli, GetAlertSchema(), namespace, name, exist, interval, timeout)
+}
+
+// WaitForBlackDuckCR waits until the Black Duck namespace/name appears (exist == true), or disappears (exist == false)
+func WaitForBlackDuckCR(restcli rest.Interface, namespace, name string, exist bool, interval, timeout time.Duration) error {
+	return WaitForCR(restcli, GetBlackDuckSchema(), namespace, name, exist, interval, timeout)
+}
+
+// WaitForOpsSightCR waits until the OpsSight namespace/name appears (exist == true), or disappears (exist == false)
+func WaitForOpsSightCR(restcli rest.Interface, namespace, name string, exist bool, interval, timeout time.Duration) error {
+	return WaitForCR(restcli, GetOpssightSchema(), namespace, name, exist, interval, timeout)
+}
@@ -0,0 +1,92 @@
+package cr
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest/fake"
+)
+
+// restClient answers every request with the next status code of codes, repeating the last one
+func restClient(t *testing.T, codes ...int) *fake.RESTClient {
+	return &fake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/apis/synopsys.com/v1/namespaces/alt/alerts/alt" {
+				t.Errorf("unexpected path %s", req.URL.Path)
+			}
+			code := codes[0]
+			if len(codes) > 1 {
+				codes = codes[1:]
+			}
+			body := `{"apiVersion":"synopsys.com/v1","kind":"Alert","metadata":{"name":"alt"}}`
+			if code != http.StatusOK {
+				reason := map[int]string{http.StatusNotFound: "NotFound", http.StatusForbidden: "Forbidden", http.StatusInternalServerError: "InternalError"}[code]
+				body = fmt.Sprintf(`{"apiVersion":"v1","kind":"Status","status":"Failure","code":%d,"reason":%q,"message":"%s"}`, code, reason, http.StatusText(code))
+			}
+			return &http.Response{
+				StatusCode: code,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			}, nil
+		}),
+	}
+}
+
+func TestAlertCRExists(t *testing.T) {
+	for _, tc := range []struct {
+		code   int
+		exists bool
+		err    bool
+	}{
+		{http.StatusOK, true, false},
+		{http.StatusNotFound, false, false},
+		{http.StatusForbidden, false, true},
+	} {
+		exists, err := AlertCRExists(restClient(t, tc.code), "alt", "alt")
+		if exists != tc.exists || (err != nil) != tc.err {
+			t.Errorf("%d: expected %v and an error %v, got %v and %v", tc.code, tc.exists, tc.err, exists, err)
+		}
+	}
+}
+
+func TestCRExistsClusterScoped(t *testing.T) {
+	restcli := &fake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/apis/synopsys.com/v1/alerts/alt" {
+				t.Errorf("unexpected path %s", req.URL.Path)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"apiVersion":"synopsys.com/v1","kind":"Alert","metadata":{"name":"alt"}}`)),
+			}, nil
+		}),
+	}
+	if exists, err := AlertCRExists(restcli, "", "alt"); !exists || err != nil {
+		t.Errorf("expected the alert to exist, got %v and %v", exists, err)
+	}
+}
+
+func TestWaitForAlertCR(t *testing.T) {
+	// transient errors are retried
+	restcli := restClient(t, http.StatusNotFound, http.StatusInternalServerError, http.StatusOK)
+	if err := WaitForAlertCR(restcli, "alt", "alt", true, time.Millisecond, time.Second); err != nil {
+		t.Error(err)
+	}
+	if err := WaitForAlertCR(restClient(t, http.StatusOK, http.StatusNotFound), "alt", "alt", false, time.Millisecond, time.Second); err != nil {
+		t.Error(err)
+	}
+	if err := WaitForAlertCR(restClient(t, http.StatusForbidden), "alt", "alt", true, time.Millisecond, time.Second); err == nil {
+		t.Error("a forbidden error did not stop the wait")
+	}
+	if err := WaitForAlertCR(restClient(t, http.StatusNotFound), "alt", "alt", true, time.Millisecond, 50*time.Millisecond); err == nil {
+		t.Error("expected a timeout")
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package cr
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Namespace returns the namespace the custom resource u belongs to: its own namespace or, if it is
+// cluster scoped, spec.namespace
+func Namespace(u *unstructured.Unstructured) string {
+	if ns := u.GetNamespace(); ns != "" {
+		return ns
+	}
+	ns, _, _ := unstructured.NestedString(u.Object, "spec", "namespace")
+	return ns
+}
+
+// ResourceFor returns the client of the custom resources of gvr in namespace, or of the cluster
+// scoped ones if namespace is empty
+func ResourceFor(dc dynamic.Interface, gvr schema.GroupVersionResource, namespace string) dynamic.ResourceInterface {
+	if namespace == "" {
+		return dc.Resource(gvr)
+	}
+	return dc.Resource(gvr).Namespace(namespace)
+}
+
+// ListInNamespace lists the custom resources of gvr that belong to namespace whatever the scope of
+// their crd, see Namespace. It returns a NotFound error if the crd is not installed
+func ListInNamespace(dc dynamic.Interface, gvr schema.GroupVersionResource, namespace string) ([]unstructured.Unstructured, error) {
+	list, err := dc.Resource(gvr).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	items := []unstructured.Unstructured{}
+	for i := range list.Items {
+		if Namespace(&list.Items[i]) == namespace {
+			items = append(items, list.Items[i])
+		}
+	}
+	return items, nil
+}
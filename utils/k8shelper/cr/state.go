@@ -0,0 +1,185 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package cr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+// States the operator writes into status.state
+const (
+	StateRunning   = "Running"
+	StateStopped   = "Stopped"
+	StateDbMigrate = "DbMigrate"
+	StateError     = "Error"
+)
+
+// StateTransition is a status of a custom resource and when it was first observed
+type StateTransition struct {
+	State        string
+	ErrorMessage string
+	Time         time.Time
+}
+
+// StateHistory is every status a custom resource was observed in, oldest first
+type StateHistory []StateTransition
+
+func (h StateHistory) String() string {
+	if len(h) == 0 {
+		return "no status was observed"
+	}
+	states := make([]string, 0, len(h))
+	for _, t := range h {
+		state := t.State
+		if state == "" {
+			state = "<none>"
+		}
+		if t.ErrorMessage != "" {
+			state = fmt.Sprintf("%s (%s)", state, t.ErrorMessage)
+		}
+		states = append(states, fmt.Sprintf("%s at %s", state, t.Time.Format("15:04:05")))
+	}
+	return strings.Join(states, " -> ")
+}
+
+// StateWaitError is returned by WaitForState when the custom resource reports an error or does not reach
+// the wanted state in time
+type StateWaitError struct {
+	Resource  string
+	Namespace string
+	Name      string
+	Wanted    []string
+	// ErrorMessage is status.errorMessage, or describes the Error state if it has none; empty on a timeout
+	ErrorMessage string
+	History      StateHistory
+}
+
+func (e *StateWaitError) Error() string {
+	target := fmt.Sprintf("%s %s/%s", e.Resource, e.Namespace, e.Name)
+	if e.ErrorMessage != "" {
+		return fmt.Sprintf("%s reported an error while waiting for state %s: %s; states: %s", target, strings.Join(e.Wanted, " or "), e.ErrorMessage, e.History)
+	}
+	return fmt.Sprintf("timed out waiting for %s to reach state %s; states: %s", target, strings.Join(e.Wanted, " or "), e.History)
+}
+
+// WaitForState watches the custom resource namespace/name of gvr until status.state is one of states
+// and returns the states it went through. It returns a *StateWaitError as soon as status.errorMessage is
+// set or status.state is Error, unless Error is one of states, or when ctx is done first. namespace is
+// empty for a cluster scoped custom resource
+func WaitForState(ctx context.Context, dc dynamic.Interface, gvr schema.GroupVersionResource, namespace, name string, states ...string) (StateHistory, error) {
+	logging.FromContext(ctx).Debugf("Waiting for %s %s in namespace %s to be in one of the states %v", gvr.Resource, name, namespace, states)
+	client := dc.Resource(gvr).Namespace(namespace)
+	list := func(options metav1.ListOptions) (runtime.Object, error) {
+		return client.List(options)
+	}
+	lw := k8sutils.ListWatch(list, client.Watch, k8sutils.SelectName(name))
+	wanted := map[string]bool{}
+	for _, s := range states {
+		wanted[s] = true
+	}
+	var lock sync.Mutex
+	history := StateHistory{}
+	// every event is recorded, the condition may only see the latest of several quick transitions
+	record := func(obj interface{}) {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return
+		}
+		state, _, _ := unstructured.NestedString(u.Object, "status", "state")
+		msg, _, _ := unstructured.NestedString(u.Object, "status", "errorMessage")
+		lock.Lock()
+		defer lock.Unlock()
+		if last := len(history) - 1; last < 0 || history[last].State != state || history[last].ErrorMessage != msg {
+			history = append(history, StateTransition{State: state, ErrorMessage: msg, Time: time.Now()})
+		}
+	}
+	source := k8sutils.InformerSource{
+		ListerWatcher: lw,
+		Object:        &unstructured.Unstructured{},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    record,
+			UpdateFunc: func(_, obj interface{}) { record(obj) },
+		},
+	}
+	// the key cache.MetaNamespaceKeyFunc gives the custom resource, which has no namespace if it is cluster scoped
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+	stateErr := &StateWaitError{Resource: gvr.Resource, Namespace: namespace, Name: name, Wanted: states}
+	err := k8sutils.WaitForStores(ctx, []k8sutils.InformerSource{source}, func(stores []cache.Store) (bool, error) {
+		obj, exists, err := stores[0].GetByKey(key)
+		if err != nil || !exists {
+			return false, err
+		}
+		u := obj.(*unstructured.Unstructured)
+		state, _, _ := unstructured.NestedString(u.Object, "status", "state")
+		msg, _, _ := unstructured.NestedString(u.Object, "status", "errorMessage")
+		if wanted[state] {
+			return true, nil
+		}
+		if msg == "" && state == StateError {
+			// a failed operator may not explain the error
+			msg = fmt.Sprintf("status.state is %s", StateError)
+		}
+		if msg != "" {
+			stateErr.ErrorMessage = msg
+			return false, stateErr
+		}
+		return false, nil
+	})
+	// WaitForStores has stopped the informer, so history is no longer written
+	stateErr.History = history
+	if err == wait.ErrWaitTimeout {
+		return history, stateErr
+	}
+	return history, err
+}
+
+// WaitForAlertState waits until the status state of the Alert namespace/name is one of states
+func WaitForAlertState(ctx context.Context, dc dynamic.Interface, namespace, name string, states ...string) (StateHistory, error) {
+	return WaitForState(ctx, dc, GetAlertSchema(), namespace, name, states...)
+}
+
+// WaitForBlackDuckState waits until the status state of the Black Duck namespace/name is one of states
+func WaitForBlackDuckState(ctx context.Context, dc dynamic.Interface, namespace, name string, states ...string) (StateHistory, error) {
+	return WaitForState(ctx, dc, GetBlackDuckSchema(), namespace, name, states...)
+}
+
+// WaitForOpsSightState waits until the status state of the OpsSight namespace/name is one of states
+func WaitForOpsSightState(ctx context.Context, dc dynamic.Interface, namespace, name string, states ...string) (StateHistory, error) {
+	return WaitForState(ctx, dc, GetOpssightSchema(), namespace, name, states...)
+}
@@ -0,0 +1,100 @@
+package cr
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func alertWithStatus(state, errorMessage string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "synopsys.com/v1",
+		"kind":       AlertKind,
+		"metadata":   map[string]interface{}{"name": "alt", "namespace": "alt"},
+		"status":     map[string]interface{}{"state": state, "errorMessage": errorMessage},
+	}}
+}
+
+// setStatus replaces the alert with one in state after a short delay, like the operator would
+func setStatus(t *testing.T, dc *dynamicfake.FakeDynamicClient, states ...*unstructured.Unstructured) {
+	go func() {
+		for _, u := range states {
+			time.Sleep(50 * time.Millisecond)
+			if _, err := dc.Resource(GetAlertSchema()).Namespace("alt").Update(u, metav1.UpdateOptions{}); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+}
+
+func TestWaitForAlertState(t *testing.T) {
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), alertWithStatus("Creating", ""))
+	setStatus(t, dc, alertWithStatus(StateDbMigrate, ""), alertWithStatus(StateRunning, ""))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	history, err := WaitForAlertState(ctx, dc, "alt", "alt", StateRunning)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) == 0 || history[0].State != "Creating" || history[len(history)-1].State != StateRunning {
+		t.Errorf("unexpected history %s", history)
+	}
+}
+
+func TestWaitForAlertStateReportsErrors(t *testing.T) {
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), alertWithStatus("Creating", ""))
+	setStatus(t, dc, alertWithStatus(StateError, "unable to create the pvc"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	history, err := WaitForAlertState(ctx, dc, "alt", "alt", StateRunning)
+	stateErr, ok := err.(*StateWaitError)
+	if !ok {
+		t.Fatalf("expected a *StateWaitError, got %v", err)
+	}
+	if stateErr.ErrorMessage != "unable to create the pvc" || len(history) != 2 || !strings.Contains(err.Error(), "Creating at") {
+		t.Errorf("unexpected error %v with history %s", err, history)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	dc = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), alertWithStatus(StateStopped, ""))
+	if _, err := WaitForAlertState(ctx, dc, "alt", "alt", StateRunning); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestWaitForAlertStateErrorWithoutMessage(t *testing.T) {
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), alertWithStatus(StateError, ""))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := WaitForAlertState(ctx, dc, "alt", "alt", StateRunning)
+	stateErr, ok := err.(*StateWaitError)
+	if !ok || stateErr.ErrorMessage != "status.state is Error" || ctx.Err() != nil {
+		t.Fatalf("expected a *StateWaitError before the timeout, got %v", err)
+	}
+
+	if _, err := WaitForAlertState(ctx, dc, "alt", "alt", StateRunning, StateError); err != nil {
+		t.Errorf("expected the wanted Error state to be reached, got %v", err)
+	}
+}
+
+func TestWaitForAlertStateClusterScoped(t *testing.T) {
+	alert := alertWithStatus(StateRunning, "")
+	unstructured.RemoveNestedField(alert.Object, "metadata", "namespace")
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), alert)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	history, err := WaitForAlertState(ctx, dc, "", "alt", StateRunning)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].State != StateRunning {
+		t.Errorf("unexpected history %s", history)
+	}
+}
@@ -0,0 +1,301 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package cr
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The types below model the synopsys.com/v1 custom resources created by synopsysctl 2019.6. Fields the
+// operator added later are not modeled: they are missing from the structs, but Update and UpdateStatus
+// keep the stored values of those fields
+
+// Alert is the synopsys.com/v1 Alert custom resource
+type Alert struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              AlertSpec   `json:"spec"`
+	Status            AlertStatus `json:"status,omitempty"`
+}
+
+// AlertSpec is the spec of an Alert
+type AlertSpec struct {
+	Namespace            string   `json:"namespace,omitempty"`
+	Version              string   `json:"version,omitempty"`
+	AlertImage           string   `json:"alertImage,omitempty"`
+	CfsslImage           string   `json:"cfsslImage,omitempty"`
+	ExposeService        string   `json:"exposeService,omitempty"`
+	StandAlone           *bool    `json:"standAlone,omitempty"`
+	Port                 *int32   `json:"port,omitempty"`
+	EncryptionPassword   string   `json:"EncryptionPassword,omitempty"`
+	EncryptionGlobalSalt string   `json:"EncryptionGlobalSalt,omitempty"`
+	Environs             []string `json:"environs,omitempty"`
+	PersistentStorage    bool     `json:"persistentStorage"`
+	PVCName              string   `json:"pvcName,omitempty"`
+	PVCStorageClass      string   `json:"pvcStorageClass,omitempty"`
+	PVCSize              string   `json:"pvcSize,omitempty"`
+	AlertMemory          string   `json:"alertMemory,omitempty"`
+	CfsslMemory          string   `json:"cfsslMemory,omitempty"`
+	DesiredState         string   `json:"desiredState,omitempty"`
+	ImageRegistries      []string `json:"imageRegistries,omitempty"`
+}
+
+// AlertStatus is the status of an Alert
+type AlertStatus struct {
+	State        string `json:"state,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// AlertList is a list of Alerts
+type AlertList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Alert `json:"items"`
+}
+
+// BlackDuck is the synopsys.com/v1 Blackduck custom resource
+type BlackDuck struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              BlackDuckSpec   `json:"spec"`
+	Status            BlackDuckStatus `json:"status,omitempty"`
+}
+
+// BlackDuckSpec is the spec of a BlackDuck
+type BlackDuckSpec struct {
+	Namespace         string                    `json:"namespace,omitempty"`
+	Size              string                    `json:"size,omitempty"`
+	Version           string                    `json:"version,omitempty"`
+	ExposeService     string                    `json:"exposeService,omitempty"`
+	DbPrototype       string                    `json:"dbPrototype,omitempty"`
+	ExternalPostgres  *PostgresExternalDBConfig `json:"externalPostgres,omitempty"`
+	PVCStorageClass   string                    `json:"pvcStorageClass,omitempty"`
+	LivenessProbes    bool                      `json:"livenessProbes"`
+	ScanType          string                    `json:"scanType,omitempty"`
+	PersistentStorage bool                      `json:"persistentStorage"`
+	PVC               []PVC                     `json:"pvc,omitempty"`
+	CertificateName   string                    `json:"certificateName,omitempty"`
+	Certificate       string                    `json:"certificate,omitempty"`
+	CertificateKey    string                    `json:"certificateKey,omitempty"`
+	ProxyCertificate  string                    `json:"proxyCertificate,omitempty"`
+	AuthCustomCA      string                    `json:"authCustomCa,omitempty"`
+	Type              string                    `json:"type,omitempty"`
+	DesiredState      string                    `json:"desiredState,omitempty"`
+	Environs          []string                  `json:"environs,omitempty"`
+	ImageRegistries   []string                  `json:"imageRegistries,omitempty"`
+	LicenseKey        string                    `json:"licenseKey,omitempty"`
+	AdminPassword     string                    `json:"adminPassword,omitempty"`
+	UserPassword      string                    `json:"userPassword,omitempty"`
+	PostgresPassword  string                    `json:"postgresPassword,omitempty"`
+}
+
+// PostgresExternalDBConfig is the external database of a BlackDuck
+type PostgresExternalDBConfig struct {
+	PostgresHost          string `json:"postgresHost"`
+	PostgresPort          int    `json:"postgresPort"`
+	PostgresAdmin         string `json:"postgresAdmin"`
+	PostgresUser          string `json:"postgresUser"`
+	PostgresSsl           bool   `json:"postgresSsl"`
+	PostgresAdminPassword string `json:"postgresAdminPassword"`
+	PostgresUserPassword  string `json:"postgresUserPassword"`
+}
+
+// PVC is a persistent volume claim of a BlackDuck
+type PVC struct {
+	Name         string `json:"name"`
+	Size         string `json:"size,omitempty"`
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+// BlackDuckStatus is the status of a BlackDuck
+type BlackDuckStatus struct {
+	State         string            `json:"state,omitempty"`
+	IP            string            `json:"ip,omitempty"`
+	Fqdn          string            `json:"fqdn,omitempty"`
+	PVCVolumeName map[string]string `json:"pvcVolumeName,omitempty"`
+	ErrorMessage  string            `json:"errorMessage,omitempty"`
+}
+
+// BlackDuckList is a list of BlackDucks
+type BlackDuckList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BlackDuck `json:"items"`
+}
+
+// OpsSight is the synopsys.com/v1 OpsSight custom resource
+type OpsSight struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              OpsSightSpec   `json:"spec"`
+	Status            OpsSightStatus `json:"status,omitempty"`
+}
+
+// OpsSightSpec is the spec of an OpsSight
+type OpsSightSpec struct {
+	Namespace       string             `json:"namespace,omitempty"`
+	Perceptor       *Perceptor         `json:"perceptor,omitempty"`
+	ScannerPod      *ScannerPod        `json:"scannerPod,omitempty"`
+	Perceiver       *Perceiver         `json:"perceiver,omitempty"`
+	Prometheus      *Prometheus        `json:"prometheus,omitempty"`
+	EnableSkyfire   bool               `json:"enableSkyfire"`
+	Skyfire         *Skyfire           `json:"skyfire,omitempty"`
+	EnableMetrics   bool               `json:"enableMetrics"`
+	DefaultCPU      string             `json:"defaultCpu,omitempty"`
+	DefaultMem      string             `json:"defaultMem,omitempty"`
+	ScannerCPU      string             `json:"scannerCpu,omitempty"`
+	ScannerMem      string             `json:"scannerMem,omitempty"`
+	LogLevel        string             `json:"logLevel,omitempty"`
+	SecretName      string             `json:"secretName,omitempty"`
+	ConfigMapName   string             `json:"configMapName,omitempty"`
+	DesiredState    string             `json:"desiredState,omitempty"`
+	Blackduck       *OpsSightBlackDuck `json:"blackduck,omitempty"`
+	ImageRegistries []string           `json:"imageRegistries,omitempty"`
+}
+
+// Perceptor is the core component of an OpsSight
+type Perceptor struct {
+	Name                           string `json:"name,omitempty"`
+	Image                          string `json:"image,omitempty"`
+	Port                           int    `json:"port,omitempty"`
+	CheckForStalledScansPauseHours int    `json:"checkForStalledScansPauseHours,omitempty"`
+	StalledScanClientTimeoutHours  int    `json:"stalledScanClientTimeoutHours,omitempty"`
+	ModelMetricsPauseSeconds       int    `json:"modelMetricsPauseSeconds,omitempty"`
+	UnknownImagePauseMilliseconds  int    `json:"unknownImagePauseMilliseconds,omitempty"`
+	ClientTimeoutMilliseconds      int    `json:"clientTimeoutMilliseconds,omitempty"`
+	Expose                         string `json:"expose,omitempty"`
+}
+
+// ScannerPod is the scanner and image facade of an OpsSight
+type ScannerPod struct {
+	Name           string       `json:"name,omitempty"`
+	Scanner        *Scanner     `json:"scanner,omitempty"`
+	ImageFacade    *ImageFacade `json:"imageFacade,omitempty"`
+	ReplicaCount   int          `json:"scannerReplicaCount,omitempty"`
+	ImageDirectory string       `json:"imageDirectory,omitempty"`
+}
+
+// Scanner is the scanner container of an OpsSight
+type Scanner struct {
+	Name                 string `json:"name,omitempty"`
+	Image                string `json:"image,omitempty"`
+	Port                 int    `json:"port,omitempty"`
+	ClientTimeoutSeconds int    `json:"clientTimeoutSeconds,omitempty"`
+}
+
+// ImageFacade is the image facade container of an OpsSight
+type ImageFacade struct {
+	Name               string          `json:"name,omitempty"`
+	Image              string          `json:"image,omitempty"`
+	Port               int             `json:"port,omitempty"`
+	InternalRegistries []*RegistryAuth `json:"internalRegistries,omitempty"`
+	ImagePullerType    string          `json:"imagePullerType,omitempty"`
+	ServiceAccount     string          `json:"serviceAccount,omitempty"`
+}
+
+// RegistryAuth is the credentials of a registry scanned by an OpsSight
+type RegistryAuth struct {
+	URL      string `json:"Url"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+// Perceiver watches pods and images for an OpsSight
+type Perceiver struct {
+	EnableImagePerceiver      bool            `json:"enableImagePerceiver"`
+	EnablePodPerceiver        bool            `json:"enablePodPerceiver"`
+	ImagePerceiver            *ImagePerceiver `json:"imagePerceiver,omitempty"`
+	PodPerceiver              *PodPerceiver   `json:"podPerceiver,omitempty"`
+	AnnotationIntervalSeconds int             `json:"annotationIntervalSeconds,omitempty"`
+	DumpIntervalMinutes       int             `json:"dumpIntervalMinutes,omitempty"`
+	ServiceAccount            string          `json:"serviceAccount,omitempty"`
+	Port                      int             `json:"port,omitempty"`
+}
+
+// ImagePerceiver is the OpenShift image perceiver of an OpsSight
+type ImagePerceiver struct {
+	Name  string `json:"name,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+// PodPerceiver is the pod perceiver of an OpsSight
+type PodPerceiver struct {
+	Name            string `json:"name,omitempty"`
+	Image           string `json:"image,omitempty"`
+	NamespaceFilter string `json:"namespaceFilter,omitempty"`
+}
+
+// Prometheus is the metrics component of an OpsSight
+type Prometheus struct {
+	Name   string `json:"name,omitempty"`
+	Image  string `json:"image,omitempty"`
+	Port   int    `json:"port,omitempty"`
+	Expose string `json:"expose,omitempty"`
+}
+
+// Skyfire is the test component of an OpsSight
+type Skyfire struct {
+	Name                         string `json:"name,omitempty"`
+	Image                        string `json:"image,omitempty"`
+	Port                         int    `json:"port,omitempty"`
+	PrometheusPort               int    `json:"prometheusPort,omitempty"`
+	ServiceAccount               string `json:"serviceAccount,omitempty"`
+	HubClientTimeoutSeconds      int    `json:"hubClientTimeoutSeconds,omitempty"`
+	HubDumpPauseSeconds          int    `json:"hubDumpPauseSeconds,omitempty"`
+	KubeDumpIntervalSeconds      int    `json:"kubeDumpIntervalSeconds,omitempty"`
+	PerceptorDumpIntervalSeconds int    `json:"perceptorDumpIntervalSeconds,omitempty"`
+}
+
+// OpsSightBlackDuck is the Black Duck configuration of an OpsSight
+type OpsSightBlackDuck struct {
+	ExternalHosts                      []*Host        `json:"externalHosts,omitempty"`
+	ConnectionsEnvironmentVariableName string         `json:"connectionsEnvironmentVariableName,omitempty"`
+	TLSVerification                    bool           `json:"tlsVerification"`
+	InitialCount                       int            `json:"initialCount,omitempty"`
+	MaxCount                           int            `json:"maxCount,omitempty"`
+	DeleteBlackduckThresholdPercentage int            `json:"deleteBlackduckThresholdPercentage,omitempty"`
+	BlackduckSpec                      *BlackDuckSpec `json:"blackduckSpec,omitempty"`
+}
+
+// Host is a Black Duck instance used by an OpsSight
+type Host struct {
+	Scheme              string `json:"scheme"`
+	Domain              string `json:"domain"`
+	Port                int    `json:"port"`
+	User                string `json:"user"`
+	Password            string `json:"password"`
+	ConcurrentScanLimit int    `json:"concurrentScanLimit"`
+}
+
+// OpsSightStatus is the status of an OpsSight
+type OpsSightStatus struct {
+	State         string  `json:"state,omitempty"`
+	ErrorMessage  string  `json:"errorMessage,omitempty"`
+	InternalHosts []*Host `json:"internalHosts,omitempty"`
+}
+
+// OpsSightList is a list of OpsSights
+type OpsSightList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpsSight `json:"items"`
+}
@@ -22,38 +22,135 @@ under the License.
 package crd
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
 	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
 )
 
-// WatchCustomResourceDefinition watches the custom resource defintion
-func WatchCustomResourceDefinition(apiExtensionClient *apiextensionsclient.Clientset, name string, timeout int) (watch.Interface, error) {
-	return apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Watch(metav1.ListOptions{TimeoutSeconds: k8sutils.IntToInt64Ptr(timeout)})
+// WatchCustomResourceDefinition watches the custom resource definition name
+func WatchCustomResourceDefinition(apiExtensionClient apiextensionsclient.Interface, name string, timeout int) (watch.Interface, error) {
+	return apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Watch(metav1.ListOptions{
+		FieldSelector:  fields.OneTermEqualSelector("metadata.name", name).String(),
+		TimeoutSeconds: k8sutils.IntToInt64Ptr(timeout),
+	})
 }
 
 // BlockUntilWatchEventReceived blocks until first event is received, and sees if it matches the wanted eventType
 func BlockUntilWatchEventReceived(watchInterface watch.Interface, eventType watch.EventType) error {
 	defer watchInterface.Stop()
-	watchEvent := <-watchInterface.ResultChan()
+	watchEvent, ok := <-watchInterface.ResultChan()
+	if !ok {
+		return fmt.Errorf("the watch ended before an event was received")
+	}
 	if watchEvent.Type != eventType {
 		return fmt.Errorf("The event from the watch did not match the wanted eventType: %v", eventType)
 	}
 	return nil
 }
 
-// BlockUntilCrdIsAdded blocks until first event is received, and sees if it matches the wanted eventType
-func BlockUntilCrdIsAdded(apiExtensionClient *apiextensionsclient.Clientset, name string, timeout int) error {
-	watchInterface, err := WatchCustomResourceDefinition(apiExtensionClient, name, timeout)
+// IsCrdEstablished returns whether crd is Established, its names are accepted and it is not being
+// deleted, and otherwise describes what is missing
+func IsCrdEstablished(crd *apiextensionsv1beta1.CustomResourceDefinition) (bool, string) {
+	if condition := crdCondition(crd, apiextensionsv1beta1.Terminating); condition != nil && condition.Status == apiextensionsv1beta1.ConditionTrue {
+		return false, "it is being deleted"
+	}
+	missing := []string{}
+	for _, conditionType := range []apiextensionsv1beta1.CustomResourceDefinitionConditionType{apiextensionsv1beta1.Established, apiextensionsv1beta1.NamesAccepted} {
+		condition := crdCondition(crd, conditionType)
+		switch {
+		case condition == nil:
+			missing = append(missing, fmt.Sprintf("%s is not set", conditionType))
+		case condition.Status != apiextensionsv1beta1.ConditionTrue:
+			missing = append(missing, fmt.Sprintf("%s is %s: %s", conditionType, condition.Status, condition.Message))
+		}
+	}
+	return len(missing) == 0, strings.Join(missing, ", ")
+}
+
+func crdCondition(crd *apiextensionsv1beta1.CustomResourceDefinition, conditionType apiextensionsv1beta1.CustomResourceDefinitionConditionType) *apiextensionsv1beta1.CustomResourceDefinitionCondition {
+	for i := range crd.Status.Conditions {
+		if crd.Status.Conditions[i].Type == conditionType {
+			return &crd.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// BlockUntilCrdIsAdded blocks until the custom resource definition name exists, is Established and its
+// names are accepted. A CRD that already exists satisfies it immediately.
+// A zero timeout (in seconds) uses the configured crd added timeout
+func BlockUntilCrdIsAdded(apiExtensionClient apiextensionsclient.Interface, name string, timeout int) error {
+	state := "it does not exist"
+	err := waitForCrd(apiExtensionClient, name, timeout, func(crd *apiextensionsv1beta1.CustomResourceDefinition) bool {
+		if crd == nil {
+			state = "it does not exist"
+			return false
+		}
+		var established bool
+		established, state = IsCrdEstablished(crd)
+		return established
+	})
 	if err != nil {
-		return fmt.Errorf("something went wrong in the watch event: %v", err)
+		return fmt.Errorf("%v crd was not added: %v: %s", name, err, state)
 	}
-	err = BlockUntilWatchEventReceived(watchInterface, watch.Added)
+	return nil
+}
+
+// BlockUntilCrdIsDeleted blocks until the custom resource definition name does not exist.
+// A zero timeout (in seconds) uses the configured crd deleted timeout
+func BlockUntilCrdIsDeleted(apiExtensionClient apiextensionsclient.Interface, name string, timeout int) error {
+	if timeout == 0 {
+		timeout = int(config.Get().Timeouts.CRDDeleted.Seconds())
+	}
+	err := waitForCrd(apiExtensionClient, name, timeout, func(crd *apiextensionsv1beta1.CustomResourceDefinition) bool {
+		return crd == nil
+	})
 	if err != nil {
-		return fmt.Errorf("%v crd was not added: %v", name, err)
+		return fmt.Errorf("%v crd was not deleted: %v", name, err)
 	}
 	return nil
 }
+
+// waitForCrd lists and then watches the custom resource definition name until done returns true for
+// it, or for nil once it does not exist
+func waitForCrd(apiExtensionClient apiextensionsclient.Interface, name string, timeout int, done func(*apiextensionsv1beta1.CustomResourceDefinition) bool) error {
+	if timeout == 0 {
+		timeout = int(config.Get().Timeouts.CRDAdded.Seconds())
+	}
+	logging.Debugf("Waiting up to %ds for crd %s", timeout, name)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+	crds := apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions()
+	list := func(options metav1.ListOptions) (runtime.Object, error) {
+		return crds.List(options)
+	}
+	lw := k8sutils.ListWatch(list, crds.Watch, k8sutils.SelectName(name))
+	source := k8sutils.InformerSource{ListerWatcher: lw, Object: &apiextensionsv1beta1.CustomResourceDefinition{}}
+	err := k8sutils.WaitForStores(ctx, []k8sutils.InformerSource{source}, func(stores []cache.Store) (bool, error) {
+		obj, exists, err := stores[0].GetByKey(name)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			return done(nil), nil
+		}
+		return done(obj.(*apiextensionsv1beta1.CustomResourceDefinition)), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out after %ds", timeout)
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package crd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testCrd(name string, established bool) *apiextensionsv1beta1.CustomResourceDefinition {
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if established {
+		crd.Status.Conditions = []apiextensionsv1beta1.CustomResourceDefinitionCondition{
+			{Type: apiextensionsv1beta1.NamesAccepted, Status: apiextensionsv1beta1.ConditionTrue},
+			{Type: apiextensionsv1beta1.Established, Status: apiextensionsv1beta1.ConditionTrue},
+		}
+	}
+	return crd
+}
+
+func TestBlockUntilCrdIsAddedExisting(t *testing.T) {
+	c := apiextensionsfake.NewSimpleClientset(testCrd("blackducks.synopsys.com", true))
+	if err := BlockUntilCrdIsAdded(c, "blackducks.synopsys.com", 5); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBlockUntilCrdIsAddedWaitsForTheNamedCrd(t *testing.T) {
+	c := apiextensionsfake.NewSimpleClientset()
+	crds := c.ApiextensionsV1beta1().CustomResourceDefinitions()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		crds.Create(testCrd("alerts.synopsys.com", true))
+		crds.Create(testCrd("blackducks.synopsys.com", false))
+		time.Sleep(100 * time.Millisecond)
+		crds.UpdateStatus(testCrd("blackducks.synopsys.com", true))
+	}()
+	start := time.Now()
+	if err := BlockUntilCrdIsAdded(c, "blackducks.synopsys.com", 5); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("returned after %v, before the crd was established", elapsed)
+	}
+}
+
+func TestBlockUntilCrdIsAddedTimesOut(t *testing.T) {
+	c := apiextensionsfake.NewSimpleClientset(testCrd("opssights.synopsys.com", false))
+	err := BlockUntilCrdIsAdded(c, "opssights.synopsys.com", 1)
+	if err == nil || !strings.Contains(err.Error(), "Established is not set") {
+		t.Errorf("expected a timeout naming the missing condition, got %v", err)
+	}
+}
+
+func TestBlockUntilCrdIsDeleted(t *testing.T) {
+	c := apiextensionsfake.NewSimpleClientset(testCrd("alerts.synopsys.com", true))
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		c.ApiextensionsV1beta1().CustomResourceDefinitions().Delete("alerts.synopsys.com", nil)
+	}()
+	if err := BlockUntilCrdIsDeleted(c, "alerts.synopsys.com", 5); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package crd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Names of the custom resource definitions synopsysctl deploys
+const (
+	AlertCRDName     = "alerts.synopsys.com"
+	BlackDuckCRDName = "blackducks.synopsys.com"
+	OpsSightCRDName  = "opssights.synopsys.com"
+)
+
+// Shape is what the suites check of a custom resource definition
+type Shape struct {
+	Name       string
+	Group      string
+	Scope      apiextensionsv1beta1.ResourceScope
+	Kind       string
+	Plural     string
+	Singular   string
+	ShortNames []string
+	Categories []string
+	// ServedVersions are the versions the API server serves, in the order of the definition
+	ServedVersions []string
+	StorageVersion string
+	// Validation is the OpenAPI schema of the storage version, or nil without validation
+	Validation *apiextensionsv1beta1.JSONSchemaProps
+}
+
+// ShapeOf returns the shape of crd. A definition without a versions list serves and stores spec.version
+func ShapeOf(crd *apiextensionsv1beta1.CustomResourceDefinition) *Shape {
+	s := &Shape{
+		Name:       crd.Name,
+		Group:      crd.Spec.Group,
+		Scope:      crd.Spec.Scope,
+		Kind:       crd.Spec.Names.Kind,
+		Plural:     crd.Spec.Names.Plural,
+		Singular:   crd.Spec.Names.Singular,
+		ShortNames: crd.Spec.Names.ShortNames,
+		Categories: crd.Spec.Names.Categories,
+	}
+	if crd.Spec.Validation != nil {
+		s.Validation = crd.Spec.Validation.OpenAPIV3Schema
+	}
+	if len(crd.Spec.Versions) == 0 {
+		s.ServedVersions = []string{crd.Spec.Version}
+		s.StorageVersion = crd.Spec.Version
+		return s
+	}
+	for _, v := range crd.Spec.Versions {
+		if v.Served {
+			s.ServedVersions = append(s.ServedVersions, v.Name)
+		}
+		if v.Storage {
+			s.StorageVersion = v.Name
+			if v.Schema != nil && v.Schema.OpenAPIV3Schema != nil {
+				s.Validation = v.Schema.OpenAPIV3Schema
+			}
+		}
+	}
+	return s
+}
+
+// GetShape returns the shape of the custom resource definition name
+func GetShape(apiExtensionClient apiextensionsclient.Interface, name string) (*Shape, error) {
+	crd, err := apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ShapeOf(crd), nil
+}
+
+// Namespace returns namespace, or "" if the definition is cluster scoped
+func (s *Shape) Namespace(namespace string) string {
+	if s.Scope == apiextensionsv1beta1.ClusterScoped {
+		return ""
+	}
+	return namespace
+}
+
+// ValidationProperty returns the schema of the property at path, e.g. "spec", "size", or nil if the
+// validation schema does not have it
+func (s *Shape) ValidationProperty(path ...string) *apiextensionsv1beta1.JSONSchemaProps {
+	props := s.Validation
+	for _, p := range path {
+		if props == nil {
+			return nil
+		}
+		prop, ok := props.Properties[p]
+		if !ok {
+			return nil
+		}
+		props = &prop
+	}
+	return props
+}
+
+// ValidationProperties returns the paths of every property of the validation schema, sorted, e.g.
+// "spec.size"
+func (s *Shape) ValidationProperties() []string {
+	paths := []string{}
+	var walk func(prefix string, props *apiextensionsv1beta1.JSONSchemaProps)
+	walk = func(prefix string, props *apiextensionsv1beta1.JSONSchemaProps) {
+		for name, prop := range props.Properties {
+			path := strings.TrimPrefix(prefix+"."+name, ".")
+			paths = append(paths, path)
+			prop := prop
+			walk(path, &prop)
+		}
+	}
+	if s.Validation != nil {
+		walk("", s.Validation)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (s *Shape) String() string {
+	validation := "none"
+	if s.Validation != nil {
+		validation = strings.Join(s.ValidationProperties(), ", ")
+	}
+	return fmt.Sprintf("name: %s\ngroup: %s\nscope: %s\nkind: %s\nplural: %s\nsingular: %s\nshort names: %s\ncategories: %s\nserved versions: %s\nstorage version: %s\nvalidation: %s",
+		s.Name, s.Group, s.Scope, s.Kind, s.Plural, s.Singular, strings.Join(s.ShortNames, ", "), strings.Join(s.Categories, ", "),
+		strings.Join(s.ServedVersions, ", "), s.StorageVersion, validation)
+}
@@ -0,0 +1,138 @@
+package crd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/onsi/gomega/types"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func blackDuckCrd() *apiextensionsv1beta1.CustomResourceDefinition {
+	return &apiextensionsv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: BlackDuckCRDName},
+		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+			Group: "synopsys.com",
+			Scope: apiextensionsv1beta1.NamespaceScoped,
+			Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
+				Kind:       "Blackduck",
+				Plural:     "blackducks",
+				Singular:   "blackduck",
+				ShortNames: []string{"bds", "bd"},
+				Categories: []string{"synopsys"},
+			},
+			Versions: []apiextensionsv1beta1.CustomResourceDefinitionVersion{
+				{Name: "v1", Served: true, Storage: true},
+				{Name: "v1alpha1", Served: false},
+			},
+			Validation: &apiextensionsv1beta1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextensionsv1beta1.JSONSchemaProps{
+					Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+						"spec": {Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+							"size": {Type: "string"},
+						}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestShapeOf(t *testing.T) {
+	s := ShapeOf(blackDuckCrd())
+	if s.Scope != apiextensionsv1beta1.NamespaceScoped || s.Group != "synopsys.com" || s.Kind != "Blackduck" {
+		t.Errorf("unexpected shape\n%s", s)
+	}
+	if !reflect.DeepEqual(s.ServedVersions, []string{"v1"}) || s.StorageVersion != "v1" {
+		t.Errorf("expected v1 to be served and stored, got %v and %s", s.ServedVersions, s.StorageVersion)
+	}
+	if got := s.ValidationProperties(); !reflect.DeepEqual(got, []string{"spec", "spec.size"}) {
+		t.Errorf("unexpected validation properties %v", got)
+	}
+	if s.ValidationProperty("spec", "size") == nil || s.ValidationProperty("spec", "namespace") != nil {
+		t.Error("unexpected validation property lookup")
+	}
+}
+
+func TestShapeOfSingleVersion(t *testing.T) {
+	crd := blackDuckCrd()
+	crd.Spec.Versions = nil
+	crd.Spec.Version = "v1"
+	s := ShapeOf(crd)
+	if !reflect.DeepEqual(s.ServedVersions, []string{"v1"}) || s.StorageVersion != "v1" {
+		t.Errorf("expected spec.version to be served and stored, got %v and %s", s.ServedVersions, s.StorageVersion)
+	}
+}
+
+func TestGetShape(t *testing.T) {
+	c := apiextensionsfake.NewSimpleClientset(blackDuckCrd())
+	s, err := GetShape(c, BlackDuckCRDName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != BlackDuckCRDName {
+		t.Errorf("expected %s, got %s", BlackDuckCRDName, s.Name)
+	}
+	if _, err := GetShape(c, AlertCRDName); err == nil {
+		t.Error("expected an error for a missing crd")
+	}
+}
+
+func TestShapeNamespace(t *testing.T) {
+	s := ShapeOf(blackDuckCrd())
+	s.Scope = apiextensionsv1beta1.NamespaceScoped
+	if ns := s.Namespace("bd-one"); ns != "bd-one" {
+		t.Errorf("expected bd-one for a namespaced crd, got %q", ns)
+	}
+	s.Scope = apiextensionsv1beta1.ClusterScoped
+	if ns := s.Namespace("bd-one"); ns != "" {
+		t.Errorf("expected no namespace for a cluster scoped crd, got %q", ns)
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	crd := blackDuckCrd()
+	matching := map[string]types.GomegaMatcher{
+		"BeNamespaced":           BeNamespaced(),
+		"HaveGroup":              HaveGroup("synopsys.com"),
+		"HaveKind":               HaveKind("Blackduck"),
+		"ServeVersion":           ServeVersion("v1"),
+		"HaveStorageVersion":     HaveStorageVersion("v1"),
+		"HaveShortName":          HaveShortName("bd"),
+		"HaveCategory":           HaveCategory("synopsys"),
+		"HaveValidation":         HaveValidation(),
+		"HaveValidationProperty": HaveValidationProperty("spec.size"),
+	}
+	for name, m := range matching {
+		if ok, err := m.Match(crd); !ok || err != nil {
+			t.Errorf("expected %s to match, got %v, %v", name, ok, err)
+		}
+	}
+	notMatching := map[string]types.GomegaMatcher{
+		"BeClusterScoped":        BeClusterScoped(),
+		"ServeVersion":           ServeVersion("v1alpha1"),
+		"HaveValidationProperty": HaveValidationProperty("spec.namespace"),
+	}
+	for name, m := range notMatching {
+		if ok, err := m.Match(ShapeOf(crd)); ok || err != nil {
+			t.Errorf("expected %s not to match, got %v, %v", name, ok, err)
+		}
+	}
+}
+
+func TestMatcherFailureMessage(t *testing.T) {
+	m := BeClusterScoped()
+	if _, err := m.Match(blackDuckCrd()); err != nil {
+		t.Fatal(err)
+	}
+	msg := m.FailureMessage(nil)
+	if !strings.Contains(msg, "have scope Cluster") || !strings.Contains(msg, "scope: Namespaced") {
+		t.Errorf("unexpected failure message %q", msg)
+	}
+	if _, err := m.Match("blackduck"); err == nil {
+		t.Error("expected an error matching a string")
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package crd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+)
+
+// shapeMatcher is a Gomega matcher of a *Shape or a *CustomResourceDefinition
+type shapeMatcher struct {
+	description string
+	match       func(s *Shape) bool
+	actual      string
+}
+
+func (matcher *shapeMatcher) Match(actual interface{}) (bool, error) {
+	var s *Shape
+	switch a := actual.(type) {
+	case *Shape:
+		s = a
+	case *apiextensionsv1beta1.CustomResourceDefinition:
+		s = ShapeOf(a)
+	default:
+		return false, fmt.Errorf("expected a *crd.Shape or a *CustomResourceDefinition, got %T", actual)
+	}
+	matcher.actual = s.String()
+	return matcher.match(s), nil
+}
+
+func (matcher *shapeMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected the crd to %s, but it has\n%s", matcher.description, matcher.actual)
+}
+
+func (matcher *shapeMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected the crd not to %s, but it has\n%s", matcher.description, matcher.actual)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// HaveScope succeeds if the crd has scope
+func HaveScope(scope apiextensionsv1beta1.ResourceScope) types.GomegaMatcher {
+	return &shapeMatcher{
+		description: fmt.Sprintf("have scope %s", scope),
+		match:       func(s *Shape) bool { return s.Scope == scope },
+	}
+}
+
+// BeNamespaced succeeds if the crd is namespace scoped
+func BeNamespaced() types.GomegaMatcher {
+	return HaveScope(apiextensionsv1beta1.NamespaceScoped)
+}
+
+// BeClusterScoped succeeds if the crd is cluster scoped
+func BeClusterScoped() types.GomegaMatcher {
+	return HaveScope(apiextensionsv1beta1.ClusterScoped)
+}
+
+// HaveGroup succeeds if the crd is in the API group
+func HaveGroup(group string) types.GomegaMatcher {
+	return &shapeMatcher{
+		description: fmt.Sprintf("have group %s", group),
+		match:       func(s *Shape) bool { return s.Group == group },
+	}
+}
+
+// HaveKind succeeds if the crd defines kind
+func HaveKind(kind string) types.GomegaMatcher {
+	return &shapeMatcher{
+		description: fmt.Sprintf("have kind %s", kind),
+		match:       func(s *Shape) bool { return s.Kind == kind },
+	}
+}
+
+// ServeVersion succeeds if the crd serves version
+func ServeVersion(version string) types.GomegaMatcher {
+	return &shapeMatcher{
+		description: fmt.Sprintf("serve version %s", version),
+		match:       func(s *Shape) bool { return contains(s.ServedVersions, version) },
+	}
+}
+
+// HaveStorageVersion succeeds if the crd stores version
+func HaveStorageVersion(version string) types.GomegaMatcher {
+	return &shapeMatcher{
+		description: fmt.Sprintf("have storage version %s", version),
+		match:       func(s *Shape) bool { return s.StorageVersion == version },
+	}
+}
+
+// HaveShortName succeeds if the crd has the short name
+func HaveShortName(name string) types.GomegaMatcher {
+	return &shapeMatcher{
+		description: fmt.Sprintf("have short name %s", name),
+		match:       func(s *Shape) bool { return contains(s.ShortNames, name) },
+	}
+}
+
+// HaveCategory succeeds if the crd is in category
+func HaveCategory(category string) types.GomegaMatcher {
+	return &shapeMatcher{
+		description: fmt.Sprintf("have category %s", category),
+		match:       func(s *Shape) bool { return contains(s.Categories, category) },
+	}
+}
+
+// HaveValidation succeeds if the crd has an OpenAPI validation schema
+func HaveValidation() types.GomegaMatcher {
+	return &shapeMatcher{
+		description: "have a validation schema",
+		match:       func(s *Shape) bool { return s.Validation != nil },
+	}
+}
+
+// HaveValidationProperty succeeds if the validation schema has the property at path, e.g. "spec.size"
+func HaveValidationProperty(path string) types.GomegaMatcher {
+	return &shapeMatcher{
+		description: fmt.Sprintf("have validation property %s", path),
+		match:       func(s *Shape) bool { return s.ValidationProperty(strings.Split(path, ".")...) != nil },
+	}
+}
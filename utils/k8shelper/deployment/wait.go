@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ListWatch lists and watches the deployments in namespace, with options changed by tweak if it is not nil
+func ListWatch(c clientset.Interface, namespace string, tweak func(*metav1.ListOptions)) cache.ListerWatcher {
+	client := c.AppsV1().Deployments(namespace)
+	list := func(options metav1.ListOptions) (runtime.Object, error) {
+		return client.List(options)
+	}
+	return k8sutils.ListWatch(list, client.Watch, tweak)
+}
+
+// RolloutStatus describes the rollout of d and returns whether it is complete, the same way as
+// "kubectl rollout status". It returns an error if the rollout exceeded its progress deadline
+func RolloutStatus(d *appsv1.Deployment) (string, bool, error) {
+	if d.Generation > d.Status.ObservedGeneration {
+		return fmt.Sprintf("waiting for deployment %q spec update to be observed", d.Name), false, nil
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == v1.ConditionFalse && c.Reason == "ProgressDeadlineExceeded" {
+			return "", false, fmt.Errorf("deployment %q exceeded its progress deadline: %s", d.Name, c.Message)
+		}
+	}
+	want := k8sutils.Replicas(d.Spec.Replicas)
+	switch {
+	case d.Status.UpdatedReplicas < want:
+		return fmt.Sprintf("waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated", d.Name, d.Status.UpdatedReplicas, want), false, nil
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		return fmt.Sprintf("waiting for deployment %q rollout to finish: %d old replicas are pending termination", d.Name, d.Status.Replicas-d.Status.UpdatedReplicas), false, nil
+	case d.Status.AvailableReplicas < d.Status.UpdatedReplicas:
+		return fmt.Sprintf("waiting for deployment %q rollout to finish: %d of %d updated replicas are available", d.Name, d.Status.AvailableReplicas, d.Status.UpdatedReplicas), false, nil
+	}
+	return fmt.Sprintf("deployment %q successfully rolled out", d.Name), true, nil
+}
+
+// ScaledToZeroStatus describes the scale down of d and returns whether it has no replicas left
+func ScaledToZeroStatus(d *appsv1.Deployment) (string, bool, error) {
+	want := k8sutils.Replicas(d.Spec.Replicas)
+	switch {
+	case want != 0:
+		return fmt.Sprintf("deployment %q wants %d replicas", d.Name, want), false, nil
+	case d.Generation > d.Status.ObservedGeneration:
+		return fmt.Sprintf("waiting for deployment %q spec update to be observed", d.Name), false, nil
+	case d.Status.Replicas > 0:
+		return fmt.Sprintf("waiting for %d replicas of deployment %q to terminate", d.Status.Replicas, d.Name), false, nil
+	}
+	return fmt.Sprintf("deployment %q is scaled to zero", d.Name), true, nil
+}
+
+// WaitForRollout waits until the rollout of the deployment is complete
+func WaitForRollout(ctx context.Context, c clientset.Interface, namespace, name string) (*appsv1.Deployment, error) {
+	return waitFor(ctx, c, namespace, name, RolloutStatus)
+}
+
+// WaitForScaledToZero waits until the deployment is scaled to zero and all of its replicas are gone
+func WaitForScaledToZero(ctx context.Context, c clientset.Interface, namespace, name string) (*appsv1.Deployment, error) {
+	return waitFor(ctx, c, namespace, name, ScaledToZeroStatus)
+}
+
+func waitFor(ctx context.Context, c clientset.Interface, namespace, name string, status func(*appsv1.Deployment) (string, bool, error)) (*appsv1.Deployment, error) {
+	listWatch := func(tweak func(*metav1.ListOptions)) cache.ListerWatcher {
+		return ListWatch(c, namespace, tweak)
+	}
+	obj, err := k8sutils.WaitForObject(ctx, listWatch, &appsv1.Deployment{}, "deployment", namespace, name, func(obj interface{}) (string, bool, error) {
+		return status(obj.(*appsv1.Deployment))
+	})
+	d, _ := obj.(*appsv1.Deployment)
+	return d, err
+}
@@ -0,0 +1,75 @@
+package deployment
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testDeployment(want int32, status appsv1.DeploymentStatus) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "synopsys-operator", Namespace: "ns", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &want},
+		Status:     status,
+	}
+}
+
+func TestRolloutStatus(t *testing.T) {
+	for _, tc := range []struct {
+		status appsv1.DeploymentStatus
+		want   int32
+		done   bool
+		msg    string
+	}{
+		{appsv1.DeploymentStatus{ObservedGeneration: 1}, 1, false, "spec update to be observed"},
+		{appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1}, 2, false, "1 out of 2 new replicas"},
+		{appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2}, 2, false, "1 old replicas are pending termination"},
+		{appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1}, 2, false, "1 of 2 updated replicas are available"},
+		{appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}, 2, true, "successfully rolled out"},
+	} {
+		msg, done, err := RolloutStatus(testDeployment(tc.want, tc.status))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done != tc.done || !strings.Contains(msg, tc.msg) {
+			t.Errorf("%+v: expected %v and %q, got %v and %q", tc.status, tc.done, tc.msg, done, msg)
+		}
+	}
+}
+
+func TestRolloutStatusProgressDeadlineExceeded(t *testing.T) {
+	d := testDeployment(1, appsv1.DeploymentStatus{
+		ObservedGeneration: 2,
+		Conditions: []appsv1.DeploymentCondition{{
+			Type: appsv1.DeploymentProgressing, Status: v1.ConditionFalse, Reason: "ProgressDeadlineExceeded", Message: "replica set has timed out progressing",
+		}},
+	})
+	if _, _, err := RolloutStatus(d); err == nil {
+		t.Error("expected an error for an exceeded progress deadline")
+	}
+}
+
+func TestWaitForScaledToZero(t *testing.T) {
+	c := fake.NewSimpleClientset(testDeployment(0, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1}))
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		c.AppsV1().Deployments("ns").UpdateStatus(testDeployment(0, appsv1.DeploymentStatus{ObservedGeneration: 2}))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := WaitForScaledToZero(ctx, c, "ns", "synopsys-operator"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := WaitForRollout(ctx, c, "ns", "missing"); err == nil || !strings.Contains(err.Error(), "was not found") {
+		t.Errorf("expected a timeout for a missing deployment, got %v", err)
+	}
+}
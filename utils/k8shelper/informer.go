@@ -0,0 +1,127 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package k8shelper
+
+import (
+	"context"
+	"time"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+)
+
+// InformerSource is a kind of object that is kept in an informer store while waiting
+type InformerSource struct {
+	ListerWatcher cache.ListerWatcher
+	Object        runtime.Object
+	// Handler, if set, also receives every event of the informer, e.g. to record intermediate states
+	// the condition might not see
+	Handler cache.ResourceEventHandler
+}
+
+// StoresCondition reports whether the objects in stores are in the wanted state. Returning an error
+// stops the wait
+type StoresCondition func(stores []cache.Store) (bool, error)
+
+// WaitForStores runs an informer for every source and calls condition with their stores, in the same
+// order, once they are synced, whenever an object changes and every poll interval so conditions that
+// depend on time make progress. An expired or broken watch is recovered by relisting.
+// It returns wait.ErrWaitTimeout if ctx is done before condition is true
+func WaitForStores(ctx context.Context, sources []InformerSource, condition StoresCondition) error {
+	changed := make(chan struct{}, 1)
+	notify := func(interface{}) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	notifier := cache.ResourceEventHandlerFuncs{
+		AddFunc:    notify,
+		UpdateFunc: func(_, obj interface{}) { notify(obj) },
+		DeleteFunc: notify,
+	}
+
+	stop := make(chan struct{})
+	stores := make([]cache.Store, len(sources))
+	synced := make([]cache.InformerSynced, len(sources))
+	stopped := make([]chan struct{}, len(sources))
+	for i, source := range sources {
+		var handler cache.ResourceEventHandler = notifier
+		if source.Handler != nil {
+			handler = handlers{source.Handler, notifier}
+		}
+		store, controller := cache.NewInformer(source.ListerWatcher, source.Object, 0, handler)
+		stores[i], synced[i], stopped[i] = store, controller.HasSynced, make(chan struct{})
+		go func(controller cache.Controller, stopped chan struct{}) {
+			defer close(stopped)
+			controller.Run(stop)
+		}(controller, stopped[i])
+	}
+	defer func() {
+		close(stop)
+		for _, s := range stopped {
+			<-s
+		}
+	}()
+
+	ticker := time.NewTicker(config.Get().Timeouts.Poll.Duration)
+	defer ticker.Stop()
+
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return wait.ErrWaitTimeout
+	}
+	for {
+		done, err := condition(stores)
+		if done || err != nil {
+			return err
+		}
+		select {
+		case <-changed:
+		case <-ticker.C:
+		case <-ctx.Done():
+			return wait.ErrWaitTimeout
+		}
+	}
+}
+
+// handlers passes every event to each of its handlers in order
+type handlers []cache.ResourceEventHandler
+
+func (h handlers) OnAdd(obj interface{}) {
+	for _, handler := range h {
+		handler.OnAdd(obj)
+	}
+}
+
+func (h handlers) OnUpdate(oldObj, newObj interface{}) {
+	for _, handler := range h {
+		handler.OnUpdate(oldObj, newObj)
+	}
+}
+
+func (h handlers) OnDelete(obj interface{}) {
+	for _, handler := range h {
+		handler.OnDelete(obj)
+	}
+}
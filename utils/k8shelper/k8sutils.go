@@ -23,61 +23,22 @@ package k8shelper
 
 import (
 	"encoding/json"
-	"flag"
-	"fmt"
-	"os"
-	"path/filepath"
 
-	"github.com/juju/errors"
-	log "github.com/sirupsen/logrus"
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth" //for auths
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
-	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
-	"k8s.io/client-go/util/homedir"
 )
 
-// GetKubeConfig  will return the kube config
+// GetKubeConfig returns the rest config of the kubeconfig file at kubeconfigpath, or of $KUBECONFIG or
+// ~/.kube/config if it is empty. An empty path uses the in-cluster config when running in a pod
 func GetKubeConfig(kubeconfigpath string, insecureSkipTLSVerify bool) (*rest.Config, error) {
-	log.Debugf("Getting Kube Rest Config")
-	var err error
-	var kubeConfig *rest.Config
-	// creates the in-cluster config
-	kubeConfig, err = rest.InClusterConfig()
-	if err != nil {
-		log.Debugf("using native config due to %+v", err)
-		// Determine Config Paths
-		if home := homeDir(); len(kubeconfigpath) == 0 && home != "" {
-			kubeconfigpath = filepath.Join(home, ".kube", "config")
-		}
-
-		kubeConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-			&clientcmd.ClientConfigLoadingRules{
-				ExplicitPath: kubeconfigpath,
-			},
-			&clientcmd.ConfigOverrides{
-				ClusterInfo: clientcmdapi.Cluster{
-					Server:                "",
-					InsecureSkipTLSVerify: insecureSkipTLSVerify,
-				},
-			}).ClientConfig()
-		if err != nil {
-			return nil, err
-		}
-	}
-	return kubeConfig, nil
-}
-
-// homeDir determines the user's home directory path
-func homeDir() string {
-	if h := os.Getenv("HOME"); h != "" {
-		return h
-	}
-	return os.Getenv("USERPROFILE") // windows
+	logging.Debugf("Getting Kube Rest Config")
+	return DefaultClientFactory.RestConfig(ClientOptions{Kubeconfig: kubeconfigpath, InsecureSkipTLSVerify: insecureSkipTLSVerify})
 }
 
 // GetKubeClientSet will return the kube clientset
@@ -85,22 +46,9 @@ func GetKubeClientSet(kubeConfig *rest.Config) (*kubernetes.Clientset, error) {
 	return kubernetes.NewForConfig(kubeConfig)
 }
 
-func newKubeClientFromOutsideCluster() (*rest.Config, error) {
-	var kubeConfig *string
-	if home := homedir.HomeDir(); home != "" {
-		kubeConfig = flag.String("kubeconfig", filepath.Join(home, ".kube", "config"), "(optional) absolute path to the kubeconfig file")
-	} else {
-		kubeConfig = flag.String("kubeconfig", "", "absolute path to the kubeconfig file")
-	}
-	flag.Parse()
-
-	config, err := clientcmd.BuildConfigFromFlags("", *kubeConfig)
-	return config, errors.Annotate(err, "error creating default client config")
-}
-
-// GetRestConfig calls protoform.GetKubeConfig
+// GetRestConfig returns the rest config of the cluster of the suite configuration
 func GetRestConfig() (*rest.Config, error) {
-	return GetKubeConfig("", false)
+	return DefaultClientFactory.RestConfig(ClientOptionsFor(config.Get().Cluster))
 }
 
 // GetKubeClient gets the kubernetes client
@@ -159,7 +107,7 @@ func GetResponseFromK8sEndpoint(restcli rest.Interface, requesturi string, unmar
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Response: %s\n", string(b))
+	logging.Debugf("Response: %s", string(b))
 	if err := json.Unmarshal(b, unmarshal); err != nil {
 		return err
 	}
@@ -0,0 +1,220 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package namespace
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	crutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/cr"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// Remaining is an object that is still in a terminating namespace
+type Remaining struct {
+	// Resource is the resource and group of the object, e.g. blackducks.synopsys.com or pods
+	Resource   string
+	Name       string
+	Finalizers []string
+	// Deleting is true if the object has a deletion timestamp
+	Deleting bool
+}
+
+func (r Remaining) String() string {
+	s := fmt.Sprintf("%s/%s", r.Resource, r.Name)
+	if len(r.Finalizers) > 0 {
+		s += fmt.Sprintf(" (finalizers: %s)", strings.Join(r.Finalizers, ", "))
+	}
+	return s
+}
+
+// TerminationReport is why a namespace has not been deleted yet
+type TerminationReport struct {
+	Namespace string
+	Phase     v1.NamespacePhase
+	// Finalizers are the spec finalizers of the namespace, e.g. kubernetes
+	Finalizers []v1.FinalizerName
+	// Remaining are the objects left in the namespace. Events are not included
+	Remaining []Remaining
+	// Errors are the resources that could not be listed
+	Errors []string
+}
+
+func (r *TerminationReport) String() string {
+	lines := []string{fmt.Sprintf("namespace %s is %s", r.Namespace, r.Phase)}
+	if len(r.Finalizers) > 0 {
+		finalizers := make([]string, len(r.Finalizers))
+		for i, f := range r.Finalizers {
+			finalizers[i] = string(f)
+		}
+		lines = append(lines, fmt.Sprintf("  namespace finalizers: %s", strings.Join(finalizers, ", ")))
+	}
+	for _, remaining := range r.Remaining {
+		lines = append(lines, fmt.Sprintf("  remaining: %s", remaining))
+	}
+	for _, err := range r.Errors {
+		lines = append(lines, fmt.Sprintf("  error: %s", err))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DescribeTermination reports the phase and finalizers of the namespace name and, if dc is not nil,
+// every object left in it. It returns nil if the namespace does not exist
+func DescribeTermination(c clientset.Interface, dc dynamic.Interface, name string) (*TerminationReport, error) {
+	ns, err := c.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	report := &TerminationReport{Namespace: name, Phase: ns.Status.Phase, Finalizers: ns.Spec.Finalizers}
+	if dc == nil {
+		return report, nil
+	}
+	resources, err := discovery.ServerPreferredNamespacedResources(c.Discovery())
+	if err != nil {
+		// the resources of the groups that could be discovered are still listed
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, err
+		}
+		report.Errors = append(report.Errors, err.Error())
+	}
+	for _, list := range resources {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if resource.Name == "events" || !canList(resource) {
+				continue
+			}
+			remaining, err := listRemaining(dc, gv.WithResource(resource.Name), name)
+			if err != nil {
+				report.Errors = append(report.Errors, err.Error())
+				continue
+			}
+			report.Remaining = append(report.Remaining, remaining...)
+		}
+	}
+	sort.Slice(report.Remaining, func(i, j int) bool {
+		return report.Remaining[i].String() < report.Remaining[j].String()
+	})
+	return report, nil
+}
+
+func canList(resource metav1.APIResource) bool {
+	// subresources such as pods/log cannot be listed
+	if strings.Contains(resource.Name, "/") {
+		return false
+	}
+	for _, verb := range resource.Verbs {
+		if verb == "list" {
+			return true
+		}
+	}
+	return false
+}
+
+func listRemaining(dc dynamic.Interface, gvr schema.GroupVersionResource, namespace string) ([]Remaining, error) {
+	list, err := dc.Resource(gvr).Namespace(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %v", gvr.GroupResource(), err)
+	}
+	remaining := []Remaining{}
+	for _, item := range list.Items {
+		remaining = append(remaining, Remaining{
+			Resource:   gvr.GroupResource().String(),
+			Name:       item.GetName(),
+			Finalizers: item.GetFinalizers(),
+			Deleting:   item.GetDeletionTimestamp() != nil,
+		})
+	}
+	return remaining, nil
+}
+
+// DeletionTimeoutError is returned when namespaces are not deleted in time
+type DeletionTimeoutError struct {
+	Timeout time.Duration
+	// Reports describe the namespaces that were not deleted
+	Reports []*TerminationReport
+}
+
+func (e *DeletionTimeoutError) Error() string {
+	reports := make([]string, len(e.Reports))
+	for i, r := range e.Reports {
+		reports[i] = r.String()
+	}
+	return fmt.Sprintf("timed out after %v waiting for namespaces to be deleted:\n%s", e.Timeout, strings.Join(reports, "\n"))
+}
+
+// Namespaces returns the namespaces that were not deleted
+func (e *DeletionTimeoutError) Namespaces() []string {
+	namespaces := make([]string, len(e.Reports))
+	for i, r := range e.Reports {
+		namespaces[i] = r.Namespace
+	}
+	return namespaces
+}
+
+// RemoveCRFinalizers removes the finalizers of every custom resource of gvrs in namespace, including
+// the cluster scoped ones whose spec.namespace is namespace, so an operator that is gone or broken
+// cannot keep the namespace terminating. Without gvrs, the Alert, Black Duck and OpsSight resources
+// are stripped. It returns the resources it changed
+func RemoveCRFinalizers(dc dynamic.Interface, namespace string, gvrs ...schema.GroupVersionResource) ([]string, error) {
+	if len(gvrs) == 0 {
+		gvrs = []schema.GroupVersionResource{crutils.GetAlertSchema(), crutils.GetBlackDuckSchema(), crutils.GetOpssightSchema()}
+	}
+	patch := []byte(`{"metadata":{"finalizers":null}}`)
+	removed := []string{}
+	for _, gvr := range gvrs {
+		items, err := crutils.ListInNamespace(dc, gvr, namespace)
+		if apierrs.IsNotFound(err) {
+			// the crd is not installed
+			continue
+		}
+		if err != nil {
+			return removed, fmt.Errorf("failed to list %s in namespace %s: %v", gvr.Resource, namespace, err)
+		}
+		for _, item := range items {
+			if len(item.GetFinalizers()) == 0 {
+				continue
+			}
+			crs := crutils.ResourceFor(dc, gvr, item.GetNamespace())
+			if _, err := crs.Patch(item.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil && !apierrs.IsNotFound(err) {
+				return removed, fmt.Errorf("failed to remove the finalizers of %s %s in namespace %s: %v", gvr.Resource, item.GetName(), namespace, err)
+			}
+			removed = append(removed, fmt.Sprintf("%s/%s", gvr.GroupResource(), item.GetName()))
+		}
+	}
+	return removed, nil
+}
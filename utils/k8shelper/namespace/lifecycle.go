@@ -0,0 +1,154 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// OwnerLabel is set to the base name of the framework that created a namespace
+	OwnerLabel = "cnt-framework"
+	// ManagedByLabel is set to ManagedBy on every namespace created by the suites
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedBy is the value of ManagedByLabel
+	ManagedBy = "cloud-native-tests"
+	// ExpiresAnnotation is the RFC 3339 time after which a namespace may be garbage collected
+	ExpiresAnnotation = "cloud-native-tests/expires"
+)
+
+// CreateOptions describe a test namespace
+type CreateOptions struct {
+	Name string
+	// Owner is the value of OwnerLabel
+	Owner string
+	// TTL sets ExpiresAnnotation to the creation time plus TTL; zero never expires
+	TTL time.Duration
+	// Labels are added to the ownership labels
+	Labels map[string]string
+}
+
+// Create creates a namespace labeled as created by the suites
+func Create(c clientset.Interface, opts CreateOptions) (*v1.Namespace, error) {
+	ns := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   opts.Name,
+			Labels: ownershipLabels(opts.Owner),
+		},
+	}
+	for k, v := range opts.Labels {
+		ns.Labels[k] = v
+	}
+	if opts.TTL > 0 {
+		ns.Annotations = map[string]string{ExpiresAnnotation: expires(time.Now(), opts.TTL)}
+	}
+	return c.CoreV1().Namespaces().Create(ns)
+}
+
+func ownershipLabels(owner string) map[string]string {
+	labels := map[string]string{ManagedByLabel: ManagedBy}
+	if owner != "" {
+		labels[OwnerLabel] = owner
+	}
+	return labels
+}
+
+func expires(now time.Time, ttl time.Duration) string {
+	return now.Add(ttl).UTC().Format(time.RFC3339)
+}
+
+// OwnershipPatch returns a merge patch that labels and annotates any object as created by the suites,
+// like Create does for namespaces. It marks the cluster scoped objects synopsysctl creates, so they
+// can be told apart from those of other installs
+func OwnershipPatch(owner string, ttl time.Duration, now time.Time) []byte {
+	metadata := map[string]interface{}{"labels": ownershipLabels(owner)}
+	if ttl > 0 {
+		metadata["annotations"] = map[string]string{ExpiresAnnotation: expires(now, ttl)}
+	}
+	patch, _ := json.Marshal(map[string]interface{}{"metadata": metadata})
+	return patch
+}
+
+// IsManaged returns true if obj was created by the suites
+func IsManaged(obj metav1.Object) bool {
+	_, owned := obj.GetLabels()[OwnerLabel]
+	return owned || obj.GetLabels()[ManagedByLabel] == ManagedBy
+}
+
+// Expired returns true if the ExpiresAnnotation of obj is before now. An object without the
+// annotation never expires
+func Expired(obj metav1.Object, now time.Time) (bool, error) {
+	value, ok := obj.GetAnnotations()[ExpiresAnnotation]
+	if !ok {
+		return false, nil
+	}
+	expires, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false, fmt.Errorf("%s has an invalid %s annotation %q: %v", obj.GetName(), ExpiresAnnotation, value, err)
+	}
+	return now.After(expires), nil
+}
+
+// ListWatch lists and watches the namespace name
+func ListWatch(c clientset.Interface, name string) cache.ListerWatcher {
+	client := c.CoreV1().Namespaces()
+	list := func(options metav1.ListOptions) (runtime.Object, error) {
+		return client.List(options)
+	}
+	return k8sutils.ListWatch(list, client.Watch, k8sutils.SelectName(name))
+}
+
+// WaitForActive waits for the namespace name to exist and be in the Active phase
+func WaitForActive(ctx context.Context, c clientset.Interface, name string) (*v1.Namespace, error) {
+	var active *v1.Namespace
+	source := k8sutils.InformerSource{ListerWatcher: ListWatch(c, name), Object: &v1.Namespace{}}
+	err := k8sutils.WaitForStores(ctx, []k8sutils.InformerSource{source}, func(stores []cache.Store) (bool, error) {
+		obj, exists, err := stores[0].GetByKey(name)
+		if err != nil || !exists {
+			return false, err
+		}
+		ns := obj.(*v1.Namespace)
+		if ns.Status.Phase == v1.NamespaceTerminating {
+			return false, fmt.Errorf("namespace %s is terminating", name)
+		}
+		if ns.Status.Phase != v1.NamespaceActive {
+			return false, nil
+		}
+		active = ns
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return nil, fmt.Errorf("timed out waiting for namespace %s to be active", name)
+	}
+	return active, err
+}
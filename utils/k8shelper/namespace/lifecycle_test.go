@@ -0,0 +1,183 @@
+package namespace
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	crutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/cr"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func namespace(name string, phase v1.NamespacePhase) *v1.Namespace {
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.NamespaceSpec{Finalizers: []v1.FinalizerName{v1.FinalizerKubernetes}},
+		Status:     v1.NamespaceStatus{Phase: phase},
+	}
+}
+
+func blackDuck(finalizers ...string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "synopsys.com/v1",
+		"kind":       "Blackduck",
+		"metadata":   map[string]interface{}{"name": "bd-one", "namespace": "bd-one"},
+	}}
+	u.SetFinalizers(finalizers)
+	return u
+}
+
+func TestCreate(t *testing.T) {
+	c := fake.NewSimpleClientset()
+	ns, err := Create(c, CreateOptions{Name: "cnt-bd-x7k2p", Owner: "synopsysctl", TTL: time.Hour, Labels: map[string]string{"run": "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsManaged(ns) || ns.Labels[OwnerLabel] != "synopsysctl" || ns.Labels["run"] != "1" {
+		t.Errorf("unexpected labels %v", ns.Labels)
+	}
+	if expired, err := Expired(ns, time.Now()); expired || err != nil {
+		t.Errorf("expected the namespace not to be expired yet, got %v, %v", expired, err)
+	}
+	if expired, err := Expired(ns, time.Now().Add(2*time.Hour)); !expired || err != nil {
+		t.Errorf("expected the namespace to be expired, got %v, %v", expired, err)
+	}
+	if IsManaged(namespace("default", v1.NamespaceActive)) {
+		t.Error("expected default not to be managed")
+	}
+}
+
+func TestOwnershipPatch(t *testing.T) {
+	c := fake.NewSimpleClientset(&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "synopsys-operator-admin", Labels: map[string]string{"app": "synopsys-operator"}}})
+	now := time.Now()
+	role, err := c.RbacV1().ClusterRoles().Patch("synopsys-operator-admin", types.MergePatchType, OwnershipPatch("synopsysctl", time.Hour, now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsManaged(role) || role.Labels[OwnerLabel] != "synopsysctl" || role.Labels["app"] != "synopsys-operator" {
+		t.Errorf("unexpected labels %v", role.Labels)
+	}
+	if expired, err := Expired(role, now.Add(2*time.Hour)); !expired || err != nil {
+		t.Errorf("expected the cluster role to be expired, got %v, %v", expired, err)
+	}
+}
+
+func TestWaitForActive(t *testing.T) {
+	c := fake.NewSimpleClientset()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		c.CoreV1().Namespaces().Create(namespace("alt-one", ""))
+		time.Sleep(100 * time.Millisecond)
+		c.CoreV1().Namespaces().Update(namespace("alt-one", v1.NamespaceActive))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ns, err := WaitForActive(ctx, c, "alt-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns.Status.Phase != v1.NamespaceActive {
+		t.Errorf("expected an active namespace, got %s", ns.Status.Phase)
+	}
+}
+
+func TestWaitForActiveTerminating(t *testing.T) {
+	c := fake.NewSimpleClientset(namespace("alt-one", v1.NamespaceTerminating))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := WaitForActive(ctx, c, "alt-one"); err == nil || !strings.Contains(err.Error(), "terminating") {
+		t.Errorf("expected a terminating error, got %v", err)
+	}
+}
+
+func TestWaitForNamespacesDeleted(t *testing.T) {
+	c := fake.NewSimpleClientset(namespace("alt-one", v1.NamespaceTerminating), namespace("bd-one", v1.NamespaceTerminating))
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		c.CoreV1().Namespaces().Delete("alt-one", nil)
+		time.Sleep(100 * time.Millisecond)
+		c.CoreV1().Namespaces().Delete("bd-one", nil)
+	}()
+	if err := WaitForNamespacesDeleted(c, []string{"alt-one", "bd-one"}, 5*time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWaitForNamespacesDeletedReportsBlockers(t *testing.T) {
+	c := fake.NewSimpleClientset(namespace("bd-one", v1.NamespaceTerminating))
+	c.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "synopsys.com/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "blackducks", Namespaced: true, Kind: "Blackduck", Verbs: []string{"get", "list"}},
+		},
+	}}
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), blackDuck("synopsys.com/finalizer"))
+	err := WaitForNamespacesDeletedWithReport(c, dc, []string{"bd-one"}, 200*time.Millisecond)
+	timeoutErr, ok := err.(*DeletionTimeoutError)
+	if !ok {
+		t.Fatalf("expected a *DeletionTimeoutError, got %v", err)
+	}
+	if len(timeoutErr.Reports) != 1 || len(timeoutErr.Reports[0].Remaining) != 1 {
+		t.Fatalf("unexpected reports %v", err)
+	}
+	for _, s := range []string{"namespace bd-one is Terminating", "namespace finalizers: kubernetes", "blackducks.synopsys.com/bd-one (finalizers: synopsys.com/finalizer)"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("expected %q in %q", s, err)
+		}
+	}
+}
+
+func TestRemoveCRFinalizers(t *testing.T) {
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), blackDuck("synopsys.com/finalizer"))
+	removed, err := RemoveCRFinalizers(dc, "bd-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0] != "blackducks.synopsys.com/bd-one" {
+		t.Errorf("unexpected removed %v", removed)
+	}
+	list, err := dc.Resource(crutils.GetBlackDuckSchema()).Namespace("bd-one").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finalizers := list.Items[0].GetFinalizers(); len(finalizers) != 0 {
+		t.Errorf("expected no finalizers, got %v", finalizers)
+	}
+}
+
+func TestRemoveCRFinalizersClusterScoped(t *testing.T) {
+	alert := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "synopsys.com/v1",
+		"kind":       "Alert",
+		"metadata":   map[string]interface{}{"name": "alt-one"},
+		"spec":       map[string]interface{}{"namespace": "alt-one"},
+	}}
+	alert.SetFinalizers([]string{"synopsys.com/finalizer"})
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), alert)
+	if removed, err := RemoveCRFinalizers(dc, "bd-one"); err != nil || len(removed) != 0 {
+		t.Fatalf("expected nothing removed in another namespace, got %v %v", removed, err)
+	}
+	removed, err := RemoveCRFinalizers(dc, "alt-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0] != "alerts.synopsys.com/alt-one" {
+		t.Errorf("unexpected removed %v", removed)
+	}
+	u, err := dc.Resource(crutils.GetAlertSchema()).Get("alt-one", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finalizers := u.GetFinalizers(); len(finalizers) != 0 {
+		t.Errorf("expected no finalizers, got %v", finalizers)
+	}
+}
@@ -1,31 +1,62 @@
 package namespace
 
 import (
+	"context"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 )
 
-// WaitForNamespacesDeleted waits for the namespaces to be deleted.
+// WaitForNamespacesDeleted waits for the namespaces to be deleted. A zero timeout uses the configured namespace deleted timeout.
+// On timeout it returns a *DeletionTimeoutError with the phase and finalizers of the remaining namespaces
 func WaitForNamespacesDeleted(c clientset.Interface, namespaces []string, timeout time.Duration) error {
-	nsMap := map[string]bool{}
-	for _, ns := range namespaces {
-		nsMap[ns] = true
+	return WaitForNamespacesDeletedWithReport(c, nil, namespaces, timeout)
+}
+
+// WaitForNamespacesDeletedWithReport is WaitForNamespacesDeleted that also reports the objects left in
+// the remaining namespaces, which are listed with dc
+func WaitForNamespacesDeletedWithReport(c clientset.Interface, dc dynamic.Interface, namespaces []string, timeout time.Duration) error {
+	if timeout == 0 {
+		timeout = config.Get().Timeouts.NamespaceDeleted.Duration
 	}
-	//Now POLL until all namespaces have been eradicated.
-	return wait.Poll(2*time.Second, timeout,
-		func() (bool, error) {
-			nsList, err := c.CoreV1().Namespaces().List(metav1.ListOptions{})
-			if err != nil {
+	logging.Debugf("Waiting up to %v for namespaces %v to be deleted", timeout, namespaces)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	sources := make([]k8sutils.InformerSource, len(namespaces))
+	for i, ns := range namespaces {
+		sources[i] = k8sutils.InformerSource{ListerWatcher: ListWatch(c, ns), Object: &v1.Namespace{}}
+	}
+	err := k8sutils.WaitForStores(ctx, sources, func(stores []cache.Store) (bool, error) {
+		for i, ns := range namespaces {
+			if _, exists, err := stores[i].GetByKey(ns); err != nil || exists {
 				return false, err
 			}
-			for _, item := range nsList.Items {
-				if _, ok := nsMap[item.Name]; ok {
-					return false, nil
-				}
-			}
-			return true, nil
-		})
+		}
+		return true, nil
+	})
+	if err != wait.ErrWaitTimeout {
+		return err
+	}
+	timeoutErr := &DeletionTimeoutError{Timeout: timeout}
+	for _, ns := range namespaces {
+		report, err := DescribeTermination(c, dc, ns)
+		if err != nil {
+			report = &TerminationReport{Namespace: ns, Errors: []string{err.Error()}}
+		}
+		if report != nil {
+			timeoutErr.Reports = append(timeoutErr.Reports, report)
+		}
+	}
+	if len(timeoutErr.Reports) == 0 {
+		// the namespaces were deleted after the timeout
+		return nil
+	}
+	return timeoutErr
 }
@@ -0,0 +1,144 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Reasons of pod and container states that rarely resolve without a change to the pod
+const (
+	ReasonImagePullBackOff           = "ImagePullBackOff"
+	ReasonErrImagePull               = "ErrImagePull"
+	ReasonCrashLoopBackOff           = "CrashLoopBackOff"
+	ReasonCreateContainerConfigError = "CreateContainerConfigError"
+	ReasonUnschedulable              = v1.PodReasonUnschedulable
+)
+
+var failureReasons = map[string]bool{
+	ReasonImagePullBackOff:           true,
+	ReasonErrImagePull:               true,
+	ReasonCrashLoopBackOff:           true,
+	ReasonCreateContainerConfigError: true,
+}
+
+// PodFailure is a pod, or one of its containers, stuck in a state it is unlikely to leave by itself
+type PodFailure struct {
+	Namespace string
+	Pod       string
+	// Container is empty if the whole pod is affected, e.g. when it cannot be scheduled
+	Container string
+	Reason    string
+	Message   string
+	// Since is when the state was first observed
+	Since time.Time
+}
+
+func (f *PodFailure) Error() string {
+	target := fmt.Sprintf("pod %s/%s", f.Namespace, f.Pod)
+	if f.Container != "" {
+		target = fmt.Sprintf("container %s of %s", f.Container, target)
+	}
+	msg := fmt.Sprintf("%s is in %s", target, f.Reason)
+	if !f.Since.IsZero() {
+		msg = fmt.Sprintf("%s since %s", msg, f.Since.Format(time.RFC3339))
+	}
+	if f.Message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, f.Message)
+	}
+	return msg
+}
+
+// ClassifyPod returns the failures of pod: init and regular containers waiting for one of the failure
+// reasons, and the pod being unschedulable
+func ClassifyPod(pod *v1.Pod) []*PodFailure {
+	failures := []*PodFailure{}
+	if _, condition := GetPodCondition(&pod.Status, v1.PodScheduled); condition != nil &&
+		condition.Status == v1.ConditionFalse && condition.Reason == ReasonUnschedulable {
+		failures = append(failures, &PodFailure{Namespace: pod.Namespace, Pod: pod.Name, Reason: condition.Reason, Message: condition.Message})
+	}
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil && failureReasons[waiting.Reason] {
+			failures = append(failures, &PodFailure{Namespace: pod.Namespace, Pod: pod.Name, Container: status.Name, Reason: waiting.Reason, Message: waiting.Message})
+		}
+	}
+	return failures
+}
+
+// failureTracker remembers when each failure was first seen so short-lived ones are tolerated
+type failureTracker struct {
+	grace     time.Duration
+	now       func() time.Time
+	firstSeen map[string]time.Time
+}
+
+func newFailureTracker(grace time.Duration) *failureTracker {
+	return &failureTracker{grace: grace, now: time.Now, firstSeen: map[string]time.Time{}}
+}
+
+// observe returns the first failure of pods that has lasted for at least the grace period. A container
+// flipping between ErrImagePull and ImagePullBackOff keeps its first seen time
+func (t *failureTracker) observe(pods []*v1.Pod) *PodFailure {
+	now := t.now()
+	seen := map[string]bool{}
+	var persisted *PodFailure
+	for _, pod := range pods {
+		for _, failure := range ClassifyPod(pod) {
+			key := fmt.Sprintf("%s/%s/%s", pod.UID, pod.Name, failure.Container)
+			seen[key] = true
+			since, ok := t.firstSeen[key]
+			if !ok {
+				since = now
+				t.firstSeen[key] = now
+			}
+			failure.Since = since
+			if persisted == nil && now.Sub(since) >= t.grace {
+				persisted = failure
+			}
+		}
+	}
+	for key := range t.firstSeen {
+		if !seen[key] {
+			delete(t.firstSeen, key)
+		}
+	}
+	return persisted
+}
+
+// FailOnPodFailures wraps condition so the wait stops with a *PodFailure as soon as one of the pods has
+// been failing, as classified by ClassifyPod, for at least grace
+func FailOnPodFailures(grace time.Duration, condition PodsCondition) PodsCondition {
+	tracker := newFailureTracker(grace)
+	return func(pods []*v1.Pod) (bool, error) {
+		if done, err := condition(pods); done || err != nil {
+			return done, err
+		}
+		if failure := tracker.observe(pods); failure != nil {
+			return false, failure
+		}
+		return false, nil
+	}
+}
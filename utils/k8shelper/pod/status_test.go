@@ -0,0 +1,98 @@
+package pod
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func waitingPod(name, reason string) *v1.Pod {
+	pod := testPod(name, false)
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{
+		Name:  "synopsys-operator",
+		State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: reason, Message: "back-off pulling image"}},
+	}}
+	return pod
+}
+
+func TestClassifyPod(t *testing.T) {
+	unschedulable := testPod("unschedulable", false)
+	unschedulable.Status.Conditions = []v1.PodCondition{{Type: v1.PodScheduled, Status: v1.ConditionFalse, Reason: ReasonUnschedulable, Message: "0/3 nodes are available"}}
+	initFailure := testPod("init", false)
+	initFailure.Status.InitContainerStatuses = []v1.ContainerStatus{{
+		Name:  "init",
+		State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: ReasonCrashLoopBackOff}},
+	}}
+
+	for _, tc := range []struct {
+		pod       *v1.Pod
+		container string
+		reason    string
+	}{
+		{testPod("ready", true), "", ""},
+		{waitingPod("creating", "ContainerCreating"), "", ""},
+		{waitingPod("pull", ReasonImagePullBackOff), "synopsys-operator", ReasonImagePullBackOff},
+		{waitingPod("err-pull", ReasonErrImagePull), "synopsys-operator", ReasonErrImagePull},
+		{waitingPod("config", ReasonCreateContainerConfigError), "synopsys-operator", ReasonCreateContainerConfigError},
+		{initFailure, "init", ReasonCrashLoopBackOff},
+		{unschedulable, "", ReasonUnschedulable},
+	} {
+		failures := ClassifyPod(tc.pod)
+		if tc.reason == "" {
+			if len(failures) != 0 {
+				t.Errorf("%s: unexpected failures %v", tc.pod.Name, failures)
+			}
+			continue
+		}
+		if len(failures) != 1 || failures[0].Reason != tc.reason || failures[0].Container != tc.container || failures[0].Pod != tc.pod.Name {
+			t.Errorf("%s: expected %s of container %q, got %v", tc.pod.Name, tc.reason, tc.container, failures)
+		}
+	}
+}
+
+func TestFailureTrackerGrace(t *testing.T) {
+	now := time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)
+	tracker := newFailureTracker(time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	if f := tracker.observe([]*v1.Pod{waitingPod("a", ReasonErrImagePull)}); f != nil {
+		t.Fatalf("a new failure was reported before the grace period: %v", f)
+	}
+	now = now.Add(30 * time.Second)
+	if f := tracker.observe([]*v1.Pod{waitingPod("a", ReasonImagePullBackOff)}); f != nil {
+		t.Fatalf("failure was reported before the grace period: %v", f)
+	}
+	now = now.Add(30 * time.Second)
+	f := tracker.observe([]*v1.Pod{waitingPod("a", ReasonImagePullBackOff)})
+	if f == nil {
+		t.Fatal("a failure lasting the grace period was not reported")
+	}
+	if f.Reason != ReasonImagePullBackOff || f.Since != now.Add(-time.Minute) {
+		t.Errorf("unexpected failure %v", f)
+	}
+
+	// a recovered container starts over
+	tracker.observe([]*v1.Pod{testPod("a", true)})
+	if f := tracker.observe([]*v1.Pod{waitingPod("a", ReasonCrashLoopBackOff)}); f != nil {
+		t.Errorf("a failure that recovered in between was reported: %v", f)
+	}
+}
+
+func TestWaitForPodsConditionFailsFast(t *testing.T) {
+	c := fake.NewSimpleClientset(waitingPod("a", ReasonImagePullBackOff))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := WaitForPodsCondition(ctx, c, "ns", testLabel, FailOnPodFailures(0, func([]*v1.Pod) (bool, error) {
+		return false, nil
+	}))
+	failure, ok := err.(*PodFailure)
+	if !ok {
+		t.Fatalf("expected a *PodFailure, got %v", err)
+	}
+	if failure.Pod != "a" || failure.Container != "synopsys-operator" || failure.Reason != ReasonImagePullBackOff {
+		t.Errorf("unexpected failure %v", failure)
+	}
+}
@@ -3,30 +3,16 @@
 package pod
 
 import (
+	"context"
 	"fmt"
 	"time"
 
-	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
-	"github.com/sirupsen/logrus"
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
 	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 )
 
-// NewLogger returns a *logrus.Logger
-func NewLogger() *logrus.Logger {
-	newLog := logrus.New()
-	newLog.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp:   true,
-		TimestampFormat: "2006-01-02 15:04:05",
-	})
-	newLog.SetLevel(logrus.DebugLevel)
-	newLog.SetReportCaller(true)
-	return newLog
-}
-
 const (
 	// defaultPodDeletionTimeout is the default timeout for deleting pod.
 	defaultPodDeletionTimeout = 3 * time.Minute
@@ -61,77 +47,29 @@ const (
 type podCondition func(pod *v1.Pod) (bool, error)
 
 // WaitForPodsWithLabelRunningReady waits for exact amount of matching pods to become running and ready.
-// Return the list of matching pods.
-func WaitForPodsWithLabelRunningReady(c clientset.Interface, ns string, label labels.Selector, num int, timeout time.Duration) (pods *v1.PodList, err error) {
-	var current int
-	err = wait.Poll(poll, timeout,
-		func() (bool, error) {
-			pods, err = WaitForPodsWithLabel(c, ns, label)
-			if err != nil {
-				// e2elog.Logf("Failed to list pods: %v", err)
-				fmt.Printf("Failed to list pods: %v", err)
-				if k8sutils.IsRetryableAPIError(err) {
-					return false, nil
-				}
-				return false, err
-			}
-			current = 0
-			for _, pod := range pods.Items {
-				if flag, err := PodRunningReady(&pod); err == nil && flag == true {
-					current++
-				}
-			}
-			if current != num {
-				// e2elog.Logf("Got %v pods running and ready, expect: %v", current, num)
-				fmt.Printf("Got %v pods running and ready, expect: %v", current, num)
-				return false, nil
-			}
-			return true, nil
-		})
-	return pods, err
+// Return the list of matching pods. A zero timeout uses the configured pod ready timeout.
+// WaitForPodsWithLabelRunningReadyContext logs to the logger of its context instead of the default one.
+func WaitForPodsWithLabelRunningReady(c clientset.Interface, ns string, label labels.Selector, num int, timeout time.Duration) (*v1.PodList, error) {
+	if timeout == 0 {
+		timeout = config.Get().Timeouts.PodReady.Duration
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return WaitForPodsWithLabelRunningReadyContext(ctx, c, ns, label, num)
 }
 
-// WaitForPodsWithLabelDeleted waits up to podListTimeout for pods with certain label to not exist
-// NOTE: Modified WaitForPodsWithLabel below
-func WaitForPodsWithLabelDeleted(c clientset.Interface, ns string, label labels.Selector) (pods *v1.PodList, err error) {
-	for t := time.Now(); time.Since(t) < podListTimeout; time.Sleep(poll) {
-		options := metav1.ListOptions{LabelSelector: label.String()}
-		pods, err = c.CoreV1().Pods(ns).List(options)
-		if err != nil {
-			if k8sutils.IsRetryableAPIError(err) {
-				continue
-			}
-			return
-		}
-		if len(pods.Items) == 0 {
-			break
-		}
-	}
-	if pods == nil || len(pods.Items) > 0 {
-		err = fmt.Errorf("Timeout while waiting for pods with label %v", label)
-	}
-	return
+// WaitForPodsWithLabelDeleted waits up to the configured pod list timeout for pods with certain label to not exist
+func WaitForPodsWithLabelDeleted(c clientset.Interface, ns string, label labels.Selector) (*v1.PodList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.PodList.Duration)
+	defer cancel()
+	return WaitForPodsWithLabelDeletedContext(ctx, c, ns, label)
 }
 
-// WaitForPodsWithLabel waits up to podListTimeout for getting pods with certain label
-func WaitForPodsWithLabel(c clientset.Interface, ns string, label labels.Selector) (pods *v1.PodList, err error) {
-	for t := time.Now(); time.Since(t) < podListTimeout; time.Sleep(poll) {
-		options := metav1.ListOptions{LabelSelector: label.String()}
-		pods, err = c.CoreV1().Pods(ns).List(options)
-		if err != nil {
-			if k8sutils.IsRetryableAPIError(err) {
-				continue
-			}
-			return
-		}
-		if len(pods.Items) > 0 {
-			break
-		}
-	}
-	if pods == nil || len(pods.Items) == 0 {
-		err = fmt.Errorf("Timeout while waiting for pods with label %v", label)
-	}
-	return
+// WaitForPodsWithLabel waits up to the configured pod list timeout for getting pods with certain label
+func WaitForPodsWithLabel(c clientset.Interface, ns string, label labels.Selector) (*v1.PodList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.PodList.Duration)
+	defer cancel()
+	return WaitForPodsWithLabelContext(ctx, c, ns, label)
 }
 
 // PodRunningReady checks whether pod p's phase is running and it has a ready
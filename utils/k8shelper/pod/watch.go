@@ -0,0 +1,124 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PodsCondition reports whether the pods currently matching a selector are in the wanted state.
+// Returning an error stops the wait
+type PodsCondition func(pods []*v1.Pod) (bool, error)
+
+// WaitForPodsCondition lists the pods in ns matching label, then watches them until condition is true
+// for the pods that exist at that moment, condition returns an error or ctx is done. The condition is
+// checked as soon as the pods change, see k8shelper.WaitForStores
+func WaitForPodsCondition(ctx context.Context, c clientset.Interface, ns string, label labels.Selector, condition PodsCondition) ([]*v1.Pod, error) {
+	client := c.CoreV1().Pods(ns)
+	list := func(options metav1.ListOptions) (runtime.Object, error) {
+		return client.List(options)
+	}
+	lw := k8sutils.ListWatch(list, client.Watch, func(options *metav1.ListOptions) {
+		options.LabelSelector = label.String()
+	})
+	var pods []*v1.Pod
+	err := k8sutils.WaitForStores(ctx, []k8sutils.InformerSource{{ListerWatcher: lw, Object: &v1.Pod{}}}, func(stores []cache.Store) (bool, error) {
+		pods = podsIn(stores[0], label)
+		return condition(pods)
+	})
+	if err == wait.ErrWaitTimeout {
+		return pods, fmt.Errorf("timed out waiting for pods with label %v in namespace %s", label, ns)
+	}
+	return pods, err
+}
+
+// podsIn returns the pods of store matching label, sorted by name. The label is checked again because
+// not every client filters watch events by label
+func podsIn(store cache.Store, label labels.Selector) []*v1.Pod {
+	pods := []*v1.Pod{}
+	for _, obj := range store.List() {
+		if pod, ok := obj.(*v1.Pod); ok && label.Matches(labels.Set(pod.Labels)) {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods
+}
+
+func podList(pods []*v1.Pod) *v1.PodList {
+	list := &v1.PodList{}
+	for _, pod := range pods {
+		list.Items = append(list.Items, *pod)
+	}
+	return list
+}
+
+// WaitForPodsWithLabelRunningReadyContext waits until exactly num pods matching label are running and ready.
+// It returns a *PodFailure once a pod has been failing for longer than the configured pod failure grace.
+// The progress is logged to the logger of ctx, see logging.NewContext
+func WaitForPodsWithLabelRunningReadyContext(ctx context.Context, c clientset.Interface, ns string, label labels.Selector, num int) (*v1.PodList, error) {
+	last := -1
+	grace := config.Get().Timeouts.PodFailureGrace.Duration
+	pods, err := WaitForPodsCondition(ctx, c, ns, label, FailOnPodFailures(grace, func(pods []*v1.Pod) (bool, error) {
+		current := 0
+		for _, pod := range pods {
+			if ready, err := PodRunningReady(pod); err == nil && ready {
+				current++
+			}
+		}
+		if current != last {
+			logging.FromContext(ctx).Infof("Got %v pods running and ready, expect: %v", current, num)
+			last = current
+		}
+		return current == num, nil
+	}))
+	return podList(pods), err
+}
+
+// WaitForPodsWithLabelContext waits until at least one pod matches label
+func WaitForPodsWithLabelContext(ctx context.Context, c clientset.Interface, ns string, label labels.Selector) (*v1.PodList, error) {
+	pods, err := WaitForPodsCondition(ctx, c, ns, label, func(pods []*v1.Pod) (bool, error) {
+		return len(pods) > 0, nil
+	})
+	return podList(pods), err
+}
+
+// WaitForPodsWithLabelDeletedContext waits until no pod matches label
+func WaitForPodsWithLabelDeletedContext(ctx context.Context, c clientset.Interface, ns string, label labels.Selector) (*v1.PodList, error) {
+	pods, err := WaitForPodsCondition(ctx, c, ns, label, func(pods []*v1.Pod) (bool, error) {
+		return len(pods) == 0, nil
+	})
+	return podList(pods), err
+}
@@ -0,0 +1,88 @@
+package pod
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var testLabel = labels.SelectorFromSet(labels.Set{"app": "synopsys-operator"})
+
+func testPod(name string, ready bool) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: map[string]string{"app": "synopsys-operator"}},
+		Status:     v1.PodStatus{Phase: v1.PodPending},
+	}
+	if ready {
+		pod.Status.Phase = v1.PodRunning
+		pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+	}
+	return pod
+}
+
+func TestWaitForPodsWithLabelRunningReadyContextReactsToUpdates(t *testing.T) {
+	c := fake.NewSimpleClientset(testPod("a", true), testPod("b", false))
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		c.CoreV1().Pods("ns").Update(testPod("b", true))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	pods, err := WaitForPodsWithLabelRunningReadyContext(ctx, c, "ns", testLabel, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 2 {
+		t.Errorf("expected 2 pods, got %d", len(pods.Items))
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("the update was noticed after %v", elapsed)
+	}
+}
+
+func TestWaitForPodsWithLabelRunningReadyContextTimesOut(t *testing.T) {
+	c := fake.NewSimpleClientset(testPod("a", false))
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	pods, err := WaitForPodsWithLabelRunningReadyContext(ctx, c, "ns", testLabel, 1)
+	if err == nil {
+		t.Fatal("expected a timeout")
+	}
+	if len(pods.Items) != 1 {
+		t.Errorf("expected the pods seen last, got %d", len(pods.Items))
+	}
+}
+
+func TestWaitForPodsWithLabelDeletedContextRelistsAfterExpiredWatch(t *testing.T) {
+	c := fake.NewSimpleClientset(testPod("a", true))
+	var watches int32
+	c.PrependWatchReactor("pods", func(k8stesting.Action) (bool, watch.Interface, error) {
+		if atomic.AddInt32(&watches, 1) > 1 {
+			return false, nil, nil
+		}
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Error(&metav1.Status{Status: metav1.StatusFailure, Code: 410, Reason: metav1.StatusReasonExpired})
+		return true, w, nil
+	})
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		c.CoreV1().Pods("ns").Delete("a", nil)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := WaitForPodsWithLabelDeletedContext(ctx, c, "ns", testLabel); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&watches) < 2 {
+		t.Errorf("expected the expired watch to be restarted, got %d watches", watches)
+	}
+}
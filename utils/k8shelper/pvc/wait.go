@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
 	v1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,41 +31,38 @@ import (
 )
 
 // WaitForPersistentVolumeDeleted waits for a PersistentVolume to get deleted or until timeout occurs, whichever comes first.
+// A zero Poll or timeout uses the configured poll interval or PVC timeout.
 func WaitForPersistentVolumeDeleted(c clientset.Interface, pvName string, Poll, timeout time.Duration) error {
-	// e2elog.Logf("Waiting up to %v for PersistentVolume %s to get deleted", timeout, pvName)
-	fmt.Printf("Waiting up to %v for PersistentVolume %s to get deleted", timeout, pvName)
+	Poll, timeout = defaultPollAndTimeout(Poll, timeout)
+	logging.Infof("Waiting up to %v for PersistentVolume %s to get deleted", timeout, pvName)
 	for start := time.Now(); time.Since(start) < timeout; time.Sleep(Poll) {
 		pv, err := c.CoreV1().PersistentVolumes().Get(pvName, metav1.GetOptions{})
 		if err == nil {
-			// e2elog.Logf("PersistentVolume %s found and phase=%s (%v)", pvName, pv.Status.Phase, time.Since(start))
-			fmt.Printf("PersistentVolume %s found and phase=%s (%v)", pvName, pv.Status.Phase, time.Since(start))
+			logging.Debugf("PersistentVolume %s found and phase=%s (%v)", pvName, pv.Status.Phase, time.Since(start))
 			continue
 		}
 		if apierrs.IsNotFound(err) {
-			// e2elog.Logf("PersistentVolume %s was removed", pvName)
-			fmt.Printf("PersistentVolume %s was removed", pvName)
+			logging.Debugf("PersistentVolume %s was removed", pvName)
 			return nil
 		}
-		// e2elog.Logf("Get persistent volume %s in failed, ignoring for %v: %v", pvName, Poll, err)
-		fmt.Printf("Get persistent volume %s in failed, ignoring for %v: %v", pvName, Poll, err)
+		logging.Warnf("Get persistent volume %s in failed, ignoring for %v: %v", pvName, Poll, err)
 	}
 	return fmt.Errorf("PersistentVolume %s still exists within %v", pvName, timeout)
 }
 
 // WaitForPersistentVolumeClaimDeleted waits for a PersistentVolumeClaim to be removed from the system until timeout occurs, whichever comes first.
+// A zero Poll or timeout uses the configured poll interval or PVC timeout.
 func WaitForPersistentVolumeClaimDeleted(c clientset.Interface, ns string, pvcName string, Poll, timeout time.Duration) error {
-	// e2elog.Logf("Waiting up to %v for PersistentVolumeClaim %s to be removed", timeout, pvcName)
-	fmt.Printf("Waiting up to %v for PersistentVolumeClaim %s to be removed", timeout, pvcName)
+	Poll, timeout = defaultPollAndTimeout(Poll, timeout)
+	logging.Infof("Waiting up to %v for PersistentVolumeClaim %s to be removed", timeout, pvcName)
 	for start := time.Now(); time.Since(start) < timeout; time.Sleep(Poll) {
 		_, err := c.CoreV1().PersistentVolumeClaims(ns).Get(pvcName, metav1.GetOptions{})
 		if err != nil {
 			if apierrs.IsNotFound(err) {
-				// e2elog.Logf("Claim %q in namespace %q doesn't exist in the system", pvcName, ns)
-				fmt.Printf("Claim %q in namespace %q doesn't exist in the system", pvcName, ns)
+				logging.Debugf("Claim %q in namespace %q doesn't exist in the system", pvcName, ns)
 				return nil
 			}
-			// e2elog.Logf("Failed to get claim %q in namespace %q, retrying in %v. Error: %v", pvcName, ns, Poll, err)
-			fmt.Printf("Failed to get claim %q in namespace %q, retrying in %v. Error: %v", pvcName, ns, Poll, err)
+			logging.Warnf("Failed to get claim %q in namespace %q, retrying in %v. Error: %v", pvcName, ns, Poll, err)
 		}
 	}
 	return fmt.Errorf("PersistentVolumeClaim %s is not removed from the system within %v", pvcName, timeout)
@@ -76,30 +75,28 @@ func WaitForPersistentVolumeClaimPhase(phase v1.PersistentVolumeClaimPhase, c cl
 
 // WaitForPersistentVolumeClaimsPhase waits for any (if matchAny is true) or all (if matchAny is false) PersistentVolumeClaims
 // to be in a specific phase or until timeout occurs, whichever comes first.
+// A zero Poll or timeout uses the configured poll interval or PVC timeout.
 func WaitForPersistentVolumeClaimsPhase(phase v1.PersistentVolumeClaimPhase, c clientset.Interface, ns string, pvcNames []string, Poll, timeout time.Duration, matchAny bool) error {
+	Poll, timeout = defaultPollAndTimeout(Poll, timeout)
 	if len(pvcNames) == 0 {
 		return fmt.Errorf("Incorrect parameter: Need at least one PVC to track. Found 0")
 	}
-	// e2elog.Logf("Waiting up to %v for PersistentVolumeClaims %v to have phase %s", timeout, pvcNames, phase)
-	fmt.Printf("Waiting up to %v for PersistentVolumeClaims %v to have phase %s", timeout, pvcNames, phase)
+	logging.Infof("Waiting up to %v for PersistentVolumeClaims %v to have phase %s", timeout, pvcNames, phase)
 	for start := time.Now(); time.Since(start) < timeout; time.Sleep(Poll) {
 		phaseFoundInAllClaims := true
 		for _, pvcName := range pvcNames {
 			pvc, err := c.CoreV1().PersistentVolumeClaims(ns).Get(pvcName, metav1.GetOptions{})
 			if err != nil {
-				// e2elog.Logf("Failed to get claim %q, retrying in %v. Error: %v", pvcName, Poll, err)
-				fmt.Printf("Failed to get claim %q, retrying in %v. Error: %v", pvcName, Poll, err)
+				logging.Warnf("Failed to get claim %q, retrying in %v. Error: %v", pvcName, Poll, err)
 				continue
 			}
 			if pvc.Status.Phase == phase {
-				// e2elog.Logf("PersistentVolumeClaim %s found and phase=%s (%v)", pvcName, phase, time.Since(start))
-				fmt.Printf("PersistentVolumeClaim %s found and phase=%s (%v)", pvcName, phase, time.Since(start))
+				logging.Debugf("PersistentVolumeClaim %s found and phase=%s (%v)", pvcName, phase, time.Since(start))
 				if matchAny {
 					return nil
 				}
 			} else {
-				// e2elog.Logf("PersistentVolumeClaim %s found but phase is %s instead of %s.", pvcName, pvc.Status.Phase, phase)
-				fmt.Printf("PersistentVolumeClaim %s found but phase is %s instead of %s.", pvcName, pvc.Status.Phase, phase)
+				logging.Debugf("PersistentVolumeClaim %s found but phase is %s instead of %s.", pvcName, pvc.Status.Phase, phase)
 				phaseFoundInAllClaims = false
 			}
 		}
@@ -109,3 +106,14 @@ func WaitForPersistentVolumeClaimsPhase(phase v1.PersistentVolumeClaimPhase, c c
 	}
 	return fmt.Errorf("PersistentVolumeClaims %v not all in phase %s within %v", pvcNames, phase, timeout)
 }
+
+func defaultPollAndTimeout(Poll, timeout time.Duration) (time.Duration, time.Duration) {
+	timeouts := config.Get().Timeouts
+	if Poll == 0 {
+		Poll = timeouts.Poll.Duration
+	}
+	if timeout == 0 {
+		timeout = timeouts.PVC.Duration
+	}
+	return Poll, timeout
+}
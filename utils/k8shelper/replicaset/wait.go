@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package replicaset
+
+import (
+	"context"
+	"fmt"
+
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ListWatch lists and watches the replica sets in namespace, with options changed by tweak if it is not nil
+func ListWatch(c clientset.Interface, namespace string, tweak func(*metav1.ListOptions)) cache.ListerWatcher {
+	client := c.AppsV1().ReplicaSets(namespace)
+	list := func(options metav1.ListOptions) (runtime.Object, error) {
+		return client.List(options)
+	}
+	return k8sutils.ListWatch(list, client.Watch, tweak)
+}
+
+// RolloutStatus describes the rollout of rs and returns whether all of its replicas exist and are available
+func RolloutStatus(rs *appsv1.ReplicaSet) (string, bool, error) {
+	want := k8sutils.Replicas(rs.Spec.Replicas)
+	switch {
+	case rs.Generation > rs.Status.ObservedGeneration:
+		return fmt.Sprintf("waiting for replicaset %q spec update to be observed", rs.Name), false, nil
+	case rs.Status.Replicas != want:
+		return fmt.Sprintf("waiting for replicaset %q to have %d replicas, it has %d", rs.Name, want, rs.Status.Replicas), false, nil
+	case rs.Status.ReadyReplicas < want:
+		return fmt.Sprintf("waiting for replicaset %q rollout to finish: %d of %d replicas are ready", rs.Name, rs.Status.ReadyReplicas, want), false, nil
+	case rs.Status.AvailableReplicas < want:
+		return fmt.Sprintf("waiting for replicaset %q rollout to finish: %d of %d replicas are available", rs.Name, rs.Status.AvailableReplicas, want), false, nil
+	}
+	return fmt.Sprintf("replicaset %q has %d available replicas", rs.Name, rs.Status.AvailableReplicas), true, nil
+}
+
+// ScaledToZeroStatus describes the scale down of rs and returns whether it has no replicas left
+func ScaledToZeroStatus(rs *appsv1.ReplicaSet) (string, bool, error) {
+	want := k8sutils.Replicas(rs.Spec.Replicas)
+	switch {
+	case want != 0:
+		return fmt.Sprintf("replicaset %q wants %d replicas", rs.Name, want), false, nil
+	case rs.Generation > rs.Status.ObservedGeneration:
+		return fmt.Sprintf("waiting for replicaset %q spec update to be observed", rs.Name), false, nil
+	case rs.Status.Replicas > 0:
+		return fmt.Sprintf("waiting for %d replicas of replicaset %q to terminate", rs.Status.Replicas, rs.Name), false, nil
+	}
+	return fmt.Sprintf("replicaset %q is scaled to zero", rs.Name), true, nil
+}
+
+// WaitForRollout waits until all replicas of the replica set exist and are available
+func WaitForRollout(ctx context.Context, c clientset.Interface, namespace, name string) (*appsv1.ReplicaSet, error) {
+	return waitFor(ctx, c, namespace, name, RolloutStatus)
+}
+
+// WaitForScaledToZero waits until the replica set is scaled to zero and all of its replicas are gone
+func WaitForScaledToZero(ctx context.Context, c clientset.Interface, namespace, name string) (*appsv1.ReplicaSet, error) {
+	return waitFor(ctx, c, namespace, name, ScaledToZeroStatus)
+}
+
+func waitFor(ctx context.Context, c clientset.Interface, namespace, name string, status func(*appsv1.ReplicaSet) (string, bool, error)) (*appsv1.ReplicaSet, error) {
+	listWatch := func(tweak func(*metav1.ListOptions)) cache.ListerWatcher {
+		return ListWatch(c, namespace, tweak)
+	}
+	obj, err := k8sutils.WaitForObject(ctx, listWatch, &appsv1.ReplicaSet{}, "replicaset", namespace, name, func(obj interface{}) (string, bool, error) {
+		return status(obj.(*appsv1.ReplicaSet))
+	})
+	rs, _ := obj.(*appsv1.ReplicaSet)
+	return rs, err
+}
@@ -0,0 +1,65 @@
+package replicaset
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testReplicaSet(want int32, status appsv1.ReplicaSetStatus) *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "alert", Namespace: "ns", Generation: 2},
+		Spec:       appsv1.ReplicaSetSpec{Replicas: &want},
+		Status:     status,
+	}
+}
+
+func TestRolloutStatus(t *testing.T) {
+	for _, tc := range []struct {
+		status appsv1.ReplicaSetStatus
+		done   bool
+		msg    string
+	}{
+		{appsv1.ReplicaSetStatus{ObservedGeneration: 1, Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 2}, false, "spec update to be observed"},
+		{appsv1.ReplicaSetStatus{ObservedGeneration: 2, Replicas: 1}, false, "to have 2 replicas, it has 1"},
+		{appsv1.ReplicaSetStatus{ObservedGeneration: 2, Replicas: 2, ReadyReplicas: 1}, false, "1 of 2 replicas are ready"},
+		{appsv1.ReplicaSetStatus{ObservedGeneration: 2, Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 1}, false, "1 of 2 replicas are available"},
+		{appsv1.ReplicaSetStatus{ObservedGeneration: 2, Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 2}, true, "has 2 available replicas"},
+	} {
+		msg, done, err := RolloutStatus(testReplicaSet(2, tc.status))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done != tc.done || !strings.Contains(msg, tc.msg) {
+			t.Errorf("%+v: expected %v and %q, got %v and %q", tc.status, tc.done, tc.msg, done, msg)
+		}
+	}
+}
+
+func TestWaitForRollout(t *testing.T) {
+	c := fake.NewSimpleClientset(testReplicaSet(1, appsv1.ReplicaSetStatus{ObservedGeneration: 1}))
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		c.AppsV1().ReplicaSets("ns").UpdateStatus(testReplicaSet(1, appsv1.ReplicaSetStatus{ObservedGeneration: 2, Replicas: 1, ReadyReplicas: 1, AvailableReplicas: 1}))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rs, err := WaitForRollout(ctx, c, "ns", "alert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Status.AvailableReplicas != 1 {
+		t.Errorf("expected the last version of the replica set, got %+v", rs.Status)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := WaitForScaledToZero(ctx, c, "ns", "alert"); err == nil || !strings.Contains(err.Error(), `replicaset "alert" wants 1 replicas`) {
+		t.Errorf("expected a timeout with the last status, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package replicationcontroller
+
+import (
+	"context"
+	"fmt"
+
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ListWatch lists and watches the replication controllers in namespace, with options changed by tweak if it is not nil
+func ListWatch(c clientset.Interface, namespace string, tweak func(*metav1.ListOptions)) cache.ListerWatcher {
+	client := c.CoreV1().ReplicationControllers(namespace)
+	list := func(options metav1.ListOptions) (runtime.Object, error) {
+		return client.List(options)
+	}
+	return k8sutils.ListWatch(list, client.Watch, tweak)
+}
+
+// RolloutStatus describes the rollout of rc and returns whether all of its replicas exist and are available
+func RolloutStatus(rc *v1.ReplicationController) (string, bool, error) {
+	want := k8sutils.Replicas(rc.Spec.Replicas)
+	switch {
+	case rc.Generation > rc.Status.ObservedGeneration:
+		return fmt.Sprintf("waiting for replicationcontroller %q spec update to be observed", rc.Name), false, nil
+	case rc.Status.Replicas != want:
+		return fmt.Sprintf("waiting for replicationcontroller %q to have %d replicas, it has %d", rc.Name, want, rc.Status.Replicas), false, nil
+	case rc.Status.ReadyReplicas < want:
+		return fmt.Sprintf("waiting for replicationcontroller %q rollout to finish: %d of %d replicas are ready", rc.Name, rc.Status.ReadyReplicas, want), false, nil
+	case rc.Status.AvailableReplicas < want:
+		return fmt.Sprintf("waiting for replicationcontroller %q rollout to finish: %d of %d replicas are available", rc.Name, rc.Status.AvailableReplicas, want), false, nil
+	}
+	return fmt.Sprintf("replicationcontroller %q has %d available replicas", rc.Name, rc.Status.AvailableReplicas), true, nil
+}
+
+// ScaledToZeroStatus describes the scale down of rc and returns whether it has no replicas left
+func ScaledToZeroStatus(rc *v1.ReplicationController) (string, bool, error) {
+	want := k8sutils.Replicas(rc.Spec.Replicas)
+	switch {
+	case want != 0:
+		return fmt.Sprintf("replicationcontroller %q wants %d replicas", rc.Name, want), false, nil
+	case rc.Generation > rc.Status.ObservedGeneration:
+		return fmt.Sprintf("waiting for replicationcontroller %q spec update to be observed", rc.Name), false, nil
+	case rc.Status.Replicas > 0:
+		return fmt.Sprintf("waiting for %d replicas of replicationcontroller %q to terminate", rc.Status.Replicas, rc.Name), false, nil
+	}
+	return fmt.Sprintf("replicationcontroller %q is scaled to zero", rc.Name), true, nil
+}
+
+// WaitForRollout waits until all replicas of the replication controller exist and are available
+func WaitForRollout(ctx context.Context, c clientset.Interface, namespace, name string) (*v1.ReplicationController, error) {
+	return waitFor(ctx, c, namespace, name, RolloutStatus)
+}
+
+// WaitForScaledToZero waits until the replication controller is scaled to zero and all of its replicas are gone
+func WaitForScaledToZero(ctx context.Context, c clientset.Interface, namespace, name string) (*v1.ReplicationController, error) {
+	return waitFor(ctx, c, namespace, name, ScaledToZeroStatus)
+}
+
+func waitFor(ctx context.Context, c clientset.Interface, namespace, name string, status func(*v1.ReplicationController) (string, bool, error)) (*v1.ReplicationController, error) {
+	listWatch := func(tweak func(*metav1.ListOptions)) cache.ListerWatcher {
+		return ListWatch(c, namespace, tweak)
+	}
+	obj, err := k8sutils.WaitForObject(ctx, listWatch, &v1.ReplicationController{}, "replicationcontroller", namespace, name, func(obj interface{}) (string, bool, error) {
+		return status(obj.(*v1.ReplicationController))
+	})
+	rc, _ := obj.(*v1.ReplicationController)
+	return rc, err
+}
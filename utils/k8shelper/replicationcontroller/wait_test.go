@@ -0,0 +1,61 @@
+package replicationcontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testRC(want int32, status v1.ReplicationControllerStatus) *v1.ReplicationController {
+	return &v1.ReplicationController{
+		ObjectMeta: metav1.ObjectMeta{Name: "alert", Namespace: "ns", Generation: 2},
+		Spec:       v1.ReplicationControllerSpec{Replicas: &want},
+		Status:     status,
+	}
+}
+
+func TestRolloutStatus(t *testing.T) {
+	for _, tc := range []struct {
+		status v1.ReplicationControllerStatus
+		done   bool
+		msg    string
+	}{
+		{v1.ReplicationControllerStatus{ObservedGeneration: 1, Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 2}, false, "spec update to be observed"},
+		{v1.ReplicationControllerStatus{ObservedGeneration: 2, Replicas: 3}, false, "to have 2 replicas, it has 3"},
+		{v1.ReplicationControllerStatus{ObservedGeneration: 2, Replicas: 2, ReadyReplicas: 1}, false, "1 of 2 replicas are ready"},
+		{v1.ReplicationControllerStatus{ObservedGeneration: 2, Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 1}, false, "1 of 2 replicas are available"},
+		{v1.ReplicationControllerStatus{ObservedGeneration: 2, Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 2}, true, "has 2 available replicas"},
+	} {
+		msg, done, err := RolloutStatus(testRC(2, tc.status))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done != tc.done || !strings.Contains(msg, tc.msg) {
+			t.Errorf("%+v: expected %v and %q, got %v and %q", tc.status, tc.done, tc.msg, done, msg)
+		}
+	}
+}
+
+func TestWaitForScaledToZero(t *testing.T) {
+	c := fake.NewSimpleClientset(testRC(0, v1.ReplicationControllerStatus{ObservedGeneration: 2, Replicas: 1}))
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		c.CoreV1().ReplicationControllers("ns").UpdateStatus(testRC(0, v1.ReplicationControllerStatus{ObservedGeneration: 2}))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := WaitForScaledToZero(ctx, c, "ns", "alert"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := WaitForRollout(ctx, c, "ns", "missing"); err == nil || !strings.Contains(err.Error(), `replicationcontroller "missing" was not found`) {
+		t.Errorf("expected a timeout for a missing replication controller, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package k8shelper
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ListWatch lists and watches with list and watchFunc, with options changed by tweak if it is not nil.
+// Every informer based waiter builds its ListerWatcher from it
+func ListWatch(list func(metav1.ListOptions) (runtime.Object, error), watchFunc func(metav1.ListOptions) (watch.Interface, error), tweak func(*metav1.ListOptions)) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			if tweak != nil {
+				tweak(&options)
+			}
+			return list(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			if tweak != nil {
+				tweak(&options)
+			}
+			return watchFunc(options)
+		},
+	}
+}
+
+// SelectName is a tweak for ListWatch that selects the object name only
+func SelectName(name string) func(*metav1.ListOptions) {
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	return func(options *metav1.ListOptions) {
+		options.FieldSelector = selector
+	}
+}
+
+// Replicas returns the desired replicas of a workload, which default to 1
+func Replicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// ObjectStatus describes an object and returns whether it is in the wanted state. Returning an error
+// stops the wait
+type ObjectStatus func(obj interface{}) (string, bool, error)
+
+// WaitForObject watches the object namespace/name of kind, e.g. "deployment", until status is done.
+// listWatch returns the ListerWatcher of the kind with tweak applied, and object is an empty object of
+// the kind. It returns the last version of the object it saw, or nil, and on timeout an error with the
+// last message of status
+func WaitForObject(ctx context.Context, listWatch func(tweak func(*metav1.ListOptions)) cache.ListerWatcher, object runtime.Object, kind, namespace, name string, status ObjectStatus) (interface{}, error) {
+	lw := listWatch(SelectName(name))
+	var last interface{}
+	msg := fmt.Sprintf("%s %q was not found", kind, name)
+	err := WaitForStores(ctx, []InformerSource{{ListerWatcher: lw, Object: object}}, func(stores []cache.Store) (bool, error) {
+		obj, exists, err := stores[0].GetByKey(namespace + "/" + name)
+		if err != nil || !exists {
+			return false, err
+		}
+		last = obj
+		var done bool
+		msg, done, err = status(obj)
+		return done, err
+	})
+	if err == wait.ErrWaitTimeout {
+		return last, fmt.Errorf("timed out in namespace %s: %s", namespace, msg)
+	}
+	return last, err
+}
@@ -22,30 +22,34 @@ import (
 	"fmt"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
-	clientset "k8s.io/client-go/kubernetes"
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
 	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
 )
 
 // WaitForService waits until the service appears (exist == true), or disappears (exist == false)
+// A zero interval or timeout uses the configured poll interval or service timeout
 func WaitForService(c clientset.Interface, namespace, name string, exist bool, interval, timeout time.Duration) error {
+	interval, timeout = defaultIntervalAndTimeout(interval, timeout)
 	err := wait.PollImmediate(interval, timeout, func() (bool, error) {
 		_, err := c.CoreV1().Services(namespace).Get(name, metav1.GetOptions{})
 		switch {
 		case err == nil:
-			// e2elog.Logf("Service %s in namespace %s found.", name, namespace)
+			logging.Debugf("Service %s in namespace %s found.", name, namespace)
 			return exist, nil
 		case apierrs.IsNotFound(err):
-			// e2elog.Logf("Service %s in namespace %s disappeared.", name, namespace)
+			logging.Debugf("Service %s in namespace %s disappeared.", name, namespace)
 			return !exist, nil
 		case !k8sutils.IsRetryableAPIError(err):
-			// e2elog.Logf("Non-retryable failure while getting service.")
+			logging.Errorf("Non-retryable failure while getting service %s in namespace %s: %v", name, namespace, err)
 			return false, err
 		default:
-			// e2elog.Logf("Get service %s in namespace %s failed: %v", name, namespace, err)
+			logging.Warnf("Get service %s in namespace %s failed: %v", name, namespace, err)
 			return false, nil
 		}
 	})
@@ -57,24 +61,25 @@ func WaitForService(c clientset.Interface, namespace, name string, exist bool, i
 }
 
 // WaitForServiceWithSelector waits until any service with given selector appears (exist == true), or disappears (exist == false)
+// A zero interval or timeout uses the configured poll interval or service timeout
 func WaitForServiceWithSelector(c clientset.Interface, namespace string, selector labels.Selector, exist bool, interval,
 	timeout time.Duration) error {
+	interval, timeout = defaultIntervalAndTimeout(interval, timeout)
 	err := wait.PollImmediate(interval, timeout, func() (bool, error) {
 		services, err := c.CoreV1().Services(namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
 		switch {
+		case err != nil && !k8sutils.IsRetryableAPIError(err):
+			logging.Errorf("Non-retryable failure while listing services with %s in namespace %s: %v", selector.String(), namespace, err)
+			return false, err
+		case err != nil:
+			logging.Warnf("List service with %s in namespace %s failed: %v", selector.String(), namespace, err)
+			return false, nil
 		case len(services.Items) != 0:
-			// e2elog.Logf("Service with %s in namespace %s found.", selector.String(), namespace)
+			logging.Debugf("Service with %s in namespace %s found.", selector.String(), namespace)
 			return exist, nil
-		case len(services.Items) == 0:
-			// e2elog.Logf("Service with %s in namespace %s disappeared.", selector.String(), namespace)
-			return !exist, nil
-		case !k8sutils.IsRetryableAPIError(err):
-			// e2elog.Logf("Non-retryable failure while listing service.")
-			return false, err
 		default:
-			// e2elog.Logf("List service with %s in namespace %s failed: %v", selector.String(), namespace, err)
-			// fmt.Logf("List service with %s in namespace %s failed: %v", selector.String(), namespace, err)
-			return false, nil
+			logging.Debugf("Service with %s in namespace %s disappeared.", selector.String(), namespace)
+			return !exist, nil
 		}
 	})
 	if err != nil {
@@ -83,3 +88,14 @@ func WaitForServiceWithSelector(c clientset.Interface, namespace string, selecto
 	}
 	return nil
 }
+
+func defaultIntervalAndTimeout(interval, timeout time.Duration) (time.Duration, time.Duration) {
+	timeouts := config.Get().Timeouts
+	if interval == 0 {
+		interval = timeouts.Poll.Duration
+	}
+	if timeout == 0 {
+		timeout = timeouts.Service.Duration
+	}
+	return interval, timeout
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package statefulset
+
+import (
+	"context"
+	"fmt"
+
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ListWatch lists and watches the stateful sets in namespace, with options changed by tweak if it is not nil
+func ListWatch(c clientset.Interface, namespace string, tweak func(*metav1.ListOptions)) cache.ListerWatcher {
+	client := c.AppsV1().StatefulSets(namespace)
+	list := func(options metav1.ListOptions) (runtime.Object, error) {
+		return client.List(options)
+	}
+	return k8sutils.ListWatch(list, client.Watch, tweak)
+}
+
+// RolloutStatus describes the rollout of s and returns whether it is complete, the same way as
+// "kubectl rollout status". With the OnDelete update strategy only the ready replicas are checked
+func RolloutStatus(s *appsv1.StatefulSet) (string, bool, error) {
+	if s.Status.ObservedGeneration == 0 || s.Generation > s.Status.ObservedGeneration {
+		return fmt.Sprintf("waiting for statefulset %q spec update to be observed", s.Name), false, nil
+	}
+	want := k8sutils.Replicas(s.Spec.Replicas)
+	if s.Status.ReadyReplicas < want {
+		return fmt.Sprintf("waiting for statefulset %q rollout to finish: %d of %d pods are ready", s.Name, s.Status.ReadyReplicas, want), false, nil
+	}
+	if s.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return fmt.Sprintf("statefulset %q has %d ready pods", s.Name, s.Status.ReadyReplicas), true, nil
+	}
+	if u := s.Spec.UpdateStrategy.RollingUpdate; u != nil && u.Partition != nil && *u.Partition > 0 {
+		if s.Status.UpdatedReplicas < want-*u.Partition {
+			return fmt.Sprintf("waiting for statefulset %q partitioned rollout to finish: %d out of %d new pods have been updated", s.Name, s.Status.UpdatedReplicas, want-*u.Partition), false, nil
+		}
+		return fmt.Sprintf("statefulset %q partitioned rollout complete: %d new pods have been updated", s.Name, s.Status.UpdatedReplicas), true, nil
+	}
+	if s.Status.UpdateRevision != s.Status.CurrentRevision {
+		return fmt.Sprintf("waiting for statefulset %q rolling update to complete: %d pods at revision %s", s.Name, s.Status.UpdatedReplicas, s.Status.UpdateRevision), false, nil
+	}
+	return fmt.Sprintf("statefulset %q rolling update complete: %d pods at revision %s", s.Name, s.Status.CurrentReplicas, s.Status.CurrentRevision), true, nil
+}
+
+// ScaledToZeroStatus describes the scale down of s and returns whether it has no pods left
+func ScaledToZeroStatus(s *appsv1.StatefulSet) (string, bool, error) {
+	want := k8sutils.Replicas(s.Spec.Replicas)
+	switch {
+	case want != 0:
+		return fmt.Sprintf("statefulset %q wants %d replicas", s.Name, want), false, nil
+	case s.Generation > s.Status.ObservedGeneration:
+		return fmt.Sprintf("waiting for statefulset %q spec update to be observed", s.Name), false, nil
+	case s.Status.Replicas > 0:
+		return fmt.Sprintf("waiting for %d pods of statefulset %q to terminate", s.Status.Replicas, s.Name), false, nil
+	}
+	return fmt.Sprintf("statefulset %q is scaled to zero", s.Name), true, nil
+}
+
+// WaitForRollout waits until the rollout of the stateful set is complete
+func WaitForRollout(ctx context.Context, c clientset.Interface, namespace, name string) (*appsv1.StatefulSet, error) {
+	return waitFor(ctx, c, namespace, name, RolloutStatus)
+}
+
+// WaitForScaledToZero waits until the stateful set is scaled to zero and all of its pods are gone
+func WaitForScaledToZero(ctx context.Context, c clientset.Interface, namespace, name string) (*appsv1.StatefulSet, error) {
+	return waitFor(ctx, c, namespace, name, ScaledToZeroStatus)
+}
+
+func waitFor(ctx context.Context, c clientset.Interface, namespace, name string, status func(*appsv1.StatefulSet) (string, bool, error)) (*appsv1.StatefulSet, error) {
+	listWatch := func(tweak func(*metav1.ListOptions)) cache.ListerWatcher {
+		return ListWatch(c, namespace, tweak)
+	}
+	obj, err := k8sutils.WaitForObject(ctx, listWatch, &appsv1.StatefulSet{}, "statefulset", namespace, name, func(obj interface{}) (string, bool, error) {
+		return status(obj.(*appsv1.StatefulSet))
+	})
+	s, _ := obj.(*appsv1.StatefulSet)
+	return s, err
+}
@@ -0,0 +1,39 @@
+package statefulset
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRolloutStatus(t *testing.T) {
+	three, two := int32(3), int32(2)
+	for _, tc := range []struct {
+		strategy appsv1.StatefulSetUpdateStrategy
+		status   appsv1.StatefulSetStatus
+		done     bool
+		msg      string
+	}{
+		{appsv1.StatefulSetUpdateStrategy{}, appsv1.StatefulSetStatus{}, false, "spec update to be observed"},
+		{appsv1.StatefulSetUpdateStrategy{}, appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 2}, false, "2 of 3 pods are ready"},
+		{appsv1.StatefulSetUpdateStrategy{}, appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "a", UpdateRevision: "b"}, false, "1 pods at revision b"},
+		{appsv1.StatefulSetUpdateStrategy{}, appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, CurrentReplicas: 3, CurrentRevision: "b", UpdateRevision: "b"}, true, "rolling update complete"},
+		{appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}, appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, CurrentRevision: "a", UpdateRevision: "b"}, true, "3 ready pods"},
+		{appsv1.StatefulSetUpdateStrategy{RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &two}}, appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "a", UpdateRevision: "b"}, true, "partitioned rollout complete"},
+	} {
+		s := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Generation: 1},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &three, UpdateStrategy: tc.strategy},
+			Status:     tc.status,
+		}
+		msg, done, err := RolloutStatus(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done != tc.done || !strings.Contains(msg, tc.msg) {
+			t.Errorf("%+v: expected %v and %q, got %v and %q", tc.status, tc.done, tc.msg, done, msg)
+		}
+	}
+}
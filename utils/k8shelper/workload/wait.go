@@ -0,0 +1,149 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	"github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/deployment"
+	"github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/replicaset"
+	"github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/replicationcontroller"
+	"github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/statefulset"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Status is the rollout status of a Deployment, StatefulSet, ReplicaSet or ReplicationController
+type Status struct {
+	Kind    string
+	Name    string
+	Message string
+	Ready   bool
+}
+
+func (s Status) String() string {
+	return fmt.Sprintf("%s/%s: %s", s.Kind, s.Name, s.Message)
+}
+
+// WaitForReady waits until at least one workload in namespace matches selector and the rollouts of all
+// of them are complete. Replica sets owned by a deployment are left to the deployment. It returns the
+// status of every matching workload, sorted by kind and name. It logs to the logger of ctx, see
+// logging.NewContext
+func WaitForReady(ctx context.Context, c clientset.Interface, namespace string, selector labels.Selector) ([]Status, error) {
+	logging.FromContext(ctx).Debugf("Waiting for the workloads with %s in namespace %s to be ready", selector, namespace)
+	tweak := func(options *metav1.ListOptions) {
+		options.LabelSelector = selector.String()
+	}
+	sources := []k8sutils.InformerSource{
+		{ListerWatcher: deployment.ListWatch(c, namespace, tweak), Object: &appsv1.Deployment{}},
+		{ListerWatcher: statefulset.ListWatch(c, namespace, tweak), Object: &appsv1.StatefulSet{}},
+		{ListerWatcher: replicaset.ListWatch(c, namespace, tweak), Object: &appsv1.ReplicaSet{}},
+		{ListerWatcher: replicationcontroller.ListWatch(c, namespace, tweak), Object: &v1.ReplicationController{}},
+	}
+	var statuses []Status
+	err := k8sutils.WaitForStores(ctx, sources, func(stores []cache.Store) (bool, error) {
+		var err error
+		if statuses, err = storeStatuses(stores, selector); err != nil || len(statuses) == 0 {
+			return false, err
+		}
+		for _, s := range statuses {
+			if !s.Ready {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		if len(statuses) == 0 {
+			return statuses, fmt.Errorf("timed out waiting for workloads with label %v in namespace %s: none were found", selector, namespace)
+		}
+		pending := []string{}
+		for _, s := range statuses {
+			if !s.Ready {
+				pending = append(pending, s.String())
+			}
+		}
+		return statuses, fmt.Errorf("timed out waiting for workloads with label %v in namespace %s: %s", selector, namespace, strings.Join(pending, "; "))
+	}
+	return statuses, err
+}
+
+// storeStatuses returns the statuses of the workloads in the stores passed by WaitForReady. The selector
+// is checked again because not every client filters watch events by label
+func storeStatuses(stores []cache.Store, selector labels.Selector) ([]Status, error) {
+	statuses := []Status{}
+	add := func(kind string, meta metav1.Object, status func() (string, bool, error)) error {
+		if !selector.Matches(labels.Set(meta.GetLabels())) {
+			return nil
+		}
+		msg, ready, err := status()
+		if err != nil {
+			return err
+		}
+		statuses = append(statuses, Status{Kind: kind, Name: meta.GetName(), Message: msg, Ready: ready})
+		return nil
+	}
+	for _, obj := range stores[0].List() {
+		d := obj.(*appsv1.Deployment)
+		if err := add("Deployment", d, func() (string, bool, error) { return deployment.RolloutStatus(d) }); err != nil {
+			return nil, err
+		}
+	}
+	for _, obj := range stores[1].List() {
+		s := obj.(*appsv1.StatefulSet)
+		if err := add("StatefulSet", s, func() (string, bool, error) { return statefulset.RolloutStatus(s) }); err != nil {
+			return nil, err
+		}
+	}
+	for _, obj := range stores[2].List() {
+		rs := obj.(*appsv1.ReplicaSet)
+		if owner := metav1.GetControllerOf(rs); owner != nil && owner.Kind == "Deployment" {
+			continue
+		}
+		if err := add("ReplicaSet", rs, func() (string, bool, error) { return replicaset.RolloutStatus(rs) }); err != nil {
+			return nil, err
+		}
+	}
+	for _, obj := range stores[3].List() {
+		rc := obj.(*v1.ReplicationController)
+		if err := add("ReplicationController", rc, func() (string, bool, error) { return replicationcontroller.RolloutStatus(rc) }); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Kind != statuses[j].Kind {
+			return statuses[i].Kind < statuses[j].Kind
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses, nil
+}
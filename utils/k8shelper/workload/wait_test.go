@@ -0,0 +1,90 @@
+package workload
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var alertLabels = map[string]string{"app": "alert"}
+
+func testRC(name string, ready int32) *v1.ReplicationController {
+	one := int32(1)
+	return &v1.ReplicationController{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: alertLabels},
+		Spec:       v1.ReplicationControllerSpec{Replicas: &one},
+		Status:     v1.ReplicationControllerStatus{Replicas: 1, ReadyReplicas: ready, AvailableReplicas: ready},
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	one := int32(1)
+	owned := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "alert-deployment-x", Namespace: "ns", Labels: alertLabels,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "alert-deployment", Controller: &[]bool{true}[0]}},
+		},
+		Spec: appsv1.ReplicaSetSpec{Replicas: &one},
+	}
+	other := testRC("other", 0)
+	other.Labels = map[string]string{"app": "blackduck"}
+	c := fake.NewSimpleClientset(testRC("alert", 1), testRC("cfssl", 0), owned, other)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		c.CoreV1().ReplicationControllers("ns").UpdateStatus(testRC("cfssl", 1))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	statuses, err := WaitForReady(ctx, c, "ns", labels.SelectorFromSet(alertLabels))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || statuses[0].Name != "alert" || statuses[1].Name != "cfssl" {
+		t.Errorf("expected the alert and cfssl replication controllers, got %v", statuses)
+	}
+}
+
+func TestWaitForReadyTimesOut(t *testing.T) {
+	c := fake.NewSimpleClientset(testRC("alert", 1), testRC("cfssl", 0))
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := WaitForReady(ctx, c, "ns", labels.SelectorFromSet(alertLabels))
+	if err == nil || !strings.Contains(err.Error(), "ReplicationController/cfssl") || strings.Contains(err.Error(), "ReplicationController/alert") {
+		t.Errorf("expected a timeout naming only cfssl, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := WaitForReady(ctx, c, "empty", labels.SelectorFromSet(alertLabels)); err == nil || !strings.Contains(err.Error(), "none were found") {
+		t.Errorf("expected a timeout without workloads, got %v", err)
+	}
+}
+
+func TestWaitForReadyReportsUnownedReplicaSets(t *testing.T) {
+	one := int32(1)
+	unowned := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "alert-rs", Namespace: "ns", Labels: alertLabels, Generation: 2},
+		Spec:       appsv1.ReplicaSetSpec{Replicas: &one},
+		Status:     appsv1.ReplicaSetStatus{ObservedGeneration: 1, Replicas: 1, ReadyReplicas: 1, AvailableReplicas: 1},
+	}
+	owned := unowned.DeepCopy()
+	owned.Name = "alert-deployment-x"
+	owned.OwnerReferences = []metav1.OwnerReference{{Kind: "Deployment", Name: "alert-deployment", Controller: &[]bool{true}[0]}}
+	c := fake.NewSimpleClientset(unowned, owned)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	statuses, err := WaitForReady(ctx, c, "ns", labels.SelectorFromSet(alertLabels))
+	if err == nil || !strings.Contains(err.Error(), `ReplicaSet/alert-rs: waiting for replicaset "alert-rs" spec update to be observed`) {
+		t.Errorf("expected a timeout naming the unowned replica set, got %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Name != "alert-rs" {
+		t.Errorf("expected only the unowned replica set, got %v", statuses)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package logging is the leveled logger of the test utilities. By default messages go to GinkgoWriter
+// with a timestamp and the name of the running spec, so they are attached to that spec and only shown
+// when it fails or the suite runs with -ginkgo.v
+package logging
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	"github.com/onsi/ginkgo"
+)
+
+// Level is the severity of a message
+type Level int
+
+// Levels in increasing severity
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var levelNames = map[Level]string{DebugLevel: "DEBUG", InfoLevel: "INFO", WarnLevel: "WARN", ErrorLevel: "ERROR"}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// ParseLevel parses debug, info, warn or error
+func ParseLevel(s string) (Level, error) {
+	for level, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return level, nil
+		}
+	}
+	return InfoLevel, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", s)
+}
+
+// Logger receives the messages of the test utilities
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// WriterLogger writes every message at or above its level as a line like
+// "15:04:05.000 INFO [synopsysctl deploy command all crds can be enabled] message"
+type WriterLogger struct {
+	lock  sync.Mutex
+	out   func() io.Writer
+	level func() Level
+	// spec returns the name of the running spec, or "" outside of specs
+	spec func() string
+	now  func() time.Time
+}
+
+// NewWriterLogger returns a logger that writes the messages at or above level to w
+func NewWriterLogger(w io.Writer, level Level) *WriterLogger {
+	return &WriterLogger{out: func() io.Writer { return w }, level: constant(level), spec: func() string { return "" }, now: time.Now}
+}
+
+// NewGinkgoLogger returns a logger that writes the messages at or above level to GinkgoWriter, with
+// the name of the running spec
+func NewGinkgoLogger(level Level) *WriterLogger {
+	return &WriterLogger{out: ginkgoWriter, level: constant(level), spec: currentSpec, now: time.Now}
+}
+
+// ginkgoWriter is looked up on every message since ginkgo replaces GinkgoWriter when the suite starts
+func ginkgoWriter() io.Writer {
+	return ginkgo.GinkgoWriter
+}
+
+func constant(level Level) func() Level {
+	return func() Level { return level }
+}
+
+var (
+	levelLock     sync.Mutex
+	resolvedLevel *Level
+)
+
+// configuredLevel is the log level of the suite configuration; an invalid level logs at InfoLevel.
+// It is resolved once flags are parsed, before that the default level is used
+func configuredLevel() Level {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	if resolvedLevel != nil {
+		return *resolvedLevel
+	}
+	level, err := ParseLevel(config.Get().LogLevel)
+	if err != nil {
+		level = InfoLevel
+	}
+	if flag.Parsed() {
+		resolvedLevel = &level
+	}
+	return level
+}
+
+// currentSpec returns the full text of the running spec. Outside of RunSpecs, ginkgo has no spec
+// runner and panics
+func currentSpec() (name string) {
+	defer func() {
+		if recover() != nil {
+			name = ""
+		}
+	}()
+	return ginkgo.CurrentGinkgoTestDescription().FullTestText
+}
+
+func (l *WriterLogger) logf(level Level, format string, args ...interface{}) {
+	if level < l.level() {
+		return
+	}
+	prefix := fmt.Sprintf("%s %s ", l.now().Format("15:04:05.000"), level)
+	if spec := l.spec(); spec != "" {
+		prefix += fmt.Sprintf("[%s] ", spec)
+	}
+	message := strings.TrimRight(fmt.Sprintf(format, args...), "\n")
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	fmt.Fprintf(l.out(), "%s%s\n", prefix, message)
+}
+
+// Debugf logs a message at DebugLevel
+func (l *WriterLogger) Debugf(format string, args ...interface{}) {
+	l.logf(DebugLevel, format, args...)
+}
+
+// Infof logs a message at InfoLevel
+func (l *WriterLogger) Infof(format string, args ...interface{}) {
+	l.logf(InfoLevel, format, args...)
+}
+
+// Warnf logs a message at WarnLevel
+func (l *WriterLogger) Warnf(format string, args ...interface{}) {
+	l.logf(WarnLevel, format, args...)
+}
+
+// Errorf logs a message at ErrorLevel
+func (l *WriterLogger) Errorf(format string, args ...interface{}) {
+	l.logf(ErrorLevel, format, args...)
+}
+
+var (
+	defaultLock   sync.RWMutex
+	defaultLogger Logger = &WriterLogger{out: ginkgoWriter, level: configuredLevel, spec: currentSpec, now: time.Now}
+)
+
+// Default returns the logger of the test utilities. Unless it is replaced with SetDefault, it writes
+// to GinkgoWriter at the configured log level
+func Default() Logger {
+	defaultLock.RLock()
+	defer defaultLock.RUnlock()
+	return defaultLogger
+}
+
+// SetDefault replaces the logger of the test utilities, e.g. with a WriterLogger of stderr in a command
+func SetDefault(l Logger) {
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+	defaultLogger = l
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries l, so the waiters that take ctx log to l instead of
+// the default logger
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok && l != nil {
+		return l
+	}
+	return Default()
+}
+
+// Debugf logs a message at DebugLevel with the default logger
+func Debugf(format string, args ...interface{}) { Default().Debugf(format, args...) }
+
+// Infof logs a message at InfoLevel with the default logger
+func Infof(format string, args ...interface{}) { Default().Infof(format, args...) }
+
+// Warnf logs a message at WarnLevel with the default logger
+func Warnf(format string, args ...interface{}) { Default().Warnf(format, args...) }
+
+// Errorf logs a message at ErrorLevel with the default logger
+func Errorf(format string, args ...interface{}) { Default().Errorf(format, args...) }
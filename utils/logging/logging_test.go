@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testLogger(buf *bytes.Buffer, level Level, spec string) *WriterLogger {
+	return &WriterLogger{
+		out:   func() io.Writer { return buf },
+		level: constant(level),
+		spec:  func() string { return spec },
+		now:   func() time.Time { return time.Date(2019, 8, 1, 13, 4, 5, 6000000, time.UTC) },
+	}
+}
+
+func TestLevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	l := testLogger(&buf, WarnLevel, "")
+	l.Debugf("debug %d", 1)
+	l.Infof("info %d", 2)
+	l.Warnf("warn %d", 3)
+	l.Errorf("error %d\n", 4)
+	expected := "13:04:05.006 WARN warn 3\n13:04:05.006 ERROR error 4\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestSpecPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	l := testLogger(&buf, DebugLevel, "synopsysctl deploy command works")
+	l.Debugf("waiting for %s", "crd")
+	expected := "13:04:05.006 DEBUG [synopsysctl deploy command works] waiting for crd\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestCurrentSpecOutsideOfSuite(t *testing.T) {
+	if spec := currentSpec(); spec != "" {
+		t.Errorf("expected no spec outside of RunSpecs, got %q", spec)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for s, expected := range map[string]Level{"debug": DebugLevel, "INFO": InfoLevel, "Warn": WarnLevel, "error": ErrorLevel} {
+		level, err := ParseLevel(s)
+		if err != nil || level != expected {
+			t.Errorf("ParseLevel(%q) = %v, %v; expected %v", s, level, err, expected)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil || !strings.Contains(err.Error(), "verbose") {
+		t.Errorf("expected an error for an unknown level, got %v", err)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	previous := Default()
+	defer SetDefault(previous)
+	var buf bytes.Buffer
+	SetDefault(NewWriterLogger(&buf, InfoLevel))
+	Debugf("hidden")
+	Infof("shown")
+	if !strings.HasSuffix(buf.String(), " INFO shown\n") || strings.Contains(buf.String(), "hidden") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	previous := Default()
+	defer SetDefault(previous)
+	var defaultBuf, buf bytes.Buffer
+	SetDefault(NewWriterLogger(&defaultBuf, InfoLevel))
+	FromContext(context.Background()).Infof("default")
+	FromContext(NewContext(context.Background(), NewWriterLogger(&buf, InfoLevel))).Infof("per call")
+	if !strings.HasSuffix(defaultBuf.String(), " INFO default\n") || !strings.HasSuffix(buf.String(), " INFO per call\n") {
+		t.Errorf("unexpected output %q and %q", defaultBuf.String(), buf.String())
+	}
+}
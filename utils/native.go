@@ -0,0 +1,42 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/native"
+)
+
+// CreateNative runs "synopsysctl create <resource> native" and decodes the objects it prints
+func (sCtl *Synopsysctl) CreateNative(ctx context.Context, options CreateNativeOptions) (*native.Manifest, *ExecResult, error) {
+	result, err := sCtl.RunCommand(ctx, options)
+	if err != nil {
+		return nil, result, err
+	}
+	m, err := native.Decode([]byte(result.Stdout))
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to decode the native output: %v", err)
+	}
+	return m, result, nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package native
+
+import (
+	"fmt"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+// applier creates and deletes the objects of a manifest through the dynamic client
+type applier struct {
+	dc     dynamic.Interface
+	mapper meta.RESTMapper
+}
+
+func newApplier(rc *rest.Config) (*applier, error) {
+	dc, err := dynamic.NewForConfig(rc)
+	if err != nil {
+		return nil, err
+	}
+	disco, err := discovery.NewDiscoveryClientForConfig(rc)
+	if err != nil {
+		return nil, err
+	}
+	groupResources, err := restmapper.GetAPIGroupResources(disco)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the API resources: %v", err)
+	}
+	return &applier{dc: dc, mapper: restmapper.NewDiscoveryRESTMapper(groupResources)}, nil
+}
+
+// resourceFor returns the client of obj, moving namespaced objects into namespace unless it is empty
+func (a *applier) resourceFor(obj runtime.Object, namespace string) (dynamic.ResourceInterface, *unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s %s: %v", gvk.Kind, u.GetName(), err)
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return a.dc.Resource(mapping.Resource), u, nil
+	}
+	if namespace != "" {
+		u.SetNamespace(namespace)
+	}
+	return a.dc.Resource(mapping.Resource).Namespace(u.GetNamespace()), u, nil
+}
+
+// Apply creates every object of m in the cluster of rc, in order. Namespaced objects are created in
+// namespace, whatever namespace they were rendered for; an empty namespace keeps the rendered one.
+// Objects that already exist are left unchanged
+func Apply(rc *rest.Config, namespace string, m *Manifest) error {
+	a, err := newApplier(rc)
+	if err != nil {
+		return err
+	}
+	for _, obj := range m.Objects {
+		client, u, err := a.resourceFor(obj, namespace)
+		if err != nil {
+			return err
+		}
+		if _, err := client.Create(u, metav1.CreateOptions{}); err != nil && !apierrs.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create %s %s: %v", u.GetKind(), u.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// Delete deletes every object of m from the cluster of rc, in reverse order. Missing objects are ignored
+func Delete(rc *rest.Config, namespace string, m *Manifest) error {
+	a, err := newApplier(rc)
+	if err != nil {
+		return err
+	}
+	for i := len(m.Objects) - 1; i >= 0; i-- {
+		client, u, err := a.resourceFor(m.Objects[i], namespace)
+		if err != nil {
+			return err
+		}
+		if err := client.Delete(u.GetName(), &metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s %s: %v", u.GetKind(), u.GetName(), err)
+		}
+	}
+	return nil
+}
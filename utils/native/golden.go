@@ -0,0 +1,238 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package native
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	"github.com/onsi/gomega/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// UpdateGolden returns true if the golden files are rewritten instead of compared against, with
+// -cnt.update-golden or CNT_UPDATE_GOLDEN=true
+func UpdateGolden() bool {
+	return config.Get().UpdateGolden
+}
+
+const (
+	generatedPlaceholder = "GENERATED"
+	redactedPlaceholder  = "REDACTED"
+	timestampPlaceholder = "TIMESTAMP"
+)
+
+var timestampRegexp = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+
+// GoldenPath returns the golden file of resource rendered by synopsysctl version under dir
+func GoldenPath(dir, version, resource string) string {
+	return filepath.Join(dir, version, resource+".yaml")
+}
+
+// Normalize renders m as YAML documents sorted by kind, namespace and name, with the fields that change
+// from run to run replaced by placeholders: server populated metadata and status are dropped, names
+// generated from generateName, secret values and timestamps are masked
+func Normalize(m *Manifest) ([]byte, error) {
+	objects := []*unstructured.Unstructured{}
+	for _, obj := range m.Objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		u := &unstructured.Unstructured{Object: content}
+		normalizeObject(u)
+		objects = append(objects, u)
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objectKey(objects[i]) < objectKey(objects[j])
+	})
+	buf := &bytes.Buffer{}
+	for _, u := range objects {
+		out, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(buf, "---\n%s", out)
+	}
+	return buf.Bytes(), nil
+}
+
+func objectKey(u *unstructured.Unstructured) string {
+	return strings.Join([]string{u.GetKind(), u.GetNamespace(), u.GetName()}, "/")
+}
+
+func normalizeObject(u *unstructured.Unstructured) {
+	for _, field := range []string{"creationTimestamp", "uid", "resourceVersion", "generation", "selfLink", "managedFields"} {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(u.Object, "status")
+	if generateName := u.GetGenerateName(); generateName != "" {
+		u.SetName(generateName + generatedPlaceholder)
+	}
+	if u.GetKind() == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			values, found, _ := unstructured.NestedMap(u.Object, field)
+			if !found {
+				continue
+			}
+			for key := range values {
+				values[key] = redactedPlaceholder
+			}
+			unstructured.SetNestedMap(u.Object, values, field)
+		}
+	}
+	u.Object = maskTimestamps(u.Object).(map[string]interface{})
+}
+
+func maskTimestamps(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = maskTimestamps(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskTimestamps(item)
+		}
+		return v
+	case string:
+		return timestampRegexp.ReplaceAllString(v, timestampPlaceholder)
+	}
+	return value
+}
+
+// ReadGolden decodes the golden file at path, e.g. to derive the objects and images a spec expects
+// from what a real synopsysctl printed
+func ReadGolden(path string) (*Manifest, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode golden file %s: %v", path, err)
+	}
+	return m, nil
+}
+
+// CompareGolden compares the normalized m with the golden file at path. With UpdateGolden the
+// golden file is written instead
+func CompareGolden(path string, m *Manifest) error {
+	return compareGolden(path, m, UpdateGolden())
+}
+
+func compareGolden(path string, m *Manifest, update bool) error {
+	actual, err := Normalize(m)
+	if err != nil {
+		return err
+	}
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(path, actual, 0644)
+	}
+	expected, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("golden file %s does not exist, run with -cnt.update-golden to create it", path)
+	} else if err != nil {
+		return err
+	}
+	if bytes.Equal(expected, actual) {
+		return nil
+	}
+	return fmt.Errorf("the manifest differs from golden file %s (run with -cnt.update-golden to accept it):\n%s", path, diff(string(expected), string(actual)))
+}
+
+// diff returns the lines that differ between expected and actual, prefixed with - and +
+func diff(expected, actual string) string {
+	a, b := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	buf := &bytes.Buffer{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(buf, "-%s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(buf, "+%s\n", b[j])
+			j++
+		}
+	}
+	return buf.String()
+}
+
+type goldenMatcher struct {
+	path string
+	err  error
+}
+
+// MatchGolden succeeds if the normalized manifest is the same as the golden file at path
+func MatchGolden(path string) types.GomegaMatcher {
+	return &goldenMatcher{path: path}
+}
+
+func (matcher *goldenMatcher) Match(actual interface{}) (bool, error) {
+	m, ok := actual.(*Manifest)
+	if !ok {
+		return false, fmt.Errorf("expected a *native.Manifest, got %T", actual)
+	}
+	matcher.err = CompareGolden(matcher.path, m)
+	return matcher.err == nil, nil
+}
+
+func (matcher *goldenMatcher) FailureMessage(actual interface{}) string {
+	return matcher.err.Error()
+}
+
+func (matcher *goldenMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected the manifest to differ from golden file %s", matcher.path)
+}
@@ -0,0 +1,81 @@
+package native
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const volatileManifest = `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: alt-one-alert-secret
+  creationTimestamp: "2019-07-04T10:00:00Z"
+  uid: 6b1f0c9e-9e4d-11e9-a2a3-2a2ae2dbcce4
+stringData:
+  ALERT_ENCRYPTION_PASSWORD: 3342036c3a2a5836b3411a911e9d7aff
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  generateName: alt-one-
+  name: alt-one-x7k2p
+  annotations:
+    synopsys.com/created: "2019-07-04T10:00:00.123+02:00"
+data:
+  ALERT_SERVER_PORT: "8443"
+`
+
+func TestCompareGolden(t *testing.T) {
+	m, err := Decode([]byte(volatileManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := GoldenPath("testdata", "2019.6.x", "alert")
+	if err := CompareGolden(path, m); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := Decode([]byte(strings.Replace(volatileManifest, `"8443"`, `"9443"`, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CompareGolden(path, changed)
+	if err == nil || !strings.Contains(err.Error(), `-  ALERT_SERVER_PORT: "8443"`) || !strings.Contains(err.Error(), `+  ALERT_SERVER_PORT: "9443"`) {
+		t.Errorf("expected a diff of the server port, got %v", err)
+	}
+}
+
+func TestUpdateGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	m, err := Decode([]byte(volatileManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := GoldenPath(dir, "2019.6.x", "alert")
+	if err := CompareGolden(path, m); err == nil {
+		t.Fatal("expected an error for a missing golden file")
+	}
+
+	if err := compareGolden(path, m, true); err != nil {
+		t.Fatal(err)
+	}
+	written, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := ioutil.ReadFile(filepath.Join("testdata", "2019.6.x", "alert.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != string(expected) {
+		t.Errorf("unexpected golden file:\n%s", written)
+	}
+}
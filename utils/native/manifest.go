@@ -0,0 +1,280 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package native decodes the manifests printed by "synopsysctl create <resource> native" so they can be
+// verified offline and, optionally, applied to a cluster
+package native
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// Manifest is the set of objects of a native manifest, in the order they were printed
+type Manifest struct {
+	Objects []runtime.Object
+}
+
+// Decode parses a stream of YAML documents or JSON objects into typed objects of the client-go scheme.
+// Kinds the scheme does not know, such as OpenShift Routes, are decoded as *unstructured.Unstructured
+// and Lists are flattened into their items
+func Decode(data []byte) (*Manifest, error) {
+	m := &Manifest{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for doc := 1; ; doc++ {
+		raw := runtime.RawExtension{}
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("document %d: %v", doc, err)
+		}
+		raw.Raw = bytes.TrimSpace(raw.Raw)
+		if len(raw.Raw) == 0 || bytes.Equal(raw.Raw, []byte("null")) {
+			continue
+		}
+		objects, err := decodeObject(raw.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %v", doc, err)
+		}
+		m.Objects = append(m.Objects, objects...)
+	}
+	return m, nil
+}
+
+func decodeObject(data []byte) ([]runtime.Object, error) {
+	obj, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(data, nil, nil)
+	if err == nil {
+		// keep apiVersion and kind so the object can be converted and applied without the scheme
+		obj.GetObjectKind().SetGroupVersionKind(*gvk)
+	} else if runtime.IsNotRegisteredError(err) {
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(data); err != nil {
+			return nil, err
+		}
+		obj = u
+	} else if err != nil {
+		return nil, err
+	}
+	if !meta.IsListType(obj) {
+		return []runtime.Object{obj}, nil
+	}
+	items, err := meta.ExtractList(obj)
+	if err != nil {
+		return nil, err
+	}
+	objects := []runtime.Object{}
+	for _, item := range items {
+		// items of a v1.List are left as raw JSON by the deserializer
+		if unknown, ok := item.(*runtime.Unknown); ok {
+			decoded, err := decodeObject(unknown.Raw)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, decoded...)
+			continue
+		}
+		objects = append(objects, item)
+	}
+	return objects, nil
+}
+
+// Kind returns the kind of obj
+func Kind(obj runtime.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	if err != nil || len(gvks) == 0 {
+		return ""
+	}
+	return gvks[0].Kind
+}
+
+// Name returns the name of obj
+func Name(obj runtime.Object) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return accessor.GetName()
+}
+
+// Find returns the object with the given kind and name, or nil
+func (m *Manifest) Find(kind, name string) runtime.Object {
+	for _, obj := range m.Objects {
+		if Kind(obj) == kind && Name(obj) == name {
+			return obj
+		}
+	}
+	return nil
+}
+
+// Names returns the sorted names of the objects of kind
+func (m *Manifest) Names(kind string) []string {
+	names := []string{}
+	for _, obj := range m.Objects {
+		if Kind(obj) == kind {
+			names = append(names, Name(obj))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Deployments returns the apps/v1 Deployments of the manifest
+func (m *Manifest) Deployments() []*appsv1.Deployment {
+	deployments := []*appsv1.Deployment{}
+	for _, obj := range m.Objects {
+		if d, ok := obj.(*appsv1.Deployment); ok {
+			deployments = append(deployments, d)
+		}
+	}
+	return deployments
+}
+
+// ReplicationControllers returns the ReplicationControllers of the manifest
+func (m *Manifest) ReplicationControllers() []*corev1.ReplicationController {
+	rcs := []*corev1.ReplicationController{}
+	for _, obj := range m.Objects {
+		if rc, ok := obj.(*corev1.ReplicationController); ok {
+			rcs = append(rcs, rc)
+		}
+	}
+	return rcs
+}
+
+// Services returns the Services of the manifest
+func (m *Manifest) Services() []*corev1.Service {
+	services := []*corev1.Service{}
+	for _, obj := range m.Objects {
+		if s, ok := obj.(*corev1.Service); ok {
+			services = append(services, s)
+		}
+	}
+	return services
+}
+
+// ConfigMaps returns the ConfigMaps of the manifest
+func (m *Manifest) ConfigMaps() []*corev1.ConfigMap {
+	configMaps := []*corev1.ConfigMap{}
+	for _, obj := range m.Objects {
+		if cm, ok := obj.(*corev1.ConfigMap); ok {
+			configMaps = append(configMaps, cm)
+		}
+	}
+	return configMaps
+}
+
+// Secrets returns the Secrets of the manifest
+func (m *Manifest) Secrets() []*corev1.Secret {
+	secrets := []*corev1.Secret{}
+	for _, obj := range m.Objects {
+		if s, ok := obj.(*corev1.Secret); ok {
+			secrets = append(secrets, s)
+		}
+	}
+	return secrets
+}
+
+// PersistentVolumeClaims returns the PersistentVolumeClaims of the manifest
+func (m *Manifest) PersistentVolumeClaims() []*corev1.PersistentVolumeClaim {
+	pvcs := []*corev1.PersistentVolumeClaim{}
+	for _, obj := range m.Objects {
+		if pvc, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+			pvcs = append(pvcs, pvc)
+		}
+	}
+	return pvcs
+}
+
+// PodSpecs returns the pod spec of every workload of the manifest, keyed by "Kind/name"
+func (m *Manifest) PodSpecs() map[string]corev1.PodSpec {
+	specs := map[string]corev1.PodSpec{}
+	for _, obj := range m.Objects {
+		key := fmt.Sprintf("%s/%s", Kind(obj), Name(obj))
+		switch o := obj.(type) {
+		case *corev1.Pod:
+			specs[key] = o.Spec
+		case *corev1.ReplicationController:
+			if o.Spec.Template != nil {
+				specs[key] = o.Spec.Template.Spec
+			}
+		case *appsv1.Deployment:
+			specs[key] = o.Spec.Template.Spec
+		case *appsv1.StatefulSet:
+			specs[key] = o.Spec.Template.Spec
+		case *appsv1.DaemonSet:
+			specs[key] = o.Spec.Template.Spec
+		case *appsv1.ReplicaSet:
+			specs[key] = o.Spec.Template.Spec
+		case *batchv1.Job:
+			specs[key] = o.Spec.Template.Spec
+		}
+	}
+	return specs
+}
+
+// Containers returns every container and init container of the manifest, keyed by container name
+func (m *Manifest) Containers() map[string][]corev1.Container {
+	containers := map[string][]corev1.Container{}
+	for _, spec := range m.PodSpecs() {
+		for _, c := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+			containers[c.Name] = append(containers[c.Name], c)
+		}
+	}
+	return containers
+}
+
+// Images returns the sorted, de-duplicated images of every container of the manifest
+func (m *Manifest) Images() []string {
+	seen := map[string]bool{}
+	images := []string{}
+	for _, cs := range m.Containers() {
+		for _, c := range cs {
+			if !seen[c.Image] {
+				seen[c.Image] = true
+				images = append(images, c.Image)
+			}
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// Summary returns one "Kind/name" line per object, for failure messages
+func (m *Manifest) Summary() string {
+	buf := &bytes.Buffer{}
+	for _, obj := range m.Objects {
+		fmt.Fprintf(buf, "%s/%s\n", Kind(obj), Name(obj))
+	}
+	return buf.String()
+}
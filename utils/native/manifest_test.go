@@ -0,0 +1,157 @@
+package native
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const yamlManifest = `
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: alt-one-alert
+spec:
+  selector:
+    matchLabels:
+      app: alert
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: docker.io/busybox:1.28
+      containers:
+      - name: alert
+        image: docker.io/blackducksoftware/blackduck-alert:4.0.0
+        resources:
+          limits:
+            memory: 2560M
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: alt-one-alert
+---
+apiVersion: route.openshift.io/v1
+kind: Route
+metadata:
+  name: alt-one-alert
+`
+
+const jsonManifest = `{
+  "kind": "List",
+  "apiVersion": "v1",
+  "items": [
+    {"kind": "ConfigMap", "apiVersion": "v1", "metadata": {"name": "alt-one-alert-config"}},
+    {"kind": "Secret", "apiVersion": "v1", "metadata": {"name": "alt-one-alert-secret"}},
+    {"kind": "PersistentVolumeClaim", "apiVersion": "v1", "metadata": {"name": "alt-one-alert-pvc"}}
+  ]
+}`
+
+func TestDecodeYAML(t *testing.T) {
+	m, err := Decode([]byte(yamlManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Objects) != 3 || len(m.Deployments()) != 1 || len(m.Services()) != 1 {
+		t.Fatalf("unexpected objects:\n%s", m.Summary())
+	}
+	if _, ok := m.Find("Route", "alt-one-alert").(*unstructured.Unstructured); !ok {
+		t.Errorf("expected the route to be decoded as unstructured:\n%s", m.Summary())
+	}
+	want := []string{"docker.io/blackducksoftware/blackduck-alert:4.0.0", "docker.io/busybox:1.28"}
+	if got := m.Images(); !reflect.DeepEqual(got, want) {
+		t.Errorf("images: got %v, want %v", got, want)
+	}
+}
+
+func TestDecodeJSONList(t *testing.T) {
+	m, err := Decode([]byte(jsonManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.ConfigMaps()) != 1 || len(m.Secrets()) != 1 || len(m.PersistentVolumeClaims()) != 1 {
+		t.Fatalf("unexpected objects:\n%s", m.Summary())
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := Decode([]byte("kind: [")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	m, err := Decode([]byte(yamlManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory := corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2560M")}
+	tests := []struct {
+		name    string
+		matcher interface {
+			Match(actual interface{}) (bool, error)
+		}
+		want bool
+	}{
+		{"deployment", HaveDeployment("alt-one-alert"), true},
+		{"missing deployment", HaveDeployment("alt-one-cfssl"), false},
+		{"service", HaveService("alt-one-alert"), true},
+		{"secret", HaveSecret("alt-one-alert-secret"), false},
+		{"image", HaveImage("docker.io/blackducksoftware/blackduck-alert:4.0.0"), true},
+		{"image without tag", HaveImage("docker.io/blackducksoftware/blackduck-alert"), true},
+		{"other image", HaveImage("docker.io/blackducksoftware/blackduck-alert:5.0.0"), false},
+		{"limits", HaveContainerLimits("alert", memory), true},
+		{"other limits", HaveContainerLimits("alert", corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1G")}), false},
+		{"limits of missing container", HaveContainerLimits("cfssl", memory), false},
+	}
+	for _, tt := range tests {
+		got, err := tt.matcher.Match(m)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if _, err := HaveService("alt-one-alert").Match("not a manifest"); err == nil {
+		t.Error("expected an error for a value that is not a manifest")
+	}
+}
+
+func TestHaveObjectsOf(t *testing.T) {
+	m, err := Decode([]byte(yamlManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := HaveObjectsOf(m).Match(m); !ok || err != nil {
+		t.Errorf("expected a manifest to have its own objects, got %v, %v", ok, err)
+	}
+	other, err := Decode([]byte(jsonManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher := HaveObjectsOf(other)
+	if ok, _ := matcher.Match(m); ok {
+		t.Error("expected the config map, secret and claim to be missing")
+	}
+	if message := matcher.FailureMessage(m); !strings.Contains(message, "Secret/alt-one-alert-secret") {
+		t.Errorf("unexpected failure message %s", message)
+	}
+
+	// the names generated from generateName are not checked
+	golden, err := ReadGolden(filepath.Join("testdata", "2019.6.x", "alert.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := HaveObjectsOf(golden).Match(golden); !ok {
+		t.Errorf("expected a golden manifest to have its own objects:\n%s", HaveObjectsOf(golden).FailureMessage(golden))
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package native
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// manifestMatcher is a Gomega matcher of a *Manifest
+type manifestMatcher struct {
+	description string
+	// match returns whether m matches and, if not, what was found instead
+	match  func(m *Manifest) (bool, string)
+	actual string
+}
+
+func (matcher *manifestMatcher) Match(actual interface{}) (bool, error) {
+	m, ok := actual.(*Manifest)
+	if !ok {
+		return false, fmt.Errorf("expected a *native.Manifest, got %T", actual)
+	}
+	success, found := matcher.match(m)
+	matcher.actual = found
+	return success, nil
+}
+
+func (matcher *manifestMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected the manifest to %s, but it has\n%s", matcher.description, matcher.actual)
+}
+
+func (matcher *manifestMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected the manifest not to %s, but it has\n%s", matcher.description, matcher.actual)
+}
+
+// HaveObject succeeds if the manifest has an object of kind with name
+func HaveObject(kind, name string) types.GomegaMatcher {
+	return &manifestMatcher{
+		description: fmt.Sprintf("have %s %s", kind, name),
+		match: func(m *Manifest) (bool, string) {
+			return m.Find(kind, name) != nil, fmt.Sprintf("%s: %s", kind, strings.Join(m.Names(kind), ", "))
+		},
+	}
+}
+
+// HaveDeployment succeeds if the manifest has the Deployment name
+func HaveDeployment(name string) types.GomegaMatcher {
+	return HaveObject("Deployment", name)
+}
+
+// HaveReplicationController succeeds if the manifest has the ReplicationController name
+func HaveReplicationController(name string) types.GomegaMatcher {
+	return HaveObject("ReplicationController", name)
+}
+
+// HaveService succeeds if the manifest has the Service name
+func HaveService(name string) types.GomegaMatcher {
+	return HaveObject("Service", name)
+}
+
+// HaveConfigMap succeeds if the manifest has the ConfigMap name
+func HaveConfigMap(name string) types.GomegaMatcher {
+	return HaveObject("ConfigMap", name)
+}
+
+// HaveSecret succeeds if the manifest has the Secret name
+func HaveSecret(name string) types.GomegaMatcher {
+	return HaveObject("Secret", name)
+}
+
+// HavePersistentVolumeClaim succeeds if the manifest has the PersistentVolumeClaim name
+func HavePersistentVolumeClaim(name string) types.GomegaMatcher {
+	return HaveObject("PersistentVolumeClaim", name)
+}
+
+// HaveImage succeeds if a container of the manifest runs image. An image without a tag matches every tag
+func HaveImage(image string) types.GomegaMatcher {
+	return &manifestMatcher{
+		description: fmt.Sprintf("have image %s", image),
+		match: func(m *Manifest) (bool, string) {
+			images := m.Images()
+			for _, i := range images {
+				if i == image || strings.HasPrefix(i, image+":") || strings.HasPrefix(i, image+"@") {
+					return true, ""
+				}
+			}
+			return false, fmt.Sprintf("images: %s", strings.Join(images, ", "))
+		},
+	}
+}
+
+// HaveContainerLimits succeeds if every container named container has the given resource limits.
+// Resources missing from limits are not checked
+func HaveContainerLimits(container string, limits corev1.ResourceList) types.GomegaMatcher {
+	return &manifestMatcher{
+		description: fmt.Sprintf("have container %s with limits %s", container, formatResources(limits)),
+		match: func(m *Manifest) (bool, string) {
+			containers := m.Containers()[container]
+			if len(containers) == 0 {
+				return false, fmt.Sprintf("no container %s", container)
+			}
+			for _, c := range containers {
+				for name, quantity := range limits {
+					actual, ok := c.Resources.Limits[name]
+					if !ok || actual.Cmp(quantity) != 0 {
+						return false, fmt.Sprintf("container %s with limits %s", container, formatResources(c.Resources.Limits))
+					}
+				}
+			}
+			return true, ""
+		},
+	}
+}
+
+// HaveObjectsOf succeeds if the manifest has every object, image and container limit of expected,
+// such as a golden file read with ReadGolden. Objects named from generateName are not checked
+func HaveObjectsOf(expected *Manifest) types.GomegaMatcher {
+	return &manifestMatcher{
+		description: "have the objects, images and container limits of the expected manifest",
+		match: func(m *Manifest) (bool, string) {
+			missing := []string{}
+			for _, obj := range expected.Objects {
+				kind, name := Kind(obj), Name(obj)
+				if strings.HasSuffix(name, generatedPlaceholder) {
+					continue
+				}
+				if m.Find(kind, name) == nil {
+					missing = append(missing, fmt.Sprintf("%s/%s", kind, name))
+				}
+			}
+			for _, image := range expected.Images() {
+				if ok, _ := HaveImage(image).Match(m); !ok {
+					missing = append(missing, "image "+image)
+				}
+			}
+			for name, containers := range expected.Containers() {
+				for _, c := range containers {
+					if ok, _ := HaveContainerLimits(name, c.Resources.Limits).Match(m); !ok {
+						missing = append(missing, fmt.Sprintf("container %s with limits %s", name, formatResources(c.Resources.Limits)))
+					}
+				}
+			}
+			if len(missing) == 0 {
+				return true, ""
+			}
+			sort.Strings(missing)
+			return false, fmt.Sprintf("%sbut not\n%s", m.Summary(), strings.Join(missing, "\n"))
+		},
+	}
+}
+
+func formatResources(resources corev1.ResourceList) string {
+	parts := []string{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if q, ok := resources[name]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%s", name, q.String()))
+		}
+	}
+	for name, q := range resources {
+		if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+			parts = append(parts, fmt.Sprintf("%s=%s", name, q.String()))
+		}
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
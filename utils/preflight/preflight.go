@@ -0,0 +1,320 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	utils "github.com/blackducksoftware/cloud-native-tests/utils"
+	crdutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper/crd"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Status is the outcome of a check
+type Status string
+
+// Check outcomes. Only StatusFail fails the report
+const (
+	StatusPass Status = "PASS"
+	StatusWarn Status = "WARN"
+	StatusFail Status = "FAIL"
+)
+
+// operatorAdmin is the name of the ClusterRole and ClusterRoleBinding of a cluster scoped operator
+const operatorAdmin = "synopsys-operator-admin"
+
+// Result is the outcome of one check
+type Result struct {
+	Check   string
+	Status  Status
+	Message string
+}
+
+// Report is the outcome of every check, in the order they ran
+type Report struct {
+	Results []Result
+}
+
+func (r *Report) add(check string, status Status, format string, args ...interface{}) {
+	r.Results = append(r.Results, Result{Check: check, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// Failed returns true if any check failed
+func (r *Report) Failed() bool {
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the results as a table
+func (r *Report) String() string {
+	b := &bytes.Buffer{}
+	w := tabwriter.NewWriter(b, 0, 4, 2, ' ', 0)
+	for _, result := range r.Results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Status, result.Check, result.Message)
+	}
+	w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Options are the requirements of the suites
+type Options struct {
+	// MinServerVersion is the oldest supported Kubernetes version, e.g. 1.11
+	MinServerVersion string
+	// MinCPU and MinMemory are the allocatable resources the schedulable nodes need in total
+	MinCPU    resource.Quantity
+	MinMemory resource.Quantity
+	// OperatorImage is compared with the synopsysctl version
+	OperatorImage string
+}
+
+// DefaultOptions are enough to run a Black Duck next to an Alert and an OpsSight
+func DefaultOptions(operatorImage string) Options {
+	return Options{
+		MinServerVersion: "1.11",
+		MinCPU:           resource.MustParse("4"),
+		MinMemory:        resource.MustParse("16Gi"),
+		OperatorImage:    operatorImage,
+	}
+}
+
+// Checker checks that a cluster can run the suites
+type Checker struct {
+	KubeClient         kubernetes.Interface
+	APIExtensionClient apiextensionsclient.Interface
+	// Synopsysctl is not checked if it is nil
+	Synopsysctl *utils.Synopsysctl
+	Options     Options
+}
+
+// requiredAccess is what synopsysctl and the framework do outside of the namespaces they create
+var requiredAccess = []authorizationv1.ResourceAttributes{
+	{Verb: "create", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+	{Verb: "delete", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+	{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+	{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+	{Verb: "create", Resource: "namespaces"},
+	{Verb: "delete", Resource: "namespaces"},
+	{Verb: "create", Group: "apps", Resource: "deployments"},
+	{Verb: "create", Resource: "persistentvolumeclaims"},
+}
+
+// Run runs every check. The cluster checks are skipped if the API server cannot be reached
+func (c *Checker) Run(ctx context.Context) *Report {
+	r := &Report{}
+	if c.checkServerVersion(r) {
+		c.checkPermissions(r)
+		c.checkStorageClass(r)
+		c.checkNodeCapacity(r)
+		c.checkConflicts(r)
+	}
+	if c.Synopsysctl != nil {
+		c.checkSynopsysctl(ctx, r)
+	}
+	return r
+}
+
+// checkServerVersion returns false if the API server cannot be reached
+func (c *Checker) checkServerVersion(r *Report) bool {
+	info, err := c.KubeClient.Discovery().ServerVersion()
+	if err != nil {
+		r.add("api server", StatusFail, "cannot reach the API server: %v", err)
+		return false
+	}
+	r.add("api server", StatusPass, "reachable")
+	v, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		r.add("server version", StatusWarn, "cannot parse %q: %v", info.GitVersion, err)
+		return true
+	}
+	min, err := version.ParseGeneric(c.Options.MinServerVersion)
+	if err != nil {
+		r.add("server version", StatusFail, "invalid minimum version %q: %v", c.Options.MinServerVersion, err)
+		return true
+	}
+	if !v.AtLeast(min) {
+		r.add("server version", StatusFail, "%s is older than %s", info.GitVersion, c.Options.MinServerVersion)
+		return true
+	}
+	r.add("server version", StatusPass, "%s", info.GitVersion)
+	return true
+}
+
+func (c *Checker) checkPermissions(r *Report) {
+	denied := []string{}
+	for _, attributes := range requiredAccess {
+		attributes := attributes
+		review, err := c.KubeClient.AuthorizationV1().SelfSubjectAccessReviews().Create(&authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+		})
+		if err != nil {
+			r.add("permissions", StatusFail, "cannot review access: %v", err)
+			return
+		}
+		if !review.Status.Allowed {
+			denied = append(denied, fmt.Sprintf("%s %s", attributes.Verb, groupResource(attributes)))
+		}
+	}
+	if len(denied) > 0 {
+		r.add("permissions", StatusFail, "not allowed to %s", strings.Join(denied, ", "))
+		return
+	}
+	r.add("permissions", StatusPass, "allowed to create CRDs, cluster RBAC, namespaces and their workloads")
+}
+
+func groupResource(attributes authorizationv1.ResourceAttributes) string {
+	if attributes.Group == "" {
+		return attributes.Resource
+	}
+	return attributes.Resource + "." + attributes.Group
+}
+
+// defaultStorageClassAnnotations mark the default StorageClass; the beta annotation is still used by
+// older clusters
+var defaultStorageClassAnnotations = []string{"storageclass.kubernetes.io/is-default-class", "storageclass.beta.kubernetes.io/is-default-class"}
+
+func (c *Checker) checkStorageClass(r *Report) {
+	classes, err := c.KubeClient.StorageV1().StorageClasses().List(metav1.ListOptions{})
+	if err != nil {
+		r.add("default storage class", StatusFail, "cannot list storage classes: %v", err)
+		return
+	}
+	defaults := []string{}
+	for _, class := range classes.Items {
+		for _, annotation := range defaultStorageClassAnnotations {
+			if class.Annotations[annotation] == "true" {
+				defaults = append(defaults, class.Name)
+				break
+			}
+		}
+	}
+	switch len(defaults) {
+	case 0:
+		r.add("default storage class", StatusFail, "there is no default storage class, persistent volume claims will stay pending")
+	case 1:
+		r.add("default storage class", StatusPass, "%s", defaults[0])
+	default:
+		r.add("default storage class", StatusWarn, "%s are all marked as default", strings.Join(defaults, ", "))
+	}
+}
+
+func (c *Checker) checkNodeCapacity(r *Report) {
+	nodes, err := c.KubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		r.add("node capacity", StatusFail, "cannot list nodes: %v", err)
+		return
+	}
+	cpu, memory := resource.Quantity{}, resource.Quantity{}
+	schedulable := 0
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable || !nodeReady(&node) {
+			continue
+		}
+		schedulable++
+		cpu.Add(node.Status.Allocatable[corev1.ResourceCPU])
+		memory.Add(node.Status.Allocatable[corev1.ResourceMemory])
+	}
+	message := fmt.Sprintf("%d schedulable nodes with %s CPU and %s memory", schedulable, cpu.String(), memory.String())
+	if cpu.Cmp(c.Options.MinCPU) < 0 || memory.Cmp(c.Options.MinMemory) < 0 {
+		r.add("node capacity", StatusFail, "%s, at least %s CPU and %s memory are needed", message, c.Options.MinCPU.String(), c.Options.MinMemory.String())
+		return
+	}
+	r.add("node capacity", StatusPass, "%s", message)
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// checkConflicts fails if a Synopsys Operator, its cluster RBAC or its CRDs are already installed
+func (c *Checker) checkConflicts(r *Report) {
+	found := []string{}
+	for _, name := range []string{crdutils.AlertCRDName, crdutils.BlackDuckCRDName, crdutils.OpsSightCRDName} {
+		_, err := c.APIExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(name, metav1.GetOptions{})
+		if err == nil {
+			found = append(found, fmt.Sprintf("crd %s", name))
+		} else if !apierrs.IsNotFound(err) {
+			r.add("existing operator", StatusFail, "cannot get crd %s: %v", name, err)
+			return
+		}
+	}
+	if _, err := c.KubeClient.RbacV1().ClusterRoles().Get(operatorAdmin, metav1.GetOptions{}); err == nil {
+		found = append(found, fmt.Sprintf("clusterrole %s", operatorAdmin))
+	}
+	if _, err := c.KubeClient.RbacV1().ClusterRoleBindings().Get(operatorAdmin, metav1.GetOptions{}); err == nil {
+		found = append(found, fmt.Sprintf("clusterrolebinding %s", operatorAdmin))
+	}
+	deployments, err := c.KubeClient.AppsV1().Deployments("").List(metav1.ListOptions{LabelSelector: "app=synopsys-operator"})
+	if err != nil {
+		r.add("existing operator", StatusFail, "cannot list deployments: %v", err)
+		return
+	}
+	for _, d := range deployments.Items {
+		found = append(found, fmt.Sprintf("deployment %s/%s", d.Namespace, d.Name))
+	}
+	if len(found) > 0 {
+		r.add("existing operator", StatusFail, "found %s; remove them, e.g. with cnt-gc", strings.Join(found, ", "))
+		return
+	}
+	r.add("existing operator", StatusPass, "none installed")
+}
+
+func (c *Checker) checkSynopsysctl(ctx context.Context, r *Report) {
+	v, err := c.Synopsysctl.Version(ctx)
+	if err != nil {
+		r.add("synopsysctl", StatusFail, "%v", err)
+		return
+	}
+	if c.Options.OperatorImage == "" {
+		r.add("synopsysctl", StatusPass, "version %s", v)
+		return
+	}
+	operatorVersion, err := utils.OperatorImageVersion(c.Options.OperatorImage)
+	if err != nil {
+		r.add("synopsysctl", StatusWarn, "version %s, %v", v, err)
+		return
+	}
+	if v.Compare(operatorVersion) != 0 {
+		r.add("synopsysctl", StatusWarn, "version %s does not match the operator release %s", v, operatorVersion)
+		return
+	}
+	r.add("synopsysctl", StatusPass, "version %s", v)
+}
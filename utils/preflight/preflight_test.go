@@ -0,0 +1,109 @@
+package preflight
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	utils "github.com/blackducksoftware/cloud-native-tests/utils"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func node(name, cpu, memory string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)},
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func defaultStorageClass(name string) *storagev1.StorageClass {
+	return &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{
+		Name:        name,
+		Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+	}}
+}
+
+// testChecker returns a Checker of a cluster running gitVersion that allows everything but denied
+func testChecker(gitVersion string, denied string, objects ...runtime.Object) *Checker {
+	kc := fake.NewSimpleClientset(objects...)
+	kc.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: gitVersion}
+	kc.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Resource != denied
+		return true, review, nil
+	})
+	return &Checker{KubeClient: kc, APIExtensionClient: apiextensionsfake.NewSimpleClientset(), Options: DefaultOptions("")}
+}
+
+func results(r *Report) map[string]Result {
+	m := map[string]Result{}
+	for _, result := range r.Results {
+		m[result.Check] = result
+	}
+	return m
+}
+
+func TestRunPasses(t *testing.T) {
+	c := testChecker("v1.14.3-gke.11", "", node("a", "2", "8Gi"), node("b", "4", "16Gi"), defaultStorageClass("standard"))
+	r := c.Run(context.Background())
+	if r.Failed() {
+		t.Errorf("expected the checks to pass\n%s", r)
+	}
+	if got := results(r)["node capacity"].Message; got != "2 schedulable nodes with 6 CPU and 24Gi memory" {
+		t.Errorf("unexpected node capacity %q", got)
+	}
+}
+
+func TestRunFails(t *testing.T) {
+	unschedulable := node("b", "16", "64Gi")
+	unschedulable.Spec.Unschedulable = true
+	c := testChecker("v1.10.0", "customresourcedefinitions", node("a", "2", "8Gi"), unschedulable,
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "slow"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "synopsys-operator"}})
+	c.APIExtensionClient = apiextensionsfake.NewSimpleClientset(&apiextensionsv1beta1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "alerts.synopsys.com"}})
+	c.Synopsysctl = utils.NewSynopsysctl("/nonexistent/synopsysctl")
+	r := c.Run(context.Background())
+	if !r.Failed() {
+		t.Fatalf("expected the checks to fail\n%s", r)
+	}
+	for check, message := range map[string]string{
+		"server version":        "v1.10.0 is older than 1.11",
+		"permissions":           "not allowed to create customresourcedefinitions.apiextensions.k8s.io, delete customresourcedefinitions.apiextensions.k8s.io",
+		"default storage class": "there is no default storage class",
+		"node capacity":         "1 schedulable nodes with 2 CPU and 8Gi memory",
+		"existing operator":     "crd alerts.synopsys.com",
+		"synopsysctl":           "",
+	} {
+		result := results(r)[check]
+		if result.Status != StatusFail || !strings.Contains(result.Message, message) {
+			t.Errorf("expected %s to fail with %q, got %+v", check, message, result)
+		}
+	}
+}
+
+func TestReportString(t *testing.T) {
+	r := &Report{}
+	r.add("api server", StatusPass, "reachable")
+	r.add("default storage class", StatusWarn, "a, b are all marked as default")
+	want := "PASS  api server             reachable\nWARN  default storage class  a, b are all marked as default"
+	if r.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, r)
+	}
+	if r.Failed() {
+		t.Error("expected warnings not to fail the report")
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+
+	utils "github.com/blackducksoftware/cloud-native-tests/utils"
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	k8sutils "github.com/blackducksoftware/cloud-native-tests/utils/k8shelper"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
+	"github.com/onsi/ginkgo"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/rest"
+)
+
+// NewChecker returns a Checker of the cluster of rc and the configured synopsysctl, which runs against
+// the configured cluster like the one of the framework. Call the returned cleanup when the checks are done
+func NewChecker(rc *rest.Config, options Options) (*Checker, func() error, error) {
+	kc, err := k8sutils.GetKubeClient(rc)
+	if err != nil {
+		return nil, nil, err
+	}
+	aec, err := apiextensionsclient.NewForConfig(rc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating the api extension client: %v", err)
+	}
+	sCtl, cleanup, err := utils.NewSynopsysctl("").ForCluster(config.Get().Cluster)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to point synopsysctl at the cluster: %v", err)
+	}
+	return &Checker{KubeClient: kc, APIExtensionClient: aec, Synopsysctl: sCtl, Options: options}, cleanup, nil
+}
+
+// Run checks the cluster of GetRestConfig with the default options and the suite configuration
+func Run(ctx context.Context) *Report {
+	rc, err := k8sutils.GetRestConfig()
+	if err != nil {
+		r := &Report{}
+		r.add("api server", StatusFail, "failed to get the kube config: %v", err)
+		return r
+	}
+	c, cleanup, err := NewChecker(rc, DefaultOptions(config.Get().OperatorImage))
+	if err != nil {
+		r := &Report{}
+		r.add("api server", StatusFail, "%v", err)
+		return r
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			logging.Warnf("%v", err)
+		}
+	}()
+	return c.Run(ctx)
+}
+
+// BeforeSuite runs the checks and fails the suite with the report if any failed, so no spec runs
+// against a cluster that cannot pass it. Register it in a suite with
+//
+//	var _ = BeforeSuite(preflight.BeforeSuite)
+//
+// It also fails the suite if the configuration cannot be loaded, and runs no checks if they are
+// disabled with -cnt.preflight=false
+func BeforeSuite() {
+	if err := config.Init(); err != nil {
+		ginkgo.Fail(err.Error())
+	}
+	if !config.Get().Preflight {
+		logging.Infof("preflight checks are disabled")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Command.Duration)
+	defer cancel()
+	report := Run(ctx)
+	logging.Infof("preflight checks:\n%s", report)
+	if report.Failed() {
+		ginkgo.Fail(fmt.Sprintf("preflight checks failed:\n%s", report))
+	}
+}
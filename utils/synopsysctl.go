@@ -22,32 +22,337 @@ under the License.
 package utils
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
 )
 
-// Synopsysctl TODO
+// Synopsysctl runs a synopsysctl binary for the specs and returns the result of every command. It
+// logs the commands, can record them into a transcript or replay one instead of running the binary,
+// and the With* methods return copies with another environment, kubeconfig, directory or logger
 type Synopsysctl struct {
 	path string
+	// env is added to the environment of the test process; later entries win
+	env     []string
+	workDir string
+	stdin   *string
+
+	recorder *transcriptRecorder
+	replayer *transcriptReplayer
+	// logger is nil for the default logger of the logging package
+	logger logging.Logger
+
+	versionLock sync.Mutex
+	version     *ReleaseVersion
 }
 
-// NewSynopsysctl returns an empty Synopsysctl
+// NewSynopsysctl returns an empty Synopsysctl. An empty path runs the synopsysctl of the suite configuration
 func NewSynopsysctl(path string) *Synopsysctl {
 	return &Synopsysctl{
 		path: path,
 	}
 }
 
-// Exec takes everything after "synopsysctl" and
+// ExecErrorReason describes why a synopsysctl command did not complete successfully
+type ExecErrorReason string
+
+const (
+	// ExecTimeout means the command was killed because its deadline expired
+	ExecTimeout ExecErrorReason = "Timeout"
+	// ExecCanceled means the command was killed because its context was canceled
+	ExecCanceled ExecErrorReason = "Canceled"
+	// ExecNonZeroExit means the command ran to completion but exited with a non-zero code
+	ExecNonZeroExit ExecErrorReason = "NonZeroExit"
+	// ExecSpawnFailure means the command could not be started
+	ExecSpawnFailure ExecErrorReason = "SpawnFailure"
+)
+
+// ExecError is returned when a synopsysctl command fails
+type ExecError struct {
+	Args     []string
+	Reason   ExecErrorReason
+	ExitCode int
+	Err      error
+}
+
+func (e *ExecError) Error() string {
+	cmd := strings.Join(e.Args, " ")
+	switch e.Reason {
+	case ExecNonZeroExit:
+		return fmt.Sprintf("synopsysctl %s: exited with code %d", cmd, e.ExitCode)
+	case ExecTimeout:
+		return fmt.Sprintf("synopsysctl %s: timed out: %v", cmd, e.Err)
+	case ExecCanceled:
+		return fmt.Sprintf("synopsysctl %s: canceled: %v", cmd, e.Err)
+	default:
+		return fmt.Sprintf("synopsysctl %s: failed to start: %v", cmd, e.Err)
+	}
+}
+
+// IsExecTimeout returns true if err is an ExecError caused by an expired deadline
+func IsExecTimeout(err error) bool {
+	e, ok := err.(*ExecError)
+	return ok && e.Reason == ExecTimeout
+}
+
+// IsExecNonZeroExit returns true if err is an ExecError caused by a non-zero exit code
+func IsExecNonZeroExit(err error) bool {
+	e, ok := err.(*ExecError)
+	return ok && e.Reason == ExecNonZeroExit
+}
+
+// IsExecSpawnFailure returns true if err is an ExecError caused by the binary failing to start
+func IsExecSpawnFailure(err error) bool {
+	e, ok := err.(*ExecError)
+	return ok && e.Reason == ExecSpawnFailure
+}
+
+// ExecResult is the outcome of a single synopsysctl command
+type ExecResult struct {
+	// Args is the full argv, starting with the synopsysctl binary
+	Args     []string
+	Stdout   string
+	Stderr   string
+	Combined string
+	ExitCode int
+	Duration time.Duration
+}
+
+// String formats the result for failure messages, keeping stdout and stderr apart
+func (r *ExecResult) String() string {
+	return fmt.Sprintf("Command: %s\nExit Code: %d\nDuration: %v\nStdout: %s\nStderr: %s",
+		strings.Join(r.Args, " "), r.ExitCode, r.Duration, r.Stdout, r.Stderr)
+}
+
+// Exec takes everything after "synopsysctl" and runs it, returning the combined stdout and stderr
 func (sCtl *Synopsysctl) Exec(args ...string) (string, error) {
-	cmd := exec.Command(sCtl.path, args...)
-	// logger.Debugf("cmd.Args: %s \n\n", cmd.Args)
-	stdoutErr, err := cmd.CombinedOutput()
-	// logger.Debugf("stdoutErr: %s, err: %+v \n\n", stdoutErr, err)
+	return sCtl.ExecContext(context.Background(), args...)
+}
+
+// ExecWithTimeout is Exec bounded by timeout; the command is killed if it runs longer
+func (sCtl *Synopsysctl) ExecWithTimeout(timeout time.Duration, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return sCtl.ExecContext(ctx, args...)
+}
+
+// ExecContext is Exec bounded by ctx. If ctx is done before the command exits, the command
+// and every process in its process group are killed and an *ExecError is returned, at the latest
+// after a short grace period for the output
+func (sCtl *Synopsysctl) ExecContext(ctx context.Context, args ...string) (string, error) {
+	result, err := sCtl.Run(ctx, args...)
+	return result.Combined, err
+}
+
+// Run is ExecContext but keeps stdout, stderr and the exit code separate. The returned
+// ExecResult is never nil, even when err is not
+func (sCtl *Synopsysctl) Run(ctx context.Context, args ...string) (*ExecResult, error) {
+	if sCtl.replayer != nil {
+		return sCtl.replayer.nextResult(args)
+	}
+	start := time.Now()
+	result, err := sCtl.execute(ctx, args)
+	sCtl.logResult(result, err)
+	if sCtl.recorder != nil {
+		recordErr := sCtl.recorder.record(newTranscriptEntry(start, sCtl.environ(), sCtl.dir(), result, err))
+		if recordErr != nil && err == nil {
+			return result, fmt.Errorf("synopsysctl %s succeeded but could not be recorded: %v", strings.Join(args, " "), recordErr)
+		}
+	}
+	return result, err
+}
+
+// binary returns the path of the synopsysctl binary
+func (sCtl *Synopsysctl) binary() string {
+	if sCtl.path == "" {
+		return config.Get().SynopsysctlPath
+	}
+	return sCtl.path
+}
+
+// log returns the logger of sCtl
+func (sCtl *Synopsysctl) log() logging.Logger {
+	if sCtl.logger == nil {
+		return logging.Default()
+	}
+	return sCtl.logger
+}
+
+// logResult logs the command at info level and its output at debug level, with the values of
+// sensitive flags redacted like in transcripts
+func (sCtl *Synopsysctl) logResult(result *ExecResult, err error) {
+	args, secrets := redactArgs(result.Args)
+	cmd := strings.Join(args, " ")
+	if err != nil {
+		sCtl.log().Infof("%s: exit code %d after %v: %s", cmd, result.ExitCode, result.Duration, maskSecrets(err.Error(), secrets))
+	} else {
+		sCtl.log().Infof("%s: exit code %d after %v", cmd, result.ExitCode, result.Duration)
+	}
+	if result.Combined != "" {
+		sCtl.log().Debugf("%s output:\n%s", cmd, strings.TrimRight(maskSecrets(result.Combined, secrets), "\n"))
+	}
+}
+
+// environ returns the environment synopsysctl runs with
+func (sCtl *Synopsysctl) environ() []string {
+	return mergeEnv(os.Environ(), sCtl.env)
+}
+
+// dir returns the working directory synopsysctl runs in
+func (sCtl *Synopsysctl) dir() string {
+	if sCtl.workDir != "" {
+		return sCtl.workDir
+	}
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// outputGrace is how long execute waits for the output of synopsysctl once it exited or was killed. A
+// child that inherited stdout or stderr, e.g. on windows where only synopsysctl itself is killed, would
+// otherwise keep the command from returning
+const outputGrace = 5 * time.Second
+
+// execute runs the synopsysctl binary
+func (sCtl *Synopsysctl) execute(ctx context.Context, args []string) (*ExecResult, error) {
+	cmd := exec.Command(sCtl.binary(), args...)
+	cmd.Env = sCtl.environ()
+	cmd.Dir = sCtl.workDir
+	if sCtl.stdin != nil {
+		cmd.Stdin = strings.NewReader(*sCtl.stdin)
+	}
+	setProcessGroup(cmd)
+	var stdout, stderr, combined lockedBuffer
+
+	result := &ExecResult{Args: cmd.Args, ExitCode: -1}
+	start := time.Now()
+	finish := func() {
+		result.Duration = time.Since(start)
+		result.Stdout = stdout.String()
+		result.Stderr = stderr.String()
+		result.Combined = combined.String()
+	}
+
+	// the pipes are copied here rather than by cmd.Wait, which would wait for every process that holds them
+	output, err := startWithOutput(cmd, io.MultiWriter(&stdout, &combined), io.MultiWriter(&stderr, &combined))
+	if err != nil {
+		finish()
+		return result, &ExecError{Args: args, Reason: ExecSpawnFailure, ExitCode: -1, Err: err}
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		killProcessGroup(cmd)
+		<-done
+		output.wait(outputGrace)
+		finish()
+		reason := ExecCanceled
+		if ctx.Err() == context.DeadlineExceeded {
+			reason = ExecTimeout
+		}
+		return result, &ExecError{Args: args, Reason: reason, ExitCode: -1, Err: ctx.Err()}
+	}
+	output.wait(outputGrace)
+	finish()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = exitErr.ExitCode()
+			return result, &ExecError{Args: args, Reason: ExecNonZeroExit, ExitCode: result.ExitCode, Err: err}
+		}
+		return result, &ExecError{Args: args, Reason: ExecSpawnFailure, ExitCode: -1, Err: err}
+	}
+	result.ExitCode = 0
+	return result, nil
+}
+
+// commandOutput copies the stdout and stderr pipes of a started command
+type commandOutput struct {
+	readers []*os.File
+	copied  chan struct{}
+}
+
+// startWithOutput starts cmd with its stdout and stderr copied to stdout and stderr
+func startWithOutput(cmd *exec.Cmd, stdout, stderr io.Writer) (*commandOutput, error) {
+	output := &commandOutput{copied: make(chan struct{})}
+	var writers []*os.File
+	closeAll := func(files []*os.File) {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	for range []io.Writer{stdout, stderr} {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closeAll(output.readers)
+			closeAll(writers)
+			return nil, err
+		}
+		output.readers = append(output.readers, r)
+		writers = append(writers, w)
+	}
+	cmd.Stdout, cmd.Stderr = writers[0], writers[1]
+	err := cmd.Start()
+	// the command has its own copies of the write ends
+	closeAll(writers)
 	if err != nil {
-		return string(stdoutErr), err
+		closeAll(output.readers)
+		return nil, err
+	}
+	var wg sync.WaitGroup
+	for i, w := range []io.Writer{stdout, stderr} {
+		wg.Add(1)
+		go func(r *os.File, w io.Writer) {
+			defer wg.Done()
+			io.Copy(w, r)
+		}(output.readers[i], w)
 	}
-	return string(stdoutErr), nil
+	go func() {
+		wg.Wait()
+		close(output.copied)
+	}()
+	return output, nil
 }
 
+// wait waits up to grace for every process holding the pipes to close them, then closes the pipes
+func (o *commandOutput) wait(grace time.Duration) {
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-o.copied:
+	case <-timer.C:
+	}
+	for _, r := range o.readers {
+		r.Close()
+	}
+}
 
+// lockedBuffer is a bytes.Buffer that is safe to write from the output copies and read at any time
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
 
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,519 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blackducksoftware/cloud-native-tests/utils/config"
+	"github.com/blackducksoftware/cloud-native-tests/utils/logging"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// fakeSynopsysctl is the path of the fake-synopsysctl binary built by TestMain
+var fakeSynopsysctl string
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "fake-synopsysctl")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create temp dir: %v\n", err)
+		os.Exit(1)
+	}
+	fakeSynopsysctl = filepath.Join(dir, "synopsysctl")
+	out, err := exec.Command("go", "build", "-o", fakeSynopsysctl, "github.com/blackducksoftware/cloud-native-tests/cmd/fake-synopsysctl").CombinedOutput()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build fake-synopsysctl: %v\n%s", err, out)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestRunSeparatesOutput(t *testing.T) {
+	sCtl := NewSynopsysctl(fakeSynopsysctl)
+	result, err := sCtl.RunCommandWithTimeout(time.Minute, CreateBlackDuckOptions{Name: "bd-one", AdminPassword: "a", PostgresPassword: "p", UserPassword: "u"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, result)
+	}
+	if result.ExitCode != 0 || result.Stderr != "" || !strings.Contains(result.Stdout, "bd-one") {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+
+	// the fake rejects a black duck without passwords the same way synopsysctl does
+	result, err = sCtl.Run(context.Background(), "create", "blackduck", "bd-one")
+	if !IsExecNonZeroExit(err) || result.ExitCode != 1 {
+		t.Fatalf("expected exit code 1, got %v\n%s", err, result)
+	}
+	if result.Stdout != "" || !strings.Contains(result.Stderr, "admin-password") {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+}
+
+func TestExecWithTimeoutKillsCommand(t *testing.T) {
+	os.Setenv("FAKE_SYNOPSYSCTL_SLEEP", "1m")
+	defer os.Unsetenv("FAKE_SYNOPSYSCTL_SLEEP")
+	start := time.Now()
+	_, err := NewSynopsysctl(fakeSynopsysctl).ExecWithTimeout(100*time.Millisecond, "--version")
+	if !IsExecTimeout(err) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("command was not killed at its deadline, took %v", time.Since(start))
+	}
+}
+
+func TestExecWithTimeoutIgnoresChildrenHoldingOutput(t *testing.T) {
+	setsid, err := exec.LookPath("setsid")
+	if err != nil {
+		t.Skip("setsid is required to start a child outside the process group")
+	}
+	// the child leaves the process group, so it survives the kill and keeps stdout open
+	dir, err := ioutil.TempDir("", "synopsysctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "synopsysctl")
+	content := fmt.Sprintf("#!/bin/sh\necho started\n%s sleep 20 &\nsleep 20\n", setsid)
+	if err := ioutil.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	output, err := NewSynopsysctl(script).ExecWithTimeout(time.Second, "--version")
+	if !IsExecTimeout(err) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if took := time.Since(start); took > time.Second+outputGrace+5*time.Second {
+		t.Errorf("command did not return after the output grace period, took %v", took)
+	}
+	if !strings.Contains(output, "started") {
+		t.Errorf("expected the output before the timeout, got %q", output)
+	}
+}
+
+func TestExecSpawnFailure(t *testing.T) {
+	_, err := NewSynopsysctl(filepath.Join(os.TempDir(), "does-not-exist")).Exec("--version")
+	if !IsExecSpawnFailure(err) {
+		t.Fatalf("expected a spawn failure, got %v", err)
+	}
+}
+
+func TestFakeRecordsInvocations(t *testing.T) {
+	log := filepath.Join(os.TempDir(), fmt.Sprintf("fake-synopsysctl-%d.log", time.Now().UnixNano()))
+	defer os.Remove(log)
+	os.Setenv("FAKE_SYNOPSYSCTL_LOG", log)
+	defer os.Unsetenv("FAKE_SYNOPSYSCTL_LOG")
+
+	sCtl := NewSynopsysctl(fakeSynopsysctl)
+	commands := []Command{
+		DeployOptions{ClusterScoped: true, EnabledResources: []Resource{AlertResource}, OperatorImage: "operator:latest"},
+		CreateAlertOptions{Name: "alt-one", Standalone: BoolPtr(false)},
+		DestroyOptions{},
+	}
+	for _, c := range commands {
+		if result, err := sCtl.RunCommandWithTimeout(time.Minute, c); err != nil {
+			t.Fatalf("unexpected error: %v\n%s", err, result)
+		}
+	}
+
+	f, err := os.Open(log)
+	if err != nil {
+		t.Fatalf("failed to open the invocation log: %v", err)
+	}
+	defer f.Close()
+	var got [][]string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line struct {
+			Args []string `json:"args"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("bad log line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, line.Args)
+	}
+	if len(got) != len(commands) {
+		t.Fatalf("expected %d invocations, got %d: %v", len(commands), len(got), got)
+	}
+	for i, c := range commands {
+		want, _ := c.Args()
+		if strings.Join(got[i], " ") != strings.Join(want, " ") {
+			t.Errorf("invocation %d: got %v, want %v", i, got[i], want)
+		}
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	transcript := filepath.Join(os.TempDir(), fmt.Sprintf("synopsysctl-transcript-%d.jsonl", time.Now().UnixNano()))
+	defer os.Remove(transcript)
+
+	recording := NewSynopsysctl(fakeSynopsysctl)
+	if err := recording.RecordTo(transcript); err != nil {
+		t.Fatalf("failed to start recording: %v", err)
+	}
+	recorded, err := recording.Run(context.Background(), "--version")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, recordedErr := recording.Run(context.Background(), "create", "blackduck", "bd-one")
+	if err := recording.StopRecording(); err != nil {
+		t.Fatalf("failed to stop recording: %v", err)
+	}
+
+	replaying := NewSynopsysctl(filepath.Join(os.TempDir(), "does-not-exist"))
+	if err := replaying.ReplayFrom(transcript); err != nil {
+		t.Fatalf("failed to load transcript: %v", err)
+	}
+	replayed, err := replaying.Run(context.Background(), "--version")
+	if err != nil || replayed.Stdout != recorded.Stdout {
+		t.Errorf("replayed %q (%v), recorded %q", replayed.Stdout, err, recorded.Stdout)
+	}
+	replayed, err = replaying.Run(context.Background(), "create", "blackduck", "bd-one")
+	if !IsExecNonZeroExit(err) || replayed.ExitCode != recordedErr.(*ExecError).ExitCode {
+		t.Errorf("replayed %v with exit code %d, recorded %v", err, replayed.ExitCode, recordedErr)
+	}
+	if _, err := replaying.Run(context.Background(), "destroy"); err == nil {
+		t.Errorf("expected an error once the transcript is exhausted")
+	}
+}
+
+func TestRecordRedactsSecrets(t *testing.T) {
+	transcript := filepath.Join(os.TempDir(), fmt.Sprintf("synopsysctl-transcript-%d.jsonl", time.Now().UnixNano()))
+	defer os.Remove(transcript)
+	options := CreateNativeOptions{
+		Create: CreateBlackDuckOptions{Name: "bd-native", AdminPassword: "admin-s3cr3t", PostgresPassword: "postgres-s3cr3t", UserPassword: "user-s3cr3t"},
+		Output: "yaml",
+	}
+
+	recording := NewSynopsysctl(fakeSynopsysctl)
+	if err := recording.RecordTo(transcript); err != nil {
+		t.Fatalf("failed to start recording: %v", err)
+	}
+	if _, result, err := recording.CreateNative(context.Background(), options); err != nil {
+		t.Fatalf("%v\n%s", err, result)
+	}
+	if err := recording.StopRecording(); err != nil {
+		t.Fatalf("failed to stop recording: %v", err)
+	}
+
+	info, err := os.Stat(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the transcript to be only readable by the current user, got %v", info.Mode())
+	}
+	b, err := ioutil.ReadFile(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "s3cr3t") {
+		t.Errorf("expected the passwords to be redacted:\n%s", b)
+	}
+	entries, err := ReadTranscript(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !strings.Contains(strings.Join(entries[0].Args, " "), "--admin-password=REDACTED") || !strings.Contains(entries[0].Stdout, "REDACTED") {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+
+	// replaying matches the redacted arguments
+	replaying := NewSynopsysctl(filepath.Join(os.TempDir(), "does-not-exist"))
+	if err := replaying.ReplayFrom(transcript); err != nil {
+		t.Fatalf("failed to load transcript: %v", err)
+	}
+	if _, _, err := replaying.CreateNative(context.Background(), options); err != nil {
+		t.Errorf("failed to replay: %v", err)
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	args, secrets := redactArgs([]string{"synopsysctl", "create", "blackduck", "bd-one", "--admin-password=a", "--user-password", "u", "--postgres-password=", "--size=small"})
+	expected := []string{"synopsysctl", "create", "blackduck", "bd-one", "--admin-password=REDACTED", "--user-password", "REDACTED", "--postgres-password=", "--size=small"}
+	if !reflect.DeepEqual(args, expected) || !reflect.DeepEqual(secrets, []string{"a", "u"}) {
+		t.Errorf("unexpected %v %v", args, secrets)
+	}
+	if masked := maskSecrets("a YQ== b", []string{"a"}); masked != "REDACTED REDACTED b" {
+		t.Errorf("unexpected %q", masked)
+	}
+}
+
+func TestStopRecordingOfCopy(t *testing.T) {
+	transcript := filepath.Join(os.TempDir(), fmt.Sprintf("synopsysctl-transcript-%d.jsonl", time.Now().UnixNano()))
+	defer os.Remove(transcript)
+
+	sCtl := NewSynopsysctl(fakeSynopsysctl)
+	if err := sCtl.RecordTo(transcript); err != nil {
+		t.Fatalf("failed to start recording: %v", err)
+	}
+	copied := sCtl.WithEnv("FAKE_SYNOPSYSCTL_VERSION=2019.8.0")
+	if err := copied.StopRecording(); err != nil {
+		t.Fatalf("failed to stop recording the copy: %v", err)
+	}
+	if _, err := copied.Exec("--version"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sCtl.Exec("--version"); err != nil {
+		t.Fatalf("expected the original to keep recording, got %v", err)
+	}
+	another := sCtl.WithDir(os.TempDir())
+	if err := sCtl.StopRecording(); err != nil {
+		t.Fatalf("failed to stop recording: %v", err)
+	}
+	if _, err := another.Exec("--version"); err != nil {
+		t.Fatalf("expected a copy not to fail once the original stopped recording, got %v", err)
+	}
+
+	entries, err := ReadTranscript(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the command of the original to be recorded, got %+v", entries)
+	}
+}
+
+func TestWithKubeContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	kubeconfig := filepath.Join(dir, "config")
+	config := `apiVersion: v1
+kind: Config
+clusters:
+- name: one
+  cluster:
+    server: https://one.example.com
+- name: two
+  cluster:
+    server: https://two.example.com
+contexts:
+- name: one
+  context:
+    cluster: one
+- name: two
+  context:
+    cluster: two
+current-context: one
+`
+	if err := ioutil.WriteFile(kubeconfig, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sCtl := NewSynopsysctl(fakeSynopsysctl).WithKubeconfig(kubeconfig)
+	if _, _, err := sCtl.WithKubeContext("three"); err == nil {
+		t.Error("expected an error for a context that does not exist")
+	}
+	two, cleanup, err := sCtl.WithKubeContext("two")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := lookupEnv(two.environ(), "KUBECONFIG")
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "current-context: two") {
+		t.Errorf("unexpected kubeconfig\n%s", b)
+	}
+	if err := cleanup(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", path, err)
+	}
+}
+
+func TestWithImpersonation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	kubeconfig := filepath.Join(dir, "config")
+	config := `apiVersion: v1
+kind: Config
+clusters:
+- name: one
+  cluster:
+    server: https://one.example.com
+users:
+- name: admin
+  user:
+    token: admin-token
+contexts:
+- name: one
+  context:
+    cluster: one
+    user: admin
+- name: anonymous
+  context:
+    cluster: one
+current-context: one
+`
+	if err := ioutil.WriteFile(kubeconfig, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sCtl := NewSynopsysctl(fakeSynopsysctl).WithKubeconfig(kubeconfig)
+	if _, _, err := sCtl.WithImpersonation("anonymous", "tester"); err == nil {
+		t.Error("expected an error for a context without a user")
+	}
+	tester, cleanup, err := sCtl.WithImpersonation("", "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	written, err := clientcmd.LoadFromFile(lookupEnv(tester.environ(), "KUBECONFIG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := written.AuthInfos["admin"]
+	if written.CurrentContext != "one" || admin == nil || admin.Impersonate != "tester" || admin.Token != "admin-token" {
+		t.Errorf("unexpected kubeconfig %+v", written)
+	}
+}
+
+func TestForCluster(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	kubeconfig := filepath.Join(dir, "config")
+	content := `apiVersion: v1
+kind: Config
+clusters:
+- name: one
+  cluster:
+    server: https://one.example.com
+users:
+- name: admin
+  user:
+    token: admin-token
+contexts:
+- name: one
+  context:
+    cluster: one
+    user: admin
+current-context: one
+`
+	if err := ioutil.WriteFile(kubeconfig, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sCtl, cleanup, err := NewSynopsysctl(fakeSynopsysctl).ForCluster(config.Cluster{Kubeconfig: kubeconfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cleanup(); err != nil || lookupEnv(sCtl.environ(), "KUBECONFIG") != kubeconfig {
+		t.Errorf("expected the kubeconfig %s without a copy, got %s, %v", kubeconfig, lookupEnv(sCtl.environ(), "KUBECONFIG"), err)
+	}
+	sCtl, cleanup, err = NewSynopsysctl(fakeSynopsysctl).ForCluster(config.Cluster{Kubeconfig: kubeconfig, Context: "one", Impersonate: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	written, err := clientcmd.LoadFromFile(lookupEnv(sCtl.environ(), "KUBECONFIG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin := written.AuthInfos["admin"]; admin == nil || admin.Impersonate != "tester" {
+		t.Errorf("unexpected kubeconfig %+v", written)
+	}
+}
+
+func TestWithEnvDirAndStdin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "synopsysctl-dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, "invocations.log")
+	base := NewSynopsysctl(fakeSynopsysctl)
+	sCtl := base.WithEnv("FAKE_SYNOPSYSCTL_VERSION=2019.8.1", "FAKE_SYNOPSYSCTL_LOG="+log).WithDir(dir)
+
+	out, err := sCtl.Exec("--version")
+	if err != nil || strings.TrimSpace(out) != "synopsysctl version 2019.8.1" {
+		t.Fatalf("unexpected output %q: %v", out, err)
+	}
+	data, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := struct{ Dir string }{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(dir); entry.Dir != dir && entry.Dir != resolved {
+		t.Errorf("expected synopsysctl to run in %s, ran in %s", dir, entry.Dir)
+	}
+
+	// the instance the options were added to is left unchanged
+	if out, _ := base.Exec("--version"); strings.TrimSpace(out) != "synopsysctl version 2019.6.0" {
+		t.Errorf("WithEnv changed the environment of the original instance: %q", out)
+	}
+
+	cat, err := exec.LookPath("cat")
+	if err != nil {
+		t.Skip("cat is not on the PATH")
+	}
+	out, err = NewSynopsysctl(cat).WithStdin("yes\n").Exec()
+	if err != nil || out != "yes\n" {
+		t.Errorf("expected stdin to be passed to the command, got %q: %v", out, err)
+	}
+}
+
+func TestCreateNative(t *testing.T) {
+	sCtl := NewSynopsysctl(fakeSynopsysctl)
+	for _, output := range []string{"yaml", "json"} {
+		m, result, err := sCtl.CreateNative(context.Background(), CreateNativeOptions{
+			Create: CreateAlertOptions{Name: "alt-one", PersistentStorage: BoolPtr(false)},
+			Output: output,
+		})
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", output, err, result)
+		}
+		if len(m.ReplicationControllers()) != 2 || len(m.Services()) != 2 || len(m.PersistentVolumeClaims()) != 0 {
+			t.Errorf("%s: unexpected objects:\n%s", output, m.Summary())
+		}
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	sCtl := NewSynopsysctl(fakeSynopsysctl).WithLogger(logging.NewWriterLogger(&buf, logging.DebugLevel))
+	if _, err := sCtl.Exec("--version"); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, " INFO "+fakeSynopsysctl+" --version: exit code 0 after ") {
+		t.Errorf("expected the command at info level, got %q", out)
+	}
+	if !strings.Contains(out, " DEBUG "+fakeSynopsysctl+" --version output:\nsynopsysctl version ") {
+		t.Errorf("expected the output at debug level, got %q", out)
+	}
+}
+
+func TestLogRedactsSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	sCtl := NewSynopsysctl(fakeSynopsysctl).WithLogger(logging.NewWriterLogger(&buf, logging.DebugLevel))
+	options := CreateNativeOptions{Create: CreateBlackDuckOptions{Name: "bd-native", AdminPassword: "admin-s3cr3t", PostgresPassword: "postgres-s3cr3t", UserPassword: "user-s3cr3t"}, Output: "yaml"}
+	if _, result, err := sCtl.CreateNative(context.Background(), options); err != nil {
+		t.Fatalf("%v\n%s", err, result)
+	}
+	if strings.Contains(buf.String(), "s3cr3t") || !strings.Contains(buf.String(), "--admin-password=REDACTED") {
+		t.Errorf("expected the password to be redacted:\n%s", buf.String())
+	}
+}
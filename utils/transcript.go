@@ -0,0 +1,282 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package utils
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TranscriptEntry is one synopsysctl invocation, stored as a single line of a JSON-lines transcript
+type TranscriptEntry struct {
+	Args     []string        `json:"args"`
+	Env      []string        `json:"env,omitempty"`
+	Dir      string          `json:"dir,omitempty"`
+	Stdout   string          `json:"stdout"`
+	Stderr   string          `json:"stderr"`
+	Combined string          `json:"combined"`
+	ExitCode int             `json:"exitCode"`
+	Reason   ExecErrorReason `json:"reason,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Start    time.Time       `json:"start"`
+	Duration time.Duration   `json:"duration"`
+}
+
+// sensitiveNames are substrings of environment variable and flag names whose values are not recorded
+var sensitiveNames = []string{"PASSWORD", "SECRET", "TOKEN", "KEY"}
+
+// redacted replaces the values that are not recorded
+const redacted = "REDACTED"
+
+func sensitive(name string) bool {
+	for _, s := range sensitiveNames {
+		if strings.Contains(strings.ToUpper(name), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// newTranscriptEntry records result without the values of secret flags and environment variables. The
+// values of secret flags are also masked in the output, as-is and base64 encoded like in secrets
+func newTranscriptEntry(start time.Time, env []string, dir string, result *ExecResult, err error) TranscriptEntry {
+	args, secrets := redactArgs(result.Args)
+	entry := TranscriptEntry{
+		Args:     args,
+		Env:      redactEnv(env),
+		Dir:      dir,
+		Stdout:   maskSecrets(result.Stdout, secrets),
+		Stderr:   maskSecrets(result.Stderr, secrets),
+		Combined: maskSecrets(result.Combined, secrets),
+		ExitCode: result.ExitCode,
+		Start:    start,
+		Duration: result.Duration,
+	}
+	if err != nil {
+		entry.Error = maskSecrets(err.Error(), secrets)
+		if execErr, ok := err.(*ExecError); ok {
+			entry.Reason = execErr.Reason
+		}
+	}
+	return entry
+}
+
+func redactEnv(env []string) []string {
+	redactedEnv := make([]string, 0, len(env))
+	for _, kv := range env {
+		name := strings.SplitN(kv, "=", 2)[0]
+		if sensitive(name) {
+			kv = name + "=" + redacted
+		}
+		redactedEnv = append(redactedEnv, kv)
+	}
+	return redactedEnv
+}
+
+// redactArgs returns args with the values of secret flags, given as --flag=value or --flag value,
+// replaced, and the values it replaced
+func redactArgs(args []string) ([]string, []string) {
+	redactedArgs := make([]string, len(args))
+	secrets := []string{}
+	for i := 0; i < len(args); i++ {
+		redactedArgs[i] = args[i]
+		if !strings.HasPrefix(args[i], "-") {
+			continue
+		}
+		kv := strings.SplitN(args[i], "=", 2)
+		if !sensitive(strings.TrimLeft(kv[0], "-")) {
+			continue
+		}
+		if len(kv) == 2 {
+			if kv[1] != "" {
+				redactedArgs[i] = kv[0] + "=" + redacted
+				secrets = append(secrets, kv[1])
+			}
+		} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			redactedArgs[i] = redacted
+			secrets = append(secrets, args[i])
+		}
+	}
+	return redactedArgs, secrets
+}
+
+func maskSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.Replace(s, secret, redacted, -1)
+		s = strings.Replace(s, base64.StdEncoding.EncodeToString([]byte(secret)), redacted, -1)
+	}
+	return s
+}
+
+// transcriptRecorder appends entries to a transcript file. It is shared by the copies of the
+// Synopsysctl that started it, but only that Synopsysctl closes it
+type transcriptRecorder struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	owner  *Synopsysctl
+	closed bool
+}
+
+// record appends entry, unless the owner stopped recording
+func (r *transcriptRecorder) record(entry TranscriptEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	_, err = r.file.Write(append(line, '\n'))
+	return err
+}
+
+func (r *transcriptRecorder) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return r.file.Close()
+}
+
+// transcriptReplayer serves recorded entries back in order
+type transcriptReplayer struct {
+	mu      sync.Mutex
+	path    string
+	entries []TranscriptEntry
+	next    int
+}
+
+func (r *transcriptReplayer) nextResult(args []string) (*ExecResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.next >= len(r.entries) {
+		return &ExecResult{ExitCode: -1}, fmt.Errorf("transcript %s has no entry for synopsysctl %s: all %d entries were replayed", r.path, strings.Join(args, " "), len(r.entries))
+	}
+	entry := r.entries[r.next]
+	// the recorded argv starts with the binary, which may differ between machines, and has its
+	// secrets redacted
+	redactedArgs, _ := redactArgs(args)
+	if len(entry.Args) == 0 || !reflect.DeepEqual(entry.Args[1:], redactedArgs) {
+		return &ExecResult{ExitCode: -1}, fmt.Errorf("transcript %s entry %d is synopsysctl %s, but synopsysctl %s was run", r.path, r.next+1, strings.Join(entry.Args[1:], " "), strings.Join(args, " "))
+	}
+	r.next++
+	result := &ExecResult{
+		Args:     entry.Args,
+		Stdout:   entry.Stdout,
+		Stderr:   entry.Stderr,
+		Combined: entry.Combined,
+		ExitCode: entry.ExitCode,
+		Duration: entry.Duration,
+	}
+	if entry.Error == "" {
+		return result, nil
+	}
+	if entry.Reason == "" {
+		return result, fmt.Errorf("%s", entry.Error)
+	}
+	return result, &ExecError{Args: args, Reason: entry.Reason, ExitCode: entry.ExitCode, Err: fmt.Errorf("replayed: %s", entry.Error)}
+}
+
+// ReadTranscript reads every entry of the transcript at path
+func ReadTranscript(path string) ([]TranscriptEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries := []TranscriptEntry{}
+	scanner := bufio.NewScanner(f)
+	// stdout of commands like "create native" can be much larger than the default token size
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		entry := TranscriptEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// RecordTo appends every invocation of sCtl to the JSON-lines transcript at path. Secrets are
+// redacted, and a new transcript is only readable by the current user
+func (sCtl *Synopsysctl) RecordTo(path string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := sCtl.StopRecording(); err != nil {
+		f.Close()
+		return err
+	}
+	sCtl.recorder = &transcriptRecorder{path: path, file: f, owner: sCtl}
+	return nil
+}
+
+// StopRecording stops recording the commands of sCtl. The transcript is closed if sCtl started it
+// with RecordTo; a copy made with one of the With methods only stops recording its own commands
+func (sCtl *Synopsysctl) StopRecording() error {
+	r := sCtl.recorder
+	if r == nil {
+		return nil
+	}
+	sCtl.recorder = nil
+	if r.owner != sCtl {
+		return nil
+	}
+	return r.close()
+}
+
+// TranscriptPath returns the path of the transcript being recorded, or "" if sCtl is not recording
+func (sCtl *Synopsysctl) TranscriptPath() string {
+	if sCtl.recorder == nil {
+		return ""
+	}
+	return sCtl.recorder.path
+}
+
+// ReplayFrom makes sCtl serve the results recorded in the transcript at path, in order, instead of
+// running the binary. Running a command that does not match the next recorded entry is an error
+func (sCtl *Synopsysctl) ReplayFrom(path string) error {
+	entries, err := ReadTranscript(path)
+	if err != nil {
+		return err
+	}
+	sCtl.replayer = &transcriptReplayer{path: path, entries: entries}
+	return nil
+}
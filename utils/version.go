@@ -0,0 +1,160 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AnyPatch is the Patch of a release line such as 2019.6.x
+const AnyPatch = -1
+
+// ReleaseVersion is a Synopsys release version such as 2019.6.0
+type ReleaseVersion struct {
+	Year  int
+	Month int
+	// Patch is AnyPatch for a release line such as 2019.6.x
+	Patch int
+}
+
+var releaseVersionRegexp = regexp.MustCompile(`(\d{4})\.(\d{1,2})(?:\.(\d+|x))?`)
+
+// ParseReleaseVersion parses versions like "2019.6.0", "2019.6", "2019.6.x" and "release-2019.6.x".
+// A missing or "x" patch is AnyPatch
+func ParseReleaseVersion(s string) (ReleaseVersion, error) {
+	m := releaseVersionRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return ReleaseVersion{}, fmt.Errorf("%q does not contain a release version", s)
+	}
+	v := ReleaseVersion{Patch: AnyPatch}
+	v.Year, _ = strconv.Atoi(m[1])
+	v.Month, _ = strconv.Atoi(m[2])
+	if m[3] != "" && m[3] != "x" {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	return v, nil
+}
+
+// MustParseReleaseVersion is ParseReleaseVersion that panics on error
+func MustParseReleaseVersion(s string) ReleaseVersion {
+	v, err := ParseReleaseVersion(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String returns the version as YEAR.MONTH.PATCH, or YEAR.MONTH.x for a release line
+func (v ReleaseVersion) String() string {
+	if v.Patch == AnyPatch {
+		return fmt.Sprintf("%d.%d.x", v.Year, v.Month)
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Year, v.Month, v.Patch)
+}
+
+// Compare returns -1, 0 or 1 if v is older than, the same as or newer than o.
+// A release line (AnyPatch) is the same as every patch release on that line
+func (v ReleaseVersion) Compare(o ReleaseVersion) int {
+	pairs := [][2]int{{v.Year, o.Year}, {v.Month, o.Month}}
+	if v.Patch != AnyPatch && o.Patch != AnyPatch {
+		pairs = append(pairs, [2]int{v.Patch, o.Patch})
+	}
+	for _, p := range pairs {
+		if p[0] < p[1] {
+			return -1
+		}
+		if p[0] > p[1] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// ReleaseLine returns the release line of v, e.g. 2019.6.x for 2019.6.1
+func (v ReleaseVersion) ReleaseLine() ReleaseVersion {
+	return ReleaseVersion{Year: v.Year, Month: v.Month, Patch: AnyPatch}
+}
+
+// AtLeast returns true if v is the same as or newer than o
+func (v ReleaseVersion) AtLeast(o ReleaseVersion) bool {
+	return v.Compare(o) >= 0
+}
+
+// InRange returns true if min <= v <= max. An empty min or max is unbounded
+func (v ReleaseVersion) InRange(min, max string) (bool, error) {
+	if min != "" {
+		minVersion, err := ParseReleaseVersion(min)
+		if err != nil {
+			return false, err
+		}
+		if v.Compare(minVersion) < 0 {
+			return false, nil
+		}
+	}
+	if max != "" {
+		maxVersion, err := ParseReleaseVersion(max)
+		if err != nil {
+			return false, err
+		}
+		if v.Compare(maxVersion) > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// OperatorImageVersion returns the release version in the tag of a Synopsys Operator image,
+// e.g. 2019.6.x for gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x
+func OperatorImageVersion(image string) (ReleaseVersion, error) {
+	idx := strings.LastIndex(image, ":")
+	if idx < 0 || strings.Contains(image[idx:], "/") {
+		return ReleaseVersion{}, fmt.Errorf("operator image %s has no tag", image)
+	}
+	return ParseReleaseVersion(image[idx+1:])
+}
+
+// Version runs "synopsysctl --version" and parses the version it reports. The result is cached
+func (sCtl *Synopsysctl) Version(ctx context.Context) (ReleaseVersion, error) {
+	sCtl.versionLock.Lock()
+	defer sCtl.versionLock.Unlock()
+	if sCtl.version != nil {
+		return *sCtl.version, nil
+	}
+	result, err := sCtl.Run(ctx, "--version")
+	if err != nil {
+		return ReleaseVersion{}, fmt.Errorf("%v\n%s", err, result)
+	}
+	out := strings.TrimSpace(result.Stdout)
+	if !strings.HasPrefix(out, "synopsysctl version ") {
+		return ReleaseVersion{}, fmt.Errorf("unexpected synopsysctl --version output %q", out)
+	}
+	v, err := ParseReleaseVersion(strings.TrimPrefix(out, "synopsysctl version "))
+	if err != nil {
+		return ReleaseVersion{}, err
+	}
+	sCtl.version = &v
+	return v, nil
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseReleaseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ReleaseVersion
+		wantErr bool
+	}{
+		{in: "2019.6.0", want: ReleaseVersion{2019, 6, 0}},
+		{in: "synopsysctl version 2019.8.1", want: ReleaseVersion{2019, 8, 1}},
+		{in: "release-2019.6.x", want: ReleaseVersion{2019, 6, AnyPatch}},
+		{in: "2019.4", want: ReleaseVersion{2019, 4, AnyPatch}},
+		{in: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseReleaseVersion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseReleaseVersion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseReleaseVersion(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReleaseVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2019.6.0", "2019.6.0", 0},
+		{"2019.6.1", "2019.6.0", 1},
+		{"2019.4.2", "2019.6.0", -1},
+		{"2019.12.0", "2019.8.0", 1},
+		{"2019.6.x", "2019.6.3", 0},
+		{"2019.6.x", "2019.8.0", -1},
+	}
+	for _, tt := range tests {
+		if got := MustParseReleaseVersion(tt.a).Compare(MustParseReleaseVersion(tt.b)); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestReleaseVersionInRange(t *testing.T) {
+	v := MustParseReleaseVersion("2019.6.0")
+	tests := []struct {
+		min, max string
+		want     bool
+	}{
+		{"", "", true},
+		{"2019.4.0", "2019.8.0", true},
+		{"2019.6.x", "2019.6.x", true},
+		{"2019.7.0", "", false},
+		{"", "2019.4.x", false},
+	}
+	for _, tt := range tests {
+		got, err := v.InRange(tt.min, tt.max)
+		if err != nil || got != tt.want {
+			t.Errorf("InRange(%q, %q) = %v, %v, want %v", tt.min, tt.max, got, err, tt.want)
+		}
+	}
+}
+
+func TestOperatorImageVersion(t *testing.T) {
+	got, err := OperatorImageVersion("gcr.io/saas-hub-stg/blackducksoftware/synopsys-operator:release-2019.6.x")
+	if err != nil || got != (ReleaseVersion{2019, 6, AnyPatch}) {
+		t.Errorf("OperatorImageVersion() = %v, %v", got, err)
+	}
+	if _, err := OperatorImageVersion("localhost:5000/synopsys-operator"); err == nil {
+		t.Errorf("expected an error for an image without a tag")
+	}
+}
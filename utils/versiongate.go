@@ -0,0 +1,66 @@
+/*
+Copyright (C) 2019 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onsi/ginkgo"
+)
+
+// SkipUnlessSynopsysctlVersion skips the current spec unless min <= synopsysctl version <= max.
+// An empty min or max is unbounded. Call it from a BeforeEach or at the start of a spec
+func SkipUnlessSynopsysctlVersion(sCtl *Synopsysctl, min, max string) {
+	version, err := sCtl.Version(context.Background())
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("failed to get the synopsysctl version: %v", err), 1)
+	}
+	skipUnlessInRange("synopsysctl", version, min, max)
+}
+
+// SkipUnlessOperatorVersion skips the current spec unless min <= release of operatorImage <= max.
+// An empty min or max is unbounded. Call it from a BeforeEach or at the start of a spec
+func SkipUnlessOperatorVersion(operatorImage, min, max string) {
+	version, err := OperatorImageVersion(operatorImage)
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("failed to get the operator version: %v", err), 1)
+	}
+	skipUnlessInRange("synopsys operator", version, min, max)
+}
+
+func skipUnlessInRange(name string, version ReleaseVersion, min, max string) {
+	ok, err := version.InRange(min, max)
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("invalid %s version range [%s, %s]: %v", name, min, max, err), 2)
+	}
+	if !ok {
+		ginkgo.Skip(fmt.Sprintf("%s version %s is not in [%s, %s]", name, version, displayBound(min), displayBound(max)), 2)
+	}
+}
+
+func displayBound(bound string) string {
+	if bound == "" {
+		return "*"
+	}
+	return bound
+}